@@ -1,32 +1,66 @@
 package analysis
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/katichai/katich/internal/config"
 	"github.com/katichai/katich/internal/context"
 )
 
 // Analyzer performs static analysis on code files
 type Analyzer struct {
-	rootPath string
+	rootPath  string
+	config    config.AnalysisConfig
+	testNames map[string][]string // test names per package directory
+	files     *context.FileCache  // shared with detection; nil reads from disk
 }
 
-// NewAnalyzer creates a new analyzer
-func NewAnalyzer(rootPath string) *Analyzer {
+// NewAnalyzer creates a new analyzer using the given analysis settings
+func NewAnalyzer(rootPath string, cfg config.AnalysisConfig) *Analyzer {
 	return &Analyzer{
-		rootPath: rootPath,
+		rootPath:  rootPath,
+		config:    cfg,
+		testNames: make(map[string][]string),
 	}
 }
 
+// SetFileCache makes the analyzer read files through a cache shared with
+// other steps of a build, such as detection and file hashing
+func (a *Analyzer) SetFileCache(files *context.FileCache) {
+	a.files = files
+}
+
 // AnalysisResult contains analysis results for a repository
 type AnalysisResult struct {
-	Files          map[string]*FileAnalysis `json:"files"`
-	TotalMetrics   CodeMetrics              `json:"total_metrics"`
-	IssuesSummary  IssuesSummary            `json:"issues_summary"`
-	TopComplexity  []FunctionInfo           `json:"top_complexity"`
-	LongestFuncs   []FunctionInfo           `json:"longest_functions"`
+	Files             map[string]*FileAnalysis `json:"files"`
+	TotalMetrics      CodeMetrics              `json:"total_metrics"`
+	ProductionMetrics CodeMetrics              `json:"production_metrics"` // TotalMetrics without test files
+	TestMetrics       CodeMetrics              `json:"test_metrics"`
+	IssuesSummary     IssuesSummary            `json:"issues_summary"`
+	TopComplexity     []FunctionInfo           `json:"top_complexity"`
+	LongestFuncs      []FunctionInfo           `json:"longest_functions"`
+	DuplicateTypes    []DuplicateType          `json:"duplicate_types,omitempty"`
+	DuplicateData     []DuplicateLiteral       `json:"duplicate_data,omitempty"`
+	DuplicateCode     []DuplicateBlock         `json:"duplicate_code,omitempty"`
+	WorstFiles        []FileIssues             `json:"worst_files,omitempty"`
+}
+
+// FileIssues ranks a file by the severity-weighted number of its issues
+type FileIssues struct {
+	File   string `json:"file"`
+	Issues int    `json:"issues"`
+	Score  int    `json:"score"` // errors weigh 10, warnings 3, infos 1
+}
+
+// severityWeight is how much one issue of each severity adds to a file's score
+var severityWeight = map[Severity]int{
+	SeverityError:   10,
+	SeverityWarning: 3,
+	SeverityInfo:    1,
 }
 
 // IssuesSummary summarizes issues by type and severity
@@ -38,106 +72,336 @@ type IssuesSummary struct {
 
 // AnalyzeRepository analyzes all source files in the repository
 func (a *Analyzer) AnalyzeRepository() (*AnalysisResult, error) {
-	result := &AnalysisResult{
-		Files:         make(map[string]*FileAnalysis),
-		TopComplexity: make([]FunctionInfo, 0),
-		LongestFuncs:  make([]FunctionInfo, 0),
-		IssuesSummary: IssuesSummary{
-			ByType:     make(map[IssueType]int),
-			BySeverity: make(map[Severity]int),
-		},
+	paths, err := a.SourceFiles()
+	if err != nil {
+		return nil, err
 	}
 
+	files := make(map[string]*FileAnalysis, len(paths))
+	for _, relPath := range paths {
+		analysis, err := a.analyzeFile(filepath.Join(a.rootPath, relPath))
+		if err != nil {
+			// Log error but continue
+			continue
+		}
+
+		files[relPath] = analysis
+	}
+
+	return a.summarize(files), nil
+}
+
+// SourceFiles lists the source files AnalyzeRepository analyzes, relative
+// to the repository root
+func (a *Analyzer) SourceFiles() ([]string, error) {
+	paths := make([]string, 0)
+	ignore := context.LoadIgnore(a.rootPath)
+
 	// Walk through repository
 	err := filepath.Walk(a.rootPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
+		// Skip paths excluded by .gitignore or .katichignore
+		relPath, _ := filepath.Rel(a.rootPath, path)
+		if relPath != "." && ignore.Match(relPath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		// Skip directories and non-source files
 		if info.IsDir() {
-			name := info.Name()
-			if strings.HasPrefix(name, ".") ||
-				name == "node_modules" ||
-				name == "vendor" ||
-				name == "dist" ||
-				name == "build" ||
-				name == "target" {
+			if relPath != "." && skippedDir(info.Name()) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		// Analyze source files
 		if a.isSourceFile(path) {
-			analysis, err := a.analyzeFile(path)
-			if err != nil {
-				// Log error but continue
-				return nil
-			}
+			paths = append(paths, relPath)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
 
-			relPath, _ := filepath.Rel(a.rootPath, path)
-			result.Files[relPath] = analysis
+	return paths, nil
+}
 
-			// Aggregate metrics
-			a.aggregateMetrics(&result.TotalMetrics, analysis.Metrics)
+// skippedDir reports whether the walk skips a directory: hidden
+// directories, dependencies and build output
+func skippedDir(name string) bool {
+	return strings.HasPrefix(name, ".") ||
+		name == "node_modules" ||
+		name == "vendor" ||
+		name == "dist" ||
+		name == "build" ||
+		name == "target"
+}
+
+// walked reports whether SourceFiles would reach a repository-relative
+// path, i.e. neither it nor any parent directory is ignored or skipped
+func walked(relPath string, ignore *context.IgnoreMatcher) bool {
+	relPath = filepath.Clean(relPath)
+	if ignore.Match(relPath, false) {
+		return false
+	}
+	for dir := filepath.Dir(relPath); dir != "."; dir = filepath.Dir(dir) {
+		if skippedDir(filepath.Base(dir)) || ignore.Match(dir, true) {
+			return false
+		}
+	}
+	return true
+}
 
-			// Collect issues
-			for _, issue := range analysis.Issues {
-				result.IssuesSummary.TotalIssues++
-				result.IssuesSummary.ByType[issue.Type]++
-				result.IssuesSummary.BySeverity[issue.Severity]++
+// MergeFiles updates a previous repository analysis with freshly analyzed
+// files, dropping removed paths, then recomputes totals and cross-file
+// duplication over the merged set
+func (a *Analyzer) MergeFiles(previous *AnalysisResult, changed map[string]*FileAnalysis, removed []string) *AnalysisResult {
+	files := make(map[string]*FileAnalysis, len(previous.Files)+len(changed))
+	for path, fileAnalysis := range previous.Files {
+		// Duplication issues are recomputed from the merged files below
+		kept := make([]Issue, 0, len(fileAnalysis.Issues))
+		for _, issue := range fileAnalysis.Issues {
+			if issue.Type != IssueTypeDuplication {
+				kept = append(kept, issue)
 			}
+		}
+		fileAnalysis.Issues = kept
+		files[path] = fileAnalysis
+	}
 
-			// Collect top complexity functions
-			for _, fn := range analysis.Functions {
-				result.TopComplexity = append(result.TopComplexity, fn)
-				result.LongestFuncs = append(result.LongestFuncs, fn)
+	for _, path := range removed {
+		delete(files, path)
+	}
+	for path, fileAnalysis := range changed {
+		files[path] = fileAnalysis
+	}
+
+	return a.summarize(files)
+}
+
+// summarize aggregates metrics, issues, top functions and cross-file
+// duplication for a set of analyzed files
+func (a *Analyzer) summarize(files map[string]*FileAnalysis) *AnalysisResult {
+	result := &AnalysisResult{
+		Files:         files,
+		TopComplexity: make([]FunctionInfo, 0),
+		LongestFuncs:  make([]FunctionInfo, 0),
+		IssuesSummary: IssuesSummary{
+			ByType:     make(map[IssueType]int),
+			BySeverity: make(map[Severity]int),
+		},
+	}
+
+	functionLOC, functions := 0, 0
+	testLOC, testFunctions := 0, 0
+	for path, analysis := range files {
+		// Aggregate metrics, keeping tests apart from production code
+		a.aggregateMetrics(&result.TotalMetrics, analysis.Metrics)
+		isTest := context.IsTestFile(path)
+		if isTest {
+			a.aggregateMetrics(&result.TestMetrics, analysis.Metrics)
+		} else {
+			a.aggregateMetrics(&result.ProductionMetrics, analysis.Metrics)
+		}
+
+		// Collect issues
+		for _, issue := range analysis.Issues {
+			result.IssuesSummary.TotalIssues++
+			result.IssuesSummary.ByType[issue.Type]++
+			result.IssuesSummary.BySeverity[issue.Severity]++
+		}
+
+		// Collect top complexity functions
+		for _, fn := range analysis.Functions {
+			result.TopComplexity = append(result.TopComplexity, fn)
+			result.LongestFuncs = append(result.LongestFuncs, fn)
+			functionLOC += fn.LOC
+			functions++
+			if isTest {
+				testLOC += fn.LOC
+				testFunctions++
 			}
 		}
+	}
 
-		return nil
+	// Averages cannot be summed per file, so take them over every function
+	result.TotalMetrics.AvgFunctionLength = averageLength(functionLOC, functions)
+	result.TestMetrics.AvgFunctionLength = averageLength(testLOC, testFunctions)
+	result.ProductionMetrics.AvgFunctionLength = averageLength(functionLOC-testLOC, functions-testFunctions)
+	result.TotalMetrics.MaintainabilityIndex = averageMaintainability(files, func(path string) bool { return true })
+	result.TestMetrics.MaintainabilityIndex = averageMaintainability(files, context.IsTestFile)
+	result.ProductionMetrics.MaintainabilityIndex = averageMaintainability(files, func(path string) bool {
+		return !context.IsTestFile(path)
 	})
 
-	if err != nil {
-		return nil, err
+	// Find struct definitions, data and code copied between files
+	if checkSelected(a.config, IssueTypeDuplication) {
+		detector := NewDuplicationDetector(a.config.MinDuplicateLines)
+		detector.SetFileCache(a.files)
+		result.DuplicateTypes = detector.DetectDuplicateTypes(result.Files)
+		result.DuplicateData = detector.DetectDuplicateLiterals(result.Files)
+		addDuplicateDataIssues(result)
+		if a.config.MinDuplicateLines > 0 {
+			result.DuplicateCode = detector.DetectDuplicates(result.Files)
+			addDuplicateCodeIssues(result.Files, result.DuplicateCode)
+			result.IssuesSummary.TotalIssues += 2 * len(result.DuplicateCode)
+			result.IssuesSummary.ByType[IssueTypeDuplication] += 2 * len(result.DuplicateCode)
+			result.IssuesSummary.BySeverity[SeverityWarning] += 2 * len(result.DuplicateCode)
+		}
 	}
 
 	// Sort and limit top lists
 	result.TopComplexity = a.getTopByComplexity(result.TopComplexity, 10)
 	result.LongestFuncs = a.getTopByLength(result.LongestFuncs, 10)
+	result.WorstFiles = a.getWorstFiles(result.Files, 10)
 
-	return result, nil
+	return result
 }
 
-// analyzeFile analyzes a single file
+// addDuplicateDataIssues reports every copy of a duplicated data literal in
+// its file, pointing at the other copies
+func addDuplicateDataIssues(result *AnalysisResult) {
+	for _, dup := range result.DuplicateData {
+		for i, loc := range dup.Locations {
+			others := make([]string, 0, len(dup.Locations)-1)
+			for j, other := range dup.Locations {
+				if j != i {
+					others = append(others, fmt.Sprintf("%s:%d", other.File, other.Line))
+				}
+			}
+
+			fileAnalysis := result.Files[loc.File]
+			fileAnalysis.Issues = append(fileAnalysis.Issues, Issue{
+				Type:       IssueTypeDuplication,
+				Severity:   SeverityInfo,
+				Line:       loc.Line,
+				Message:    fmt.Sprintf("%s literal with %d elements is duplicated at %s", dup.Type, dup.Elements, strings.Join(others, ", ")),
+				Suggestion: "Consolidate the data into one shared variable",
+			})
+			result.IssuesSummary.TotalIssues++
+			result.IssuesSummary.ByType[IssueTypeDuplication]++
+			result.IssuesSummary.BySeverity[SeverityInfo]++
+		}
+	}
+}
+
+// addDuplicateCodeIssues reports both copies of a duplicated code block,
+// each pointing at the other
+func addDuplicateCodeIssues(files map[string]*FileAnalysis, duplicates []DuplicateBlock) {
+	for _, dup := range duplicates {
+		copies := []struct {
+			file      string
+			line      int
+			other     string
+			otherLine int
+		}{
+			{dup.File1, dup.StartLine1, dup.File2, dup.StartLine2},
+			{dup.File2, dup.StartLine2, dup.File1, dup.StartLine1},
+		}
+		for _, c := range copies {
+			fileAnalysis := files[c.file]
+			fileAnalysis.Issues = append(fileAnalysis.Issues, Issue{
+				Type:       IssueTypeDuplication,
+				Severity:   SeverityWarning,
+				Line:       c.line,
+				Message:    fmt.Sprintf("%d lines duplicated at %s:%d (%.0f%% identical)", dup.Lines, c.other, c.otherLine, dup.Similarity*100),
+				Suggestion: "Extract the shared logic into a function",
+			})
+		}
+	}
+}
+
+// analyzeFile analyzes a single file, keeping only the selected checks
 func (a *Analyzer) analyzeFile(filePath string) (*FileAnalysis, error) {
+	fileAnalysis, err := a.parseFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	applyCheckSelection(a.config, fileAnalysis)
+	return fileAnalysis, nil
+}
+
+// parseFile reads a file and parses it with the parser for its language
+func (a *Analyzer) parseFile(filePath string) (*FileAnalysis, error) {
+	content, err := a.files.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return a.parseContent(filePath, content)
+}
+
+// parseContent parses a file's content and adds the checks that look at
+// the file's package on disk
+func (a *Analyzer) parseContent(filePath string, content []byte) (*FileAnalysis, error) {
+	fileAnalysis, err := a.parseSource(filePath, content)
+	if err == nil && fileAnalysis.Language == string(context.LanguageGo) && checkEnabled(a.config, IssueTypeMissingTest) {
+		fileAnalysis.Issues = append(fileAnalysis.Issues, a.checkMissingTests(filePath, fileAnalysis)...)
+	}
+	return fileAnalysis, err
+}
+
+// AnalyzeSource analyzes in-memory content for a file path, such as the
+// file at a specific git revision
+func (a *Analyzer) AnalyzeSource(filePath string, content []byte) (*FileAnalysis, error) {
+	fileAnalysis, err := a.parseSource(filePath, content)
+	if err != nil {
+		return nil, err
+	}
+	applyCheckSelection(a.config, fileAnalysis)
+	return fileAnalysis, nil
+}
+
+// parseSource parses in-memory content with the parser for its language
+func (a *Analyzer) parseSource(filePath string, content []byte) (*FileAnalysis, error) {
+	if IsComponentFile(filePath) {
+		return NewSFCParser(a.config).ParseSource(filePath, content)
+	}
+
+	// The file may not exist on disk, so read a shebang from the content
 	lang := context.DetectLanguage(filePath)
+	if lang == context.LanguageUnknown && filepath.Ext(filePath) == "" {
+		line, _, _ := strings.Cut(string(content), "\n")
+		lang = context.ShebangLanguage(line)
+	}
 
 	switch lang {
 	case context.LanguageGo:
-		parser := NewGoParser()
-		return parser.ParseFile(filePath)
-	
+		parser := NewGoParser(a.config)
+		return parser.ParseSource(filePath, content)
+
+	case context.LanguagePython:
+		parser := NewPythonParser(a.config)
+		return parser.ParseSource(filePath, content)
+
 	// Add more language parsers here
 	// case context.LanguageJavaScript, context.LanguageTypeScript:
 	//     parser := NewJSParser()
-	//     return parser.ParseFile(filePath)
-	
+	//     return parser.ParseSource(filePath, content)
+
 	default:
-		// For unsupported languages, do basic analysis
-		return a.basicAnalysis(filePath, string(lang))
+		return a.basicSourceAnalysis(filePath, string(lang), content), nil
 	}
 }
 
-// basicAnalysis performs basic analysis for unsupported languages
-func (a *Analyzer) basicAnalysis(filePath string, language string) (*FileAnalysis, error) {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, err
-	}
+// basicSourceAnalysis computes line metrics for already-read content
+func (a *Analyzer) basicSourceAnalysis(filePath string, language string, content []byte) *FileAnalysis {
+	metrics := CalculateBasicMetrics(language, string(content), a.config.DebtMarkers)
 
-	metrics := CalculateBasicMetrics(string(content))
+	issues := checkEmptyCatchBlocks(language, string(content), 0)
+	issues = append(issues, checkIndentation(language, string(content), 0)...)
+	issues = append(issues, checkDebtMarkers(a.config, language, string(content), 0)...)
+	if !isTestOrConfigPath(filePath) {
+		issues = append(issues, checkHardcodedHostLines(string(content), 0, a.config.HostAllowlist)...)
+		issues = append(issues, checkHardcodedSecretLines(string(content), 0)...)
+	}
 
 	return &FileAnalysis{
 		FilePath:  filePath,
@@ -146,8 +410,8 @@ func (a *Analyzer) basicAnalysis(filePath string, language string) (*FileAnalysi
 		Functions: make([]FunctionInfo, 0),
 		Classes:   make([]ClassInfo, 0),
 		Imports:   make([]ImportInfo, 0),
-		Issues:    make([]Issue, 0),
-	}, nil
+		Issues:    issues,
+	}
 }
 
 // isSourceFile checks if a file is a source code file
@@ -164,12 +428,42 @@ func (a *Analyzer) aggregateMetrics(total *CodeMetrics, file CodeMetrics) {
 	total.FunctionCount += file.FunctionCount
 	total.ClassCount += file.ClassCount
 	total.ImportCount += file.ImportCount
+	total.DebtMarkers += file.DebtMarkers
+	total.HalsteadVolume += file.HalsteadVolume
 
 	if file.MaxFunctionLength > total.MaxFunctionLength {
 		total.MaxFunctionLength = file.MaxFunctionLength
 	}
 }
 
+// averageLength returns the mean length of count functions spanning loc
+// lines, or 0 when there are none
+func averageLength(loc, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(loc) / float64(count)
+}
+
+// averageMaintainability weighs the maintainability index of the files
+// include accepts by their lines of code, so small files do not mask a
+// large one. Files without an index are left out.
+func averageMaintainability(files map[string]*FileAnalysis, include func(path string) bool) float64 {
+	weighted, lines := 0.0, 0
+	for path, fileAnalysis := range files {
+		metrics := fileAnalysis.Metrics
+		if metrics.HalsteadVolume <= 0 || !include(path) {
+			continue
+		}
+		weighted += metrics.MaintainabilityIndex * float64(metrics.LinesOfCode)
+		lines += metrics.LinesOfCode
+	}
+	if lines == 0 {
+		return 0
+	}
+	return weighted / float64(lines)
+}
+
 // getTopByComplexity returns top N functions by complexity
 func (a *Analyzer) getTopByComplexity(functions []FunctionInfo, n int) []FunctionInfo {
 	// Simple bubble sort for top N
@@ -204,24 +498,85 @@ func (a *Analyzer) getTopByLength(functions []FunctionInfo, n int) []FunctionInf
 	return functions
 }
 
-// AnalyzeChangedFiles analyzes only the files that changed in a diff
+// getWorstFiles returns the n files with the highest severity-weighted issue
+// score, breaking ties by issue count and then path
+func (a *Analyzer) getWorstFiles(files map[string]*FileAnalysis, n int) []FileIssues {
+	worst := make([]FileIssues, 0)
+	for path, fileAnalysis := range files {
+		if len(fileAnalysis.Issues) == 0 {
+			continue
+		}
+		entry := FileIssues{File: path, Issues: len(fileAnalysis.Issues)}
+		for _, issue := range fileAnalysis.Issues {
+			entry.Score += severityWeight[issue.Severity]
+		}
+		worst = append(worst, entry)
+	}
+
+	sort.Slice(worst, func(i, j int) bool {
+		if worst[i].Score != worst[j].Score {
+			return worst[i].Score > worst[j].Score
+		}
+		if worst[i].Issues != worst[j].Issues {
+			return worst[i].Issues > worst[j].Issues
+		}
+		return worst[i].File < worst[j].File
+	})
+
+	if len(worst) > n {
+		return worst[:n]
+	}
+	return worst
+}
+
+// AnalyzeChangedFiles analyzes only the files that changed in a diff, as
+// they are in the working tree
 func (a *Analyzer) AnalyzeChangedFiles(changedFiles []string) (map[string]*FileAnalysis, error) {
+	return a.AnalyzeChangedSources(changedFiles, func(file string) ([]byte, error) {
+		return a.files.ReadFile(filepath.Join(a.rootPath, file))
+	}), nil
+}
+
+// AnalyzeChangedSources analyzes the files that changed in a diff, reading
+// each (repository-relative) path through read, for example from a git
+// revision. Files that cannot be read or parsed, or that a repository walk
+// would not reach (ignored or in a skipped directory), are left out.
+func (a *Analyzer) AnalyzeChangedSources(changedFiles []string, read func(file string) ([]byte, error)) map[string]*FileAnalysis {
 	results := make(map[string]*FileAnalysis)
+	ignore := context.LoadIgnore(a.rootPath)
 
 	for _, file := range changedFiles {
 		fullPath := filepath.Join(a.rootPath, file)
-		
-		if !a.isSourceFile(fullPath) {
+
+		// Apply the same filters as a full repository walk
+		if !a.isSourceFile(fullPath) || !walked(file, ignore) {
 			continue
 		}
 
-		analysis, err := a.analyzeFile(fullPath)
+		content, err := read(file)
+		if err != nil {
+			continue
+		}
+		analysis, err := a.parseContent(fullPath, content)
 		if err != nil {
 			continue
 		}
+		applyCheckSelection(a.config, analysis)
 
 		results[file] = analysis
 	}
 
-	return results, nil
+	return results
+}
+
+// DetectChangedDuplicates finds code blocks duplicated between the given
+// files, typically those returned by AnalyzeChangedFiles, and reports both
+// copies as issues in their files
+func (a *Analyzer) DetectChangedDuplicates(files map[string]*FileAnalysis) []DuplicateBlock {
+	if !checkSelected(a.config, IssueTypeDuplication) || a.config.MinDuplicateLines <= 0 {
+		return make([]DuplicateBlock, 0)
+	}
+	duplicates := NewDuplicationDetector(a.config.MinDuplicateLines).DetectDuplicates(files)
+	addDuplicateCodeIssues(files, duplicates)
+	return duplicates
 }
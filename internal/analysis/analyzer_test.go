@@ -0,0 +1,83 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+// writeFiles creates files under root from a map of relative path to content
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyzeChangedFilesMatchesWalk(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":            "gen/\n*.pb.go\n",
+		"main.go":               "package main\n",
+		"pkg/api.go":            "package pkg\n",
+		"pkg/api.pb.go":         "package pkg\n",
+		"gen/models.go":         "package gen\n",
+		"vendor/lib/lib.go":     "package lib\n",
+		"web/node_modules/x.js": "module.exports = 1\n",
+		".github/tool.go":       "package tool\n",
+	})
+	changed := []string{"main.go", "pkg/api.go", "pkg/api.pb.go", "gen/models.go", "vendor/lib/lib.go", "web/node_modules/x.js", ".github/tool.go"}
+
+	analyzer := NewAnalyzer(root, config.DefaultConfig().Analysis)
+	results, err := analyzer.AnalyzeChangedFiles(changed)
+	if err != nil {
+		t.Fatalf("AnalyzeChangedFiles: %v", err)
+	}
+	got := make([]string, 0, len(results))
+	for path := range results {
+		got = append(got, path)
+	}
+	slices.Sort(got)
+
+	walked, err := analyzer.SourceFiles()
+	if err != nil {
+		t.Fatalf("SourceFiles: %v", err)
+	}
+	slices.Sort(walked)
+
+	want := []string{"main.go", "pkg/api.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("AnalyzeChangedFiles analyzed %v, want %v", got, want)
+	}
+	if !slices.Equal(walked, want) {
+		t.Errorf("SourceFiles = %v, want %v", walked, want)
+	}
+}
+
+func TestAnalyzeRepositoryAverageFunctionLength(t *testing.T) {
+	root := t.TempDir()
+	// One 10-line function and three 2-line functions: the average over
+	// functions is 16/4 = 4, while averaging the per-file averages gives 6
+	writeFiles(t, root, map[string]string{
+		"long.go":  "package p\n\nfunc long() {\n" + strings.Repeat("\tprintln()\n", 8) + "}\n",
+		"short.go": "package p\n\nfunc a() {\n}\n\nfunc b() {\n}\n\nfunc c() {\n}\n",
+	})
+
+	result, err := NewAnalyzer(root, config.DefaultConfig().Analysis).AnalyzeRepository()
+	if err != nil {
+		t.Fatalf("AnalyzeRepository: %v", err)
+	}
+	if got := result.TotalMetrics.AvgFunctionLength; got != 4 {
+		t.Errorf("AvgFunctionLength = %g, want 4", got)
+	}
+}
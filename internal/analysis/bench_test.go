@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+// benchFixture is the directory of representative source files the
+// benchmarks analyze
+const benchFixture = "testdata/bench"
+
+// benchPackages is how many copies of the fixture the repository benchmark
+// spreads over separate directories
+const benchPackages = 50
+
+// newBenchRepo copies the fixture files into benchPackages directories of
+// a temporary repository and returns its root
+func newBenchRepo(b *testing.B) string {
+	b.Helper()
+	entries, err := os.ReadDir(benchFixture)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	root := b.TempDir()
+	for i := 0; i < benchPackages; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("pkg%02d", i))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			b.Fatal(err)
+		}
+		for _, entry := range entries {
+			content, err := os.ReadFile(filepath.Join(benchFixture, entry.Name()))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, entry.Name()), content, 0644); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	return root
+}
+
+func BenchmarkAnalyzeRepository(b *testing.B) {
+	root := newBenchRepo(b)
+	cfg := config.DefaultConfig().Analysis
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewAnalyzer(root, cfg).AnalyzeRepository(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGoParser(b *testing.B) {
+	content, err := os.ReadFile(filepath.Join(benchFixture, "service.go"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	parser := NewGoParser(config.DefaultConfig().Analysis)
+
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.ParseSource("service.go", content); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
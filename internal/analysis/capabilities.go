@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/katichai/katich/internal/context"
+)
+
+// Capabilities describes what the analyzer extracts for a language
+type Capabilities struct {
+	Functions  bool `json:"functions"`
+	Classes    bool `json:"classes"`
+	Complexity bool `json:"complexity"`
+	Imports    bool `json:"imports"`
+	Issues     bool `json:"issues"` // checks beyond the text checks every language gets
+}
+
+// Full reports whether every capability is supported
+func (c Capabilities) Full() bool {
+	return c.Functions && c.Classes && c.Complexity && c.Imports && c.Issues
+}
+
+// Describe summarizes the capabilities, e.g. "basic analysis only"
+func (c Capabilities) Describe() string {
+	if c.Full() {
+		return "full analysis"
+	}
+	return "basic analysis only (line metrics and text checks)"
+}
+
+// LanguageCapabilities lists the languages with a dedicated parser. Keep it
+// in sync with the parsers dispatched by Analyzer.parseFile.
+var LanguageCapabilities = map[context.Language]Capabilities{
+	context.LanguageGo:     {Functions: true, Classes: true, Complexity: true, Imports: true, Issues: true},
+	context.LanguagePython: {Functions: true, Classes: true, Complexity: true, Imports: true, Issues: true},
+}
+
+// CapabilitiesFor returns the capabilities for a language; languages
+// without a parser get basic analysis
+func CapabilitiesFor(lang context.Language) Capabilities {
+	return LanguageCapabilities[lang]
+}
+
+// BasicLanguages returns the detected languages that only get basic
+// analysis, sorted by name
+func BasicLanguages(languages map[context.Language]int) []context.Language {
+	basic := make([]context.Language, 0)
+	for lang := range languages {
+		if !CapabilitiesFor(lang).Full() {
+			basic = append(basic, lang)
+		}
+	}
+	sort.Slice(basic, func(i, j int) bool { return basic[i] < basic[j] })
+	return basic
+}
+
+// SupportSummary describes language support for diagnostics, e.g.
+// "full: Go; basic: every other language"
+func SupportSummary() string {
+	full := make([]string, 0, len(LanguageCapabilities))
+	for lang, caps := range LanguageCapabilities {
+		if caps.Full() {
+			full = append(full, string(lang))
+		}
+	}
+	sort.Strings(full)
+	return "full: " + strings.Join(full, ", ") + "; basic: every other language"
+}
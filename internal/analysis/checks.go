@@ -0,0 +1,156 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+// CheckInfo describes a check that can be selected with --only or --skip
+type CheckInfo struct {
+	Type        IssueType
+	Description string
+	OptIn       bool // runs only when listed in enabled_checks or --only
+}
+
+// Checks is the catalog of every check the analyzer can report
+var Checks = []CheckInfo{
+	{IssueTypeComplexity, "Functions above the cyclomatic complexity threshold", false},
+	{IssueTypeFunctionLength, "Functions above the length threshold", false},
+	{IssueTypeDuplication, "Struct definitions, data literals and code blocks copied between files or functions", false},
+	{IssueTypeStyleViolation, "Inconsistent indentation", false},
+	{IssueTypeSimplification, "Redundant conditionals and self-comparisons", false},
+	{IssueTypeReturnCount, "Functions with more than max_returns return statements", false},
+	{IssueTypeEmptyErrorHandling, "Ignored errors and empty catch blocks", false},
+	{IssueTypeParameterStruct, "Long parameter lists that could be a struct", false},
+	{IssueTypeHardcodedHost, "Hardcoded URLs, IP addresses and host:port pairs outside tests and config", false},
+	{IssueTypeHardcodedSecret, "API keys, tokens, private keys and passwords in string literals outside tests and config", false},
+	{IssueTypeTechDebt, "TODO, FIXME, HACK and XXX comments, or the debt_markers configured", false},
+	{IssueTypeErrorWrapping, "Exported Go functions returning errors without context", true},
+	{IssueTypeMissingTest, "Exported Go functions with no TestXxx in the package", true},
+	{IssueTypePurity, "Go functions without side effects, and query-named functions that mutate state", true},
+	{IssueTypeNaming, "Names that may not follow conventions, e.g. Url instead of URL in Go", true},
+	{IssueTypeReturnShape, "Functions whose branches return different kinds of values, and Go any results that could be typed", true},
+	{IssueTypeIgnoredError, "Go assignments that discard an error with _", true},
+	{IssueTypePanic, "Direct panic calls in Go code outside tests, init and Must* functions", true},
+	{IssueTypeAIReview, "Problems reported by the LLM in review file", false},
+}
+
+// ValidateCheckNames returns an error naming the first check that is not in
+// the catalog
+func ValidateCheckNames(names []string) error {
+	for _, name := range names {
+		if !isKnownCheck(IssueType(name)) {
+			known := make([]string, 0, len(Checks))
+			for _, check := range Checks {
+				known = append(known, string(check.Type))
+			}
+			return fmt.Errorf("unknown check %q (available: %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// isKnownCheck reports whether a check is in the catalog
+func isKnownCheck(check IssueType) bool {
+	for _, info := range Checks {
+		if info.Type == check {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSelected reports whether a check passes the only_checks and
+// skip_checks filters. Opt-in checks must also pass checkEnabled.
+func checkSelected(cfg config.AnalysisConfig, check IssueType) bool {
+	if containsCheck(cfg.SkipChecks, check) {
+		return false
+	}
+	return len(cfg.OnlyChecks) == 0 || containsCheck(cfg.OnlyChecks, check)
+}
+
+// CheckSelected reports whether a check run outside the analyzer, such as
+// the LLM review, passes the only_checks and skip_checks filters
+func CheckSelected(cfg config.AnalysisConfig, check IssueType) bool {
+	return checkSelected(cfg, check)
+}
+
+// containsCheck reports whether a check is named in a list
+func containsCheck(names []string, check IssueType) bool {
+	for _, name := range names {
+		if IssueType(name) == check {
+			return true
+		}
+	}
+	return false
+}
+
+// applyCheckSelection runs the opt-in checks that work on any parsed file
+// and drops issues whose check is not selected
+func applyCheckSelection(cfg config.AnalysisConfig, fileAnalysis *FileAnalysis) {
+	if checkEnabled(cfg, IssueTypeNaming) {
+		fileAnalysis.Issues = append(fileAnalysis.Issues, NewStyleChecker().CheckStyle(fileAnalysis)...)
+	}
+	fileAnalysis.Issues = filterIssues(cfg, fileAnalysis.Issues)
+}
+
+// filterIssues drops issues whose check is not selected
+func filterIssues(cfg config.AnalysisConfig, issues []Issue) []Issue {
+	if len(cfg.OnlyChecks) == 0 && len(cfg.SkipChecks) == 0 {
+		return issues
+	}
+
+	kept := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		if checkSelected(cfg, issue.Type) {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
+
+// checkFunctionThresholds flags functions above the configured complexity,
+// length and return count limits. Unset limits fall back to the defaults;
+// max_returns stays disabled at 0.
+func checkFunctionThresholds(cfg config.AnalysisConfig, fn FunctionInfo) []Issue {
+	defaults := config.DefaultConfig().Analysis
+	complexityThreshold, maxLength := cfg.ComplexityThreshold, cfg.MaxFunctionLength
+	if complexityThreshold <= 0 {
+		complexityThreshold = defaults.ComplexityThreshold
+	}
+	if maxLength <= 0 {
+		maxLength = defaults.MaxFunctionLength
+	}
+
+	issues := make([]Issue, 0)
+	if fn.Complexity > complexityThreshold {
+		issues = append(issues, Issue{
+			Type:       IssueTypeComplexity,
+			Severity:   SeverityWarning,
+			Line:       fn.StartLine,
+			Message:    fmt.Sprintf("Function '%s' has high complexity: %d", fn.Name, fn.Complexity),
+			Suggestion: "Consider breaking down this function into smaller functions",
+		})
+	}
+	if fn.LOC > maxLength {
+		issues = append(issues, Issue{
+			Type:       IssueTypeFunctionLength,
+			Severity:   SeverityWarning,
+			Line:       fn.StartLine,
+			Message:    fmt.Sprintf("Function '%s' is too long: %d lines", fn.Name, fn.LOC),
+			Suggestion: "Consider refactoring into smaller functions",
+		})
+	}
+	if cfg.MaxReturns > 0 && fn.Returns > cfg.MaxReturns {
+		issues = append(issues, Issue{
+			Type:       IssueTypeReturnCount,
+			Severity:   SeverityInfo,
+			Line:       fn.StartLine,
+			Message:    fmt.Sprintf("Function '%s' has too many return statements: %d", fn.Name, fn.Returns),
+			Suggestion: "Consider consolidating exit paths or splitting the function",
+		})
+	}
+	return issues
+}
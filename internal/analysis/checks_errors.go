@@ -0,0 +1,138 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// commaOKFuncs return a bool or count last rather than an error, so
+// discarding their last result is not an ignored error
+var commaOKFuncs = map[string]bool{
+	"LookupEnv": true, "Lookup": true, "Load": true, "LoadOrStore": true,
+	"LoadAndDelete": true, "Cut": true, "CutPrefix": true, "CutSuffix": true,
+	"DecodeRune": true, "DecodeRuneInString": true, "DecodeLastRune": true,
+	"DecodeLastRuneInString": true, "Caller": true,
+}
+
+// declaredFuncTypes returns the signatures of the functions and methods
+// declared in a file by name, so calls to them can be checked exactly
+func declaredFuncTypes(file *ast.File) map[string]*ast.FuncType {
+	declared := make(map[string]*ast.FuncType)
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+			declared[funcDecl.Name.Name] = funcDecl.Type
+		}
+	}
+	return declared
+}
+
+// checkIgnoredErrors flags assignments that discard an error with the
+// blank identifier, e.g. `n, _ := strconv.Atoi(s)` or `_ = f.Close()`.
+// Without type information, the discarded result counts as an error when
+// the callee is declared in the file and returns one there, or, for other
+// callees, when it is the last result, where Go functions return errors.
+// A `katich:ignore` comment on the line suppresses the issue.
+func checkIgnoredErrors(funcDecl *ast.FuncDecl, file *ast.File, fset *token.FileSet, declared map[string]*ast.FuncType) []Issue {
+	issues := make([]Issue, 0)
+
+	if funcDecl.Body == nil {
+		return issues
+	}
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Rhs) != 1 {
+			return true
+		}
+		call, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		name := calleeName(call)
+		for i, lhs := range assign.Lhs {
+			if ident, ok := lhs.(*ast.Ident); !ok || ident.Name != "_" {
+				continue
+			}
+			if !discardsError(name, i, len(assign.Lhs), declared) {
+				continue
+			}
+
+			line := fset.Position(assign.Pos()).Line
+			if hasIgnoreComment(file, fset, line, line) {
+				break
+			}
+			issues = append(issues, Issue{
+				Type:       IssueTypeIgnoredError,
+				Severity:   SeverityWarning,
+				Line:       line,
+				Message:    fmt.Sprintf("Error returned by '%s' is discarded", nodeString(fset, call.Fun)),
+				Suggestion: "Handle the error, or add a katich:ignore comment explaining why it cannot fail",
+			})
+			break
+		}
+		return true
+	})
+
+	return issues
+}
+
+// discardsError reports whether the result at index of a call to the named
+// function is an error
+func discardsError(name string, index, count int, declared map[string]*ast.FuncType) bool {
+	if funcType, ok := declared[name]; ok {
+		return errorResultIndex(funcType) == index
+	}
+	return index == count-1 && !commaOKFuncs[name]
+}
+
+// calleeName returns the name of the function a call invokes, e.g. Atoi
+// for strconv.Atoi, or "" for calls of function values
+func calleeName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+// checkPanics flags direct calls to panic. Must* helpers and init
+// functions are exempt, since panicking on bad input is their contract,
+// and a `katich:ignore` comment on the line suppresses the issue.
+func checkPanics(funcDecl *ast.FuncDecl, file *ast.File, fset *token.FileSet) []Issue {
+	issues := make([]Issue, 0)
+
+	name := funcDecl.Name.Name
+	if funcDecl.Body == nil || name == "init" || strings.HasPrefix(name, "Must") {
+		return issues
+	}
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := call.Fun.(*ast.Ident); !ok || ident.Name != "panic" || ident.Obj != nil {
+			return true
+		}
+
+		line := fset.Position(call.Pos()).Line
+		if hasIgnoreComment(file, fset, line, line) {
+			return true
+		}
+		issues = append(issues, Issue{
+			Type:       IssueTypePanic,
+			Severity:   SeverityWarning,
+			Line:       line,
+			Message:    fmt.Sprintf("Function '%s' calls panic", name),
+			Suggestion: "Return an error instead, and keep panics for unrecoverable programmer errors",
+		})
+		return true
+	})
+
+	return issues
+}
@@ -0,0 +1,412 @@
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+// isEnabled reports whether an opt-in check is enabled in the analysis config
+func (p *GoParser) isEnabled(check IssueType) bool {
+	return checkEnabled(p.config, check)
+}
+
+// checkEnabled reports whether an opt-in check is listed in enabled_checks
+// or only_checks and has not been skipped
+func checkEnabled(cfg config.AnalysisConfig, check IssueType) bool {
+	enabled := containsCheck(cfg.EnabledChecks, check) || containsCheck(cfg.EnabledChecks, config.AllChecks)
+	if !enabled && !containsCheck(cfg.OnlyChecks, check) {
+		return false
+	}
+	return checkSelected(cfg, check)
+}
+
+const (
+	// minParamStructParams is the parameter count at which grouping is suggested
+	minParamStructParams = 5
+	// minSameTypeParams is how many parameters must share a type to be grouped
+	minSameTypeParams = 3
+)
+
+// checkParameterStruct suggests a parameter struct for functions with many
+// parameters when several of them share a type, e.g. f(a, b, c, d, e int)
+func checkParameterStruct(fn FunctionInfo) []Issue {
+	if len(fn.Parameters) < minParamStructParams || len(fn.ParameterTypes) != len(fn.Parameters) {
+		return nil
+	}
+
+	// Find the most common parameter type, keeping the first one seen on ties
+	byType := make(map[string][]string)
+	commonType := ""
+	for i, paramType := range fn.ParameterTypes {
+		byType[paramType] = append(byType[paramType], fn.Parameters[i])
+		if len(byType[paramType]) > len(byType[commonType]) {
+			commonType = paramType
+		}
+	}
+
+	names := byType[commonType]
+	if len(names) < minSameTypeParams {
+		return nil
+	}
+
+	return []Issue{{
+		Type:     IssueTypeParameterStruct,
+		Severity: SeverityInfo,
+		Line:     fn.StartLine,
+		Message: fmt.Sprintf("Function '%s' takes %d parameters, %d of type %s",
+			fn.Name, len(fn.Parameters), len(names), commonType),
+		Suggestion: fmt.Sprintf("Consider grouping %s into a parameter struct (e.g. %sParams)",
+			strings.Join(names, ", "), exportedName(fn.Name)),
+	}}
+}
+
+// exportedName upper-cases the first letter of a Go identifier
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// checkErrorWrapping flags exported functions that return an error variable
+// unchanged instead of wrapping it with fmt.Errorf("...: %w", err)
+func (p *GoParser) checkErrorWrapping(funcDecl *ast.FuncDecl, fset *token.FileSet) []Issue {
+	issues := make([]Issue, 0)
+
+	if !funcDecl.Name.IsExported() || funcDecl.Body == nil {
+		return issues
+	}
+
+	errIndex := errorResultIndex(funcDecl.Type)
+	if errIndex < 0 {
+		return issues
+	}
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			// Returns inside closures belong to the closure
+			return false
+		case *ast.ReturnStmt:
+			if errIndex >= len(node.Results) {
+				return true
+			}
+			ident, ok := node.Results[errIndex].(*ast.Ident)
+			if !ok || !isBareErrorIdent(ident) {
+				return true
+			}
+			issues = append(issues, Issue{
+				Type:       IssueTypeErrorWrapping,
+				Severity:   SeverityInfo,
+				Line:       fset.Position(node.Pos()).Line,
+				Message:    fmt.Sprintf("Function '%s' returns '%s' without adding context", funcDecl.Name.Name, ident.Name),
+				Suggestion: fmt.Sprintf("Wrap the error, e.g. fmt.Errorf(\"...: %%w\", %s)", ident.Name),
+			})
+		}
+		return true
+	})
+
+	return issues
+}
+
+// checkEmptyErrorHandling flags `if err != nil {}` blocks whose body is
+// empty or contains only comments. A `katich:ignore` comment on the if
+// statement or inside the block suppresses the issue.
+func (p *GoParser) checkEmptyErrorHandling(funcDecl *ast.FuncDecl, file *ast.File, fset *token.FileSet) []Issue {
+	issues := make([]Issue, 0)
+
+	if funcDecl.Body == nil {
+		return issues
+	}
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		ifStmt, ok := n.(*ast.IfStmt)
+		if !ok || len(ifStmt.Body.List) > 0 {
+			return true
+		}
+
+		errName, ok := errNotNilCondition(ifStmt.Cond)
+		if !ok {
+			return true
+		}
+
+		startLine := fset.Position(ifStmt.Pos()).Line
+		endLine := fset.Position(ifStmt.Body.End()).Line
+		if hasIgnoreComment(file, fset, startLine, endLine) {
+			return true
+		}
+
+		issues = append(issues, Issue{
+			Type:       IssueTypeEmptyErrorHandling,
+			Severity:   SeverityWarning,
+			Line:       startLine,
+			Message:    fmt.Sprintf("Error '%s' is checked but not handled", errName),
+			Suggestion: "Return, log or otherwise handle the error, or add a katich:ignore comment explaining why it is safe to drop",
+		})
+		return true
+	})
+
+	return issues
+}
+
+// errNotNilCondition matches `err != nil` and returns the error's name
+func errNotNilCondition(cond ast.Expr) (string, bool) {
+	binary, ok := cond.(*ast.BinaryExpr)
+	if !ok || binary.Op != token.NEQ {
+		return "", false
+	}
+
+	operand := binary.X
+	if ident, ok := binary.X.(*ast.Ident); ok && ident.Name == "nil" {
+		operand = binary.Y
+	} else if ident, ok := binary.Y.(*ast.Ident); !ok || ident.Name != "nil" {
+		return "", false
+	}
+
+	ident, ok := operand.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if ident.Name == "err" || strings.HasSuffix(ident.Name, "Err") {
+		return ident.Name, true
+	}
+	return "", false
+}
+
+// hasIgnoreComment reports whether a katich:ignore comment appears between
+// two lines of a file, inclusive
+func hasIgnoreComment(file *ast.File, fset *token.FileSet, startLine, endLine int) bool {
+	for _, group := range file.Comments {
+		line := fset.Position(group.Pos()).Line
+		if line < startLine || line > endLine {
+			continue
+		}
+		if strings.Contains(group.Text(), ignoreMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorResultIndex returns the position of the error result in a function
+// signature, or -1 if the function does not return an error
+func errorResultIndex(funcType *ast.FuncType) int {
+	if funcType.Results == nil {
+		return -1
+	}
+
+	index := 0
+	for _, field := range funcType.Results.List {
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		if ident, ok := field.Type.(*ast.Ident); ok && ident.Name == "error" {
+			return index + count - 1
+		}
+		index += count
+	}
+
+	return -1
+}
+
+// isBareErrorIdent reports whether an identifier looks like a propagated
+// error variable rather than nil or an exported sentinel error (ErrXxx)
+func isBareErrorIdent(ident *ast.Ident) bool {
+	if ident.Name == "nil" || ident.Name == "_" {
+		return false
+	}
+	return !strings.HasPrefix(ident.Name, "Err")
+}
+
+// checkRedundantConditionals flags conditionals that can be simplified:
+// if/else branches returning opposite boolean literals, if/else branches
+// with identical bodies, and comparisons of an expression with itself
+func (p *GoParser) checkRedundantConditionals(funcDecl *ast.FuncDecl, fset *token.FileSet, typed *fileTypes) []Issue {
+	issues := make([]Issue, 0)
+
+	if funcDecl.Body == nil {
+		return issues
+	}
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.BlockStmt:
+			issues = append(issues, p.checkBooleanReturns(node.List, fset)...)
+
+		case *ast.IfStmt:
+			if elseBlock, ok := node.Else.(*ast.BlockStmt); ok && len(node.Body.List) > 0 {
+				if nodeString(fset, node.Body) == nodeString(fset, elseBlock) {
+					issues = append(issues, Issue{
+						Type:       IssueTypeSimplification,
+						Severity:   SeverityInfo,
+						Line:       fset.Position(node.Pos()).Line,
+						Message:    "If and else branches have identical bodies",
+						Suggestion: "Remove the conditional and keep a single copy of the body",
+					})
+				}
+			}
+
+		case *ast.BinaryExpr:
+			if nodeString(fset, node.X) == nodeString(fset, node.Y) && isSelfComparison(node, typed) {
+				issues = append(issues, Issue{
+					Type:       IssueTypeSimplification,
+					Severity:   SeverityInfo,
+					Line:       fset.Position(node.Pos()).Line,
+					Message:    fmt.Sprintf("Expression '%s' compares a value with itself", nodeString(fset, node)),
+					Suggestion: "This condition always has the same result; check for a typo or remove it",
+				})
+			}
+		}
+		return true
+	})
+
+	return issues
+}
+
+// checkBooleanReturns finds `if cond { return true } [else] return false`
+// sequences in a statement list
+func (p *GoParser) checkBooleanReturns(stmts []ast.Stmt, fset *token.FileSet) []Issue {
+	issues := make([]Issue, 0)
+
+	for i, stmt := range stmts {
+		ifStmt, ok := stmt.(*ast.IfStmt)
+		if !ok || ifStmt.Init != nil || len(ifStmt.Body.List) != 1 {
+			continue
+		}
+
+		thenValue, ok := boolReturn(ifStmt.Body.List[0])
+		if !ok {
+			continue
+		}
+
+		var elseStmt ast.Stmt
+		switch e := ifStmt.Else.(type) {
+		case *ast.BlockStmt:
+			if len(e.List) == 1 {
+				elseStmt = e.List[0]
+			}
+		case nil:
+			if i+1 < len(stmts) {
+				elseStmt = stmts[i+1]
+			}
+		}
+		if elseStmt == nil {
+			continue
+		}
+
+		elseValue, ok := boolReturn(elseStmt)
+		if !ok || elseValue == thenValue {
+			continue
+		}
+
+		cond := nodeString(fset, ifStmt.Cond)
+		replacement := "return " + cond
+		if !thenValue {
+			replacement = "return !(" + cond + ")"
+		}
+
+		issues = append(issues, Issue{
+			Type:       IssueTypeSimplification,
+			Severity:   SeverityInfo,
+			Line:       fset.Position(ifStmt.Pos()).Line,
+			Message:    "Conditional returns boolean literals",
+			Suggestion: fmt.Sprintf("Simplify to '%s'", replacement),
+		})
+	}
+
+	return issues
+}
+
+// boolReturn reports whether stmt is `return true` or `return false`
+func boolReturn(stmt ast.Stmt) (value bool, ok bool) {
+	ret, isReturn := stmt.(*ast.ReturnStmt)
+	if !isReturn || len(ret.Results) != 1 {
+		return false, false
+	}
+	ident, isIdent := ret.Results[0].(*ast.Ident)
+	if !isIdent || (ident.Name != "true" && ident.Name != "false") {
+		return false, false
+	}
+	return ident.Name == "true", true
+}
+
+// isSelfComparison reports whether a binary expression whose operands
+// are identical always yields the same result. Operands containing calls
+// are excluded since f() == f() may compare different values, as are
+// comparisons of floats (or of operands whose type is unknown) because
+// x == x and x != x are the idiomatic NaN checks.
+func isSelfComparison(expr *ast.BinaryExpr, typed *fileTypes) bool {
+	if containsCall(expr.X) {
+		return false
+	}
+	switch expr.Op {
+	case token.LAND, token.LOR:
+		return true
+	case token.EQL, token.LSS, token.GTR, token.LEQ, token.GEQ:
+		basic, ok := typed.typeOf(expr.X).Underlying().(*types.Basic)
+		return ok && basic.Kind() != types.Invalid && basic.Info()&(types.IsFloat|types.IsComplex) == 0
+	}
+	return false
+}
+
+// containsCall reports whether an expression contains a call (or a
+// conversion, which is syntactically the same)
+func containsCall(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if _, ok := n.(*ast.CallExpr); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// fileTypes type-checks a single file on first use. Imports are not
+// resolved, so expressions depending on other packages or on declarations
+// in sibling files have no type; checks treat those as unknown.
+type fileTypes struct {
+	fset *token.FileSet
+	file *ast.File
+	info *types.Info
+}
+
+// typeOf returns the type of expr, or types.Typ[types.Invalid] if unknown
+func (t *fileTypes) typeOf(expr ast.Expr) types.Type {
+	if t.info == nil {
+		t.info = &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+		conf := types.Config{
+			Importer: noImporter{},
+			Error:    func(error) {}, // keep checking past unresolved names
+		}
+		conf.Check(t.file.Name.Name, t.fset, []*ast.File{t.file}, t.info)
+	}
+	if tv, ok := t.info.Types[expr]; ok && tv.Type != nil {
+		return tv.Type
+	}
+	return types.Typ[types.Invalid]
+}
+
+// noImporter fails every import so type checking stays within the file
+type noImporter struct{}
+
+func (noImporter) Import(path string) (*types.Package, error) {
+	return nil, fmt.Errorf("imports are not resolved: %s", path)
+}
+
+// nodeString renders an AST node back to source for structural comparison
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
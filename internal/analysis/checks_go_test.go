@@ -0,0 +1,45 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+func TestSelfComparison(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{"int equality", "var x int\n\t_ = x == x", true},
+		{"string ordering", "var s string\n\t_ = s < s", true},
+		{"repeated boolean operand", "var ok bool\n\t_ = ok && ok", true},
+		{"float NaN check", "var f float64\n\t_ = f == f", false},
+		{"float inequality", "var f float64\n\t_ = f != f", false},
+		{"unknown type", "_ = other.Value == other.Value", false},
+		{"call operands", "_ = next() == next()", false},
+		{"call in boolean operands", "_ = ready() && ready()", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "package p\n\nfunc next() int { return 0 }\n\nfunc ready() bool { return true }\n\nfunc f() {\n\t" + tt.body + "\n}\n"
+			analysis, err := NewGoParser(config.DefaultConfig().Analysis).ParseSource("p.go", []byte(src))
+			if err != nil {
+				t.Fatalf("ParseSource: %v", err)
+			}
+
+			got := false
+			for _, issue := range analysis.Issues {
+				if issue.Type == IssueTypeSimplification && strings.Contains(issue.Message, "compares a value with itself") {
+					got = true
+				}
+			}
+			if got != tt.want {
+				t.Errorf("self-comparison reported = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
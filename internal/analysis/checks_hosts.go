@@ -0,0 +1,186 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/token"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/katichai/katich/internal/context"
+)
+
+var (
+	// urlLiteral matches an absolute URL such as https://api.example.com/v1
+	urlLiteral = regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'` + "`" + `<>()\[\]{},;]+`)
+
+	// ipLiteral matches a dotted IPv4 address, optionally with a port
+	ipLiteral = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d{1,5})?\b`)
+
+	// hostPortLiteral matches host:port pairs such as db.internal:5432
+	hostPortLiteral = regexp.MustCompile(`\b(?:localhost|[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)+):\d{2,5}\b`)
+)
+
+// hostMatch is a hardcoded location found in a literal or line
+type hostMatch struct {
+	kind    string // URL, IP address or host:port
+	literal string
+	host    string
+}
+
+// findHardcodedHosts returns the URLs, IP addresses and host:port pairs in
+// text whose host is not allowlisted
+func findHardcodedHosts(text string, allowlist []string) []hostMatch {
+	matches := make([]hostMatch, 0)
+	covered := make([][]int, 0)
+
+	add := func(kind string, span []int, host string) {
+		for _, c := range covered {
+			if span[0] >= c[0] && span[1] <= c[1] {
+				return
+			}
+		}
+		covered = append(covered, span)
+		if !hostAllowed(host, allowlist) {
+			matches = append(matches, hostMatch{kind: kind, literal: text[span[0]:span[1]], host: host})
+		}
+	}
+
+	for _, span := range urlLiteral.FindAllStringIndex(text, -1) {
+		literal := text[span[0]:span[1]]
+		host := literal[strings.Index(literal, "://")+3:]
+		if i := strings.IndexAny(host, "/?#"); i >= 0 {
+			host = host[:i]
+		}
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+		if host == "" {
+			continue // e.g. file:///tmp
+		}
+		add("URL", span, stripPort(host))
+	}
+
+	for _, span := range ipLiteral.FindAllStringIndex(text, -1) {
+		host := stripPort(text[span[0]:span[1]])
+		if net.ParseIP(host) == nil {
+			continue // e.g. a four-part version number above 255
+		}
+		add("IP address", span, host)
+	}
+
+	for _, span := range hostPortLiteral.FindAllStringIndex(text, -1) {
+		add("host:port", span, stripPort(text[span[0]:span[1]]))
+	}
+
+	return matches
+}
+
+// stripPort removes a trailing :port from a host
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return strings.Trim(host, "[]")
+}
+
+// hostAllowed reports whether a host or one of its parent domains is in
+// the allowlist
+func hostAllowed(host string, allowlist []string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range allowlist {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTestOrConfigPath reports whether hardcoded hosts are expected in a file,
+// such as tests, fixtures and configuration
+func isTestOrConfigPath(filePath string) bool {
+	if context.IsTestFile(filePath) {
+		return true
+	}
+
+	slashed := "/" + filepath.ToSlash(filePath)
+	for _, dir := range []string{"/fixtures/", "/config/", "/configs/"} {
+		if strings.Contains(slashed, dir) {
+			return true
+		}
+	}
+	return strings.HasPrefix(strings.ToLower(filepath.Base(filePath)), "config.")
+}
+
+// checkGoHardcodedHosts flags hosts in Go string literals, skipping import
+// paths and lines marked katich:ignore
+func checkGoHardcodedHosts(file *ast.File, fset *token.FileSet, lines []string, allowlist []string) []Issue {
+	issues := make([]Issue, 0)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.BasicLit:
+			if node.Kind != token.STRING {
+				return true
+			}
+			value, err := strconv.Unquote(node.Value)
+			if err != nil {
+				return true
+			}
+			line := fset.Position(node.Pos()).Line
+			if line <= len(lines) && strings.Contains(lines[line-1], ignoreMarker) {
+				return true
+			}
+			for _, match := range findHardcodedHosts(value, allowlist) {
+				issues = append(issues, hardcodedHostIssue(line, match))
+			}
+		}
+		return true
+	})
+
+	return issues
+}
+
+// checkHardcodedHostLines flags hosts in non-comment lines of languages
+// without a parser. lineOffset is added to reported lines.
+func checkHardcodedHostLines(content string, lineOffset int, allowlist []string) []Issue {
+	issues := make([]Issue, 0)
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if isCommentLine(trimmed) || strings.Contains(line, ignoreMarker) {
+			continue
+		}
+		for _, match := range findHardcodedHosts(line, allowlist) {
+			issues = append(issues, hardcodedHostIssue(i+1+lineOffset, match))
+		}
+	}
+
+	return issues
+}
+
+// isCommentLine reports whether a trimmed line is entirely a comment
+func isCommentLine(trimmed string) bool {
+	for _, prefix := range []string{"//", "#", "/*", "*", "<!--", "--"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hardcodedHostIssue builds an issue for a hardcoded location
+func hardcodedHostIssue(line int, match hostMatch) Issue {
+	return Issue{
+		Type:       IssueTypeHardcodedHost,
+		Severity:   SeverityInfo,
+		Line:       line,
+		Message:    "Hardcoded " + match.kind + " '" + match.literal + "'",
+		Suggestion: "Move it to configuration or an environment variable, or add " + match.host + " to host_allowlist",
+	}
+}
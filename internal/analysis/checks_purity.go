@@ -0,0 +1,302 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// purePackages are standard library packages whose functions neither do
+// I/O nor mutate their arguments
+var purePackages = map[string]bool{
+	"errors": true, "math": true, "path": true, "strconv": true,
+	"strings": true, "unicode": true, "utf8": true,
+}
+
+// pureFmtFuncs are the fmt functions that only format
+var pureFmtFuncs = map[string]bool{
+	"Errorf": true, "Sprint": true, "Sprintf": true, "Sprintln": true,
+}
+
+// ioPackages are packages whose calls are treated as I/O
+var ioPackages = map[string]bool{
+	"bufio": true, "exec": true, "http": true, "io": true, "ioutil": true,
+	"log": true, "net": true, "os": true, "sql": true, "syscall": true,
+}
+
+// pureBuiltins are builtins without side effects. copy, delete, clear and
+// close mutate their first argument and are handled separately.
+var pureBuiltins = map[string]bool{
+	"append": true, "cap": true, "complex": true, "imag": true, "len": true,
+	"make": true, "max": true, "min": true, "new": true, "panic": true,
+	"real": true,
+}
+
+// getterPrefixes mark names that read as queries or calculations
+var getterPrefixes = map[string]bool{
+	"calc": true, "calculate": true, "compute": true, "count": true,
+	"get": true, "has": true, "is": true, "sum": true,
+}
+
+// sideEffects is what checkPurity learned about a function body
+type sideEffects struct {
+	mutations []string // definite writes outside the function's own locals
+	io        bool     // calls into an I/O package
+	unknown   bool     // calls whose effects cannot be judged syntactically
+}
+
+// checkPurity reports functions that look side-effect free but are not
+// documented as such, and getter- or calculator-named functions that
+// mutate state. The analysis is syntactic and errs on the side of "not
+// pure": any call it cannot vouch for disqualifies a function.
+func checkPurity(funcDecl *ast.FuncDecl, file *ast.File, fset *token.FileSet) []Issue {
+	if funcDecl.Body == nil || len(funcDecl.Body.List) == 0 {
+		return nil
+	}
+
+	effects := collectSideEffects(funcDecl, file)
+	name := funcDecl.Name.Name
+	line := fset.Position(funcDecl.Pos()).Line
+
+	if len(effects.mutations) > 0 && isGetterName(name) {
+		return []Issue{{
+			Type:       IssueTypePurity,
+			Severity:   SeverityInfo,
+			Line:       line,
+			Message:    fmt.Sprintf("Function '%s' is named like a query but writes %s", name, strings.Join(effects.mutations, ", ")),
+			Suggestion: "Rename it to say what it changes, or move the writes to the caller",
+		}}
+	}
+
+	hasResults := funcDecl.Type.Results != nil && len(funcDecl.Type.Results.List) > 0
+	if len(effects.mutations) == 0 && !effects.io && !effects.unknown && hasResults && !documentsPurity(funcDecl.Doc) {
+		return []Issue{{
+			Type:       IssueTypePurity,
+			Severity:   SeverityInfo,
+			Line:       line,
+			Message:    fmt.Sprintf("Function '%s' has no side effects", name),
+			Suggestion: "Its result depends only on its inputs; say so in its doc comment so callers can rely on it",
+		}}
+	}
+
+	return nil
+}
+
+// collectSideEffects walks a function body, including closures, and
+// records writes to parameters, the receiver and package state
+func collectSideEffects(funcDecl *ast.FuncDecl, file *ast.File) sideEffects {
+	effects := sideEffects{}
+	params := paramObjects(funcDecl)
+	seen := make(map[string]bool)
+
+	mutate := func(expr ast.Expr) {
+		if what := mutationTarget(expr, params, file); what != "" && !seen[what] {
+			seen[what] = true
+			effects.mutations = append(effects.mutations, what)
+		}
+	}
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if node.Tok != token.DEFINE {
+				for _, lhs := range node.Lhs {
+					mutate(lhs)
+				}
+			}
+		case *ast.IncDecStmt:
+			mutate(node.X)
+		case *ast.SendStmt:
+			effects.mutations = appendOnce(effects.mutations, seen, "to a channel")
+		case *ast.GoStmt:
+			effects.mutations = appendOnce(effects.mutations, seen, "from a goroutine")
+		case *ast.CallExpr:
+			classifyCall(node, &effects, mutate)
+		}
+		return true
+	})
+
+	return effects
+}
+
+// classifyCall records the effect of a call on effects
+func classifyCall(call *ast.CallExpr, effects *sideEffects, mutate func(ast.Expr)) {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		switch {
+		case fn.Obj != nil:
+			// A function or closure declared in this file
+			effects.unknown = true
+		case fn.Name == "copy" || fn.Name == "delete" || fn.Name == "clear" || fn.Name == "close":
+			if len(call.Args) > 0 {
+				mutate(call.Args[0])
+			}
+		case !pureBuiltins[fn.Name] && !isConversion(fn.Name):
+			effects.unknown = true
+		}
+
+	case *ast.SelectorExpr:
+		pkg, ok := fn.X.(*ast.Ident)
+		if !ok || pkg.Obj != nil {
+			// A method call
+			effects.unknown = true
+			return
+		}
+		switch {
+		case ioPackages[pkg.Name]:
+			effects.io = true
+		case pkg.Name == "fmt" && pureFmtFuncs[fn.Sel.Name]:
+		case pkg.Name == "fmt":
+			effects.io = true
+		case !purePackages[pkg.Name]:
+			effects.unknown = true
+		}
+
+	case *ast.ArrayType, *ast.MapType, *ast.ParenExpr, *ast.InterfaceType:
+		// Type conversions
+
+	default:
+		effects.unknown = true
+	}
+}
+
+// mutationTarget describes what an assignment to expr writes, or returns
+// "" for writes to the function's own locals
+func mutationTarget(expr ast.Expr, params map[*ast.Object]ast.Expr, file *ast.File) string {
+	root, direct := rootIdent(expr)
+	if root == nil {
+		return "through an expression"
+	}
+	if root.Name == "_" {
+		return ""
+	}
+
+	if paramType, ok := params[root.Obj]; ok {
+		// Assigning the parameter itself, or a field of a value copy,
+		// stays local
+		if direct || (!isReferenceType(paramType) && !throughReference(expr)) {
+			return ""
+		}
+		return fmt.Sprintf("through '%s'", root.Name)
+	}
+
+	// Package-level variables are declared at file scope, or in another
+	// file of the package and left unresolved
+	if root.Obj == nil || root.Obj == file.Scope.Lookup(root.Name) {
+		return fmt.Sprintf("package variable '%s'", root.Name)
+	}
+	return ""
+}
+
+// rootIdent returns the variable an lvalue is rooted at, and whether expr
+// is that variable itself
+func rootIdent(expr ast.Expr) (*ast.Ident, bool) {
+	direct := true
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return e, direct
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		default:
+			return nil, false
+		}
+		direct = false
+	}
+}
+
+// throughReference reports whether an lvalue dereferences or indexes on
+// its way to the root, so the write is visible outside a value copy
+func throughReference(expr ast.Expr) bool {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr, *ast.IndexExpr, *ast.IndexListExpr:
+			return true
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		default:
+			return false
+		}
+	}
+}
+
+// isReferenceType reports whether writes through a value of this type are
+// visible to the caller
+func isReferenceType(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.StarExpr, *ast.MapType, *ast.ChanType:
+		return true
+	case *ast.ArrayType:
+		return t.Len == nil
+	}
+	return false
+}
+
+// paramObjects maps the receiver and parameters of a function to their
+// declared types
+func paramObjects(funcDecl *ast.FuncDecl) map[*ast.Object]ast.Expr {
+	params := make(map[*ast.Object]ast.Expr)
+	for _, list := range []*ast.FieldList{funcDecl.Recv, funcDecl.Type.Params} {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				if name.Obj != nil {
+					params[name.Obj] = field.Type
+				}
+			}
+		}
+	}
+	return params
+}
+
+// isConversion reports whether an unresolved identifier names a predeclared
+// type, making the call a conversion
+func isConversion(name string) bool {
+	switch name {
+	case "bool", "byte", "complex64", "complex128", "error", "float32", "float64",
+		"int", "int8", "int16", "int32", "int64", "rune", "string",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "any":
+		return true
+	}
+	return false
+}
+
+// isGetterName reports whether a function name starts with a query or
+// calculation verb, e.g. GetUser, isValid or computeTotal
+func isGetterName(name string) bool {
+	words := splitCamelCase(name)
+	return len(words) > 1 && getterPrefixes[strings.ToLower(words[0])]
+}
+
+// documentsPurity reports whether a doc comment already says the function
+// is pure or free of side effects
+func documentsPurity(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	text := strings.ToLower(doc.Text())
+	return strings.Contains(text, "pure") || strings.Contains(text, "side effect") || strings.Contains(text, "side-effect")
+}
+
+// appendOnce appends what unless it was already recorded
+func appendOnce(list []string, seen map[string]bool, what string) []string {
+	if seen[what] {
+		return list
+	}
+	seen[what] = true
+	return append(list, what)
+}
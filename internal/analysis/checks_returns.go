@@ -0,0 +1,201 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// pyReturnValue captures the expression after a Python return keyword
+	pyReturnValue = regexp.MustCompile(`\breturn\b\s*([^;]*)`)
+
+	pyNumber = regexp.MustCompile(`^-?\d[\d_]*(\.\d*)?([eE][-+]?\d+)?$`)
+)
+
+// checkReturnShapes looks at the results of a Go function declared as
+// interface{} or any. When every returned literal has the same type the
+// result could be typed; when the literals differ the branches return
+// different kinds of values. Closures are not followed since their returns
+// belong to them.
+func checkReturnShapes(funcDecl *ast.FuncDecl, fset *token.FileSet) []Issue {
+	if funcDecl.Body == nil || funcDecl.Type.Results == nil {
+		return nil
+	}
+
+	// Flatten the results so "(a, b any)" yields one slot per value
+	slots := make([]ast.Expr, 0)
+	for _, field := range funcDecl.Type.Results.List {
+		for i := 0; i < max(len(field.Names), 1); i++ {
+			slots = append(slots, field.Type)
+		}
+	}
+
+	issues := make([]Issue, 0)
+	for slot, resultType := range slots {
+		if !isEmptyInterface(resultType) {
+			continue
+		}
+
+		kinds := make(map[string]bool)
+		hasNil, unknown := false, false
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.ReturnStmt:
+				if len(node.Results) != len(slots) {
+					// A bare return, or a call returning every result
+					unknown = true
+					return true
+				}
+				switch kind := goLiteralKind(node.Results[slot], fset); kind {
+				case "nil":
+					hasNil = true
+				case "":
+					unknown = true
+				default:
+					kinds[kind] = true
+				}
+			}
+			return true
+		})
+
+		line := fset.Position(funcDecl.Pos()).Line
+		name := funcDecl.Name.Name
+		switch {
+		case len(kinds) > 1:
+			issues = append(issues, returnShapeIssue(name, line, sortedKeys(kinds)))
+		case len(kinds) == 1 && !unknown:
+			kind := sortedKeys(kinds)[0]
+			if hasNil && !isNilable(kind) {
+				continue
+			}
+			issues = append(issues, Issue{
+				Type:       IssueTypeReturnShape,
+				Severity:   SeverityInfo,
+				Line:       line,
+				Message:    fmt.Sprintf("Function '%s' returns %s but every value it returns is a %s", name, nodeString(fset, resultType), kind),
+				Suggestion: fmt.Sprintf("Declare the result as %s so callers need no type assertion", kind),
+			})
+		}
+	}
+
+	return issues
+}
+
+// isEmptyInterface reports whether a type is interface{} or any
+func isEmptyInterface(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name == "any"
+	case *ast.InterfaceType:
+		return t.Methods == nil || len(t.Methods.List) == 0
+	}
+	return false
+}
+
+// goLiteralKind returns the static type of a returned literal, "nil" for
+// nil, or "" when the type cannot be told without type checking
+func goLiteralKind(expr ast.Expr, fset *token.FileSet) string {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			return "int"
+		case token.FLOAT:
+			return "float64"
+		case token.IMAG:
+			return "complex128"
+		case token.CHAR:
+			return "rune"
+		case token.STRING:
+			return "string"
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "true", "false":
+			return "bool"
+		case "nil":
+			return "nil"
+		}
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return nodeString(fset, e.Type)
+		}
+	case *ast.UnaryExpr:
+		if lit, ok := e.X.(*ast.CompositeLit); ok && e.Op == token.AND && lit.Type != nil {
+			return "*" + nodeString(fset, lit.Type)
+		}
+	case *ast.ParenExpr:
+		return goLiteralKind(e.X, fset)
+	}
+	return ""
+}
+
+// isNilable reports whether nil is a valid value of a type produced by
+// goLiteralKind
+func isNilable(kind string) bool {
+	return strings.HasPrefix(kind, "*") || strings.HasPrefix(kind, "[]") || strings.HasPrefix(kind, "map[")
+}
+
+// pythonReturnKinds records the kind of value each return statement in a
+// line produces. None and expressions that cannot be judged from the
+// source are skipped.
+func pythonReturnKinds(text string, kinds map[string]bool) {
+	for _, m := range pyReturnValue.FindAllStringSubmatch(text, -1) {
+		if kind := pythonValueKind(strings.TrimSpace(m[1])); kind != "" {
+			kinds[kind] = true
+		}
+	}
+}
+
+// pythonValueKind classifies a Python expression with its string literals
+// already blanked to ""
+func pythonValueKind(expr string) string {
+	switch {
+	case expr == "" || expr == "None":
+		return ""
+	case len(splitTopLevel(expr, ',')) > 1:
+		return "tuple"
+	case pyDocString.MatchString(expr):
+		return "str"
+	case expr == "True" || expr == "False":
+		return "bool"
+	case pyNumber.MatchString(expr):
+		return "number"
+	case strings.HasPrefix(expr, "["):
+		return "list"
+	case strings.HasPrefix(expr, "{"):
+		if strings.HasPrefix(expr, "{}") || strings.Contains(splitTopLevel(strings.Trim(expr, "{}"), ',')[0], ":") {
+			return "dict"
+		}
+		return "set"
+	}
+	return ""
+}
+
+// returnShapeIssue flags a function whose branches return different kinds
+// of values
+func returnShapeIssue(name string, line int, kinds []string) Issue {
+	return Issue{
+		Type:       IssueTypeReturnShape,
+		Severity:   SeverityInfo,
+		Line:       line,
+		Message:    fmt.Sprintf("Function '%s' returns different kinds of values: %s", name, strings.Join(kinds, ", ")),
+		Suggestion: "Return one shape from every branch, or split the function so each result has its own type",
+	}
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
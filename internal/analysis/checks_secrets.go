@@ -0,0 +1,234 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/token"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// secretPattern is the shape of a credential issued by a known service
+type secretPattern struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+var (
+	// secretPatterns match credentials by their format, whatever they are
+	// assigned to
+	secretPatterns = []secretPattern{
+		{"private key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP )?PRIVATE KEY`)},
+		{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`)},
+		{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+		{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+		{"Stripe key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+		{"OpenAI or Anthropic key", regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{32,}`)},
+	}
+
+	// secretName matches names that hold a credential: anything ending in
+	// key, token or secret, and passwords
+	secretName = regexp.MustCompile(`(?i)(?:key|token|secret|password|passwd|pwd)$`)
+
+	// passwordName matches the names whose values are checked without an
+	// entropy threshold, since passwords are often short and plain words
+	passwordName = regexp.MustCompile(`(?i)(?:password|passwd|pwd)$`)
+
+	// secretAssignment matches name = "value" and name: "value" on one line,
+	// in languages without a parser
+	secretAssignment = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_.-]*)["']?\s*(?::=|=|:)\s*["']([^"'\s]+)["']`)
+
+	// envVarName matches values that name an environment variable rather
+	// than hold a secret, e.g. OPENAI_API_KEY
+	envVarName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+	// tokenValue matches values drawn from the alphabets credentials use,
+	// such as hex and base64, with at least one digit; paths, hosts and
+	// words do not match
+	tokenValue = regexp.MustCompile(`^[A-Za-z0-9+/=_-]*[0-9][A-Za-z0-9+/=_-]*$`)
+)
+
+const (
+	// minSecretLength and minSecretEntropy are what a value assigned to a
+	// key, token or secret name needs to be reported: random-looking and
+	// long enough to be a credential rather than an identifier
+	minSecretLength  = 16
+	minSecretEntropy = 3.5
+
+	// minPasswordLength is the shortest password value reported
+	minPasswordLength = 4
+)
+
+// secretPlaceholders appear in values that only stand in for a credential
+var secretPlaceholders = []string{"your", "example", "changeme", "xxx", "***", "<", "${", "{{", "%s", "dummy", "redacted"}
+
+// findSecretPatterns returns the kinds of known credential formats in text
+func findSecretPatterns(text string) []string {
+	kinds := make([]string, 0)
+	for _, p := range secretPatterns {
+		if p.pattern.MatchString(text) {
+			kinds = append(kinds, p.kind)
+		}
+	}
+	return kinds
+}
+
+// looksLikeSecret reports whether value, assigned to name, is plausibly a
+// hardcoded credential
+func looksLikeSecret(name, value string) bool {
+	if !secretName.MatchString(name) || value == "" || strings.ContainsAny(value, " \t\n") {
+		return false
+	}
+	if envVarName.MatchString(value) {
+		return false
+	}
+	lower := strings.ToLower(value)
+	for _, placeholder := range secretPlaceholders {
+		if strings.Contains(lower, placeholder) {
+			return false
+		}
+	}
+	if passwordName.MatchString(name) {
+		return len(value) >= minPasswordLength
+	}
+	return len(value) >= minSecretLength && tokenValue.MatchString(value) &&
+		strings.ContainsAny(value, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") &&
+		shannonEntropy(value) >= minSecretEntropy
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// checkGoHardcodedSecrets flags Go string literals in a known credential
+// format, and literals assigned to key, token, secret and password names,
+// skipping lines marked katich:ignore
+func checkGoHardcodedSecrets(file *ast.File, fset *token.FileSet, lines []string) []Issue {
+	issues := make([]Issue, 0)
+	reported := make(map[int]bool)
+
+	report := func(lit ast.Expr, name string) {
+		node, ok := lit.(*ast.BasicLit)
+		if !ok || node.Kind != token.STRING {
+			return
+		}
+		value, err := strconv.Unquote(node.Value)
+		if err != nil {
+			return
+		}
+		line := fset.Position(node.Pos()).Line
+		if reported[line] || (line <= len(lines) && strings.Contains(lines[line-1], ignoreMarker)) {
+			return
+		}
+		if kinds := findSecretPatterns(value); len(kinds) > 0 {
+			reported[line] = true
+			issues = append(issues, hardcodedSecretIssue(line, kinds[0], name))
+		} else if name != "" && looksLikeSecret(name, value) {
+			reported[line] = true
+			issues = append(issues, hardcodedSecretIssue(line, "", name))
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.AssignStmt:
+			if len(node.Lhs) == len(node.Rhs) {
+				for i, lhs := range node.Lhs {
+					report(node.Rhs[i], exprName(lhs))
+				}
+			}
+		case *ast.ValueSpec:
+			if len(node.Names) == len(node.Values) {
+				for i, name := range node.Names {
+					report(node.Values[i], name.Name)
+				}
+			}
+		case *ast.KeyValueExpr:
+			report(node.Value, exprName(node.Key))
+		case *ast.BasicLit:
+			report(node, "")
+		}
+		return true
+	})
+
+	return issues
+}
+
+// exprName returns the name an assignment or composite literal key refers
+// to: an identifier, a selected field or a string map key
+func exprName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.BasicLit:
+		if name, err := strconv.Unquote(e.Value); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// checkHardcodedSecretLines flags credentials in non-comment lines of
+// languages without a Go parser. lineOffset is added to reported lines.
+func checkHardcodedSecretLines(content string, lineOffset int) []Issue {
+	issues := make([]Issue, 0)
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if isCommentLine(trimmed) || strings.Contains(line, ignoreMarker) {
+			continue
+		}
+		if kinds := findSecretPatterns(line); len(kinds) > 0 {
+			issues = append(issues, hardcodedSecretIssue(i+1+lineOffset, kinds[0], ""))
+			continue
+		}
+		for _, match := range secretAssignment.FindAllStringSubmatch(line, -1) {
+			name := match[1][strings.LastIndex(match[1], ".")+1:]
+			if looksLikeSecret(name, match[2]) {
+				issues = append(issues, hardcodedSecretIssue(i+1+lineOffset, "", name))
+				break
+			}
+		}
+	}
+
+	return issues
+}
+
+// hardcodedSecretIssue builds an issue for a hardcoded credential. The
+// value itself is never quoted, so the report does not spread it further.
+func hardcodedSecretIssue(line int, kind, name string) Issue {
+	message := "Possible hardcoded secret"
+	switch {
+	case kind != "" && name != "":
+		message = "Hardcoded " + kind + " in '" + name + "'"
+	case kind != "":
+		message = "Hardcoded " + kind
+	case name != "":
+		message = "Possible hardcoded secret in '" + name + "'"
+	}
+	return Issue{
+		Type:       IssueTypeHardcodedSecret,
+		Severity:   SeverityWarning,
+		Line:       line,
+		Message:    message,
+		Suggestion: "Load it from an environment variable or a secret store, and rotate it if it was ever committed",
+	}
+}
@@ -0,0 +1,42 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+func TestProfilesEnableTheirChecks(t *testing.T) {
+	tests := []struct {
+		profile string
+		want    []IssueType // opt-in checks that must run; nil means all of them
+	}{
+		{"strict", nil},
+		{"security", []IssueType{IssueTypeErrorWrapping, IssueTypeIgnoredError, IssueTypePanic}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			profile, err := config.GetProfile(tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.DefaultConfig()
+			profile.Apply(cfg)
+
+			want := tt.want
+			if want == nil {
+				for _, check := range Checks {
+					if check.OptIn {
+						want = append(want, check.Type)
+					}
+				}
+			}
+			for _, check := range want {
+				if !checkEnabled(cfg.Analysis, check) {
+					t.Errorf("%s does not enable %s", tt.profile, check)
+				}
+			}
+		})
+	}
+}
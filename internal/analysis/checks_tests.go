@@ -0,0 +1,96 @@
+package analysis
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// packageTestNames returns the names of Test functions declared in the
+// _test.go files of a directory, with the "Test" prefix removed
+func (a *Analyzer) packageTestNames(dir string) []string {
+	if names, ok := a.testNames[dir]; ok {
+		return names
+	}
+
+	names := make([]string, 0)
+	paths, _ := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	parser := NewGoParser(a.config)
+	for _, path := range paths {
+		content, err := a.files.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		fileAnalysis, err := parser.ParseSource(path, content)
+		if err != nil {
+			continue
+		}
+		for _, fn := range fileAnalysis.Functions {
+			if fn.Receiver == "" && strings.HasPrefix(fn.Name, "Test") {
+				names = append(names, strings.TrimPrefix(fn.Name, "Test"))
+			}
+		}
+	}
+
+	a.testNames[dir] = names
+	return names
+}
+
+// checkMissingTests flags exported Go functions in a non-test file that no
+// TestXxx function in the same package appears to cover. Matching is by
+// name: TestName, TestName_case, TestType_Method and TestTypeMethod all
+// count as covering Type.Method.
+func (a *Analyzer) checkMissingTests(filePath string, fileAnalysis *FileAnalysis) []Issue {
+	issues := make([]Issue, 0)
+
+	if fileAnalysis.Language != "Go" || strings.HasSuffix(filePath, "_test.go") {
+		return issues
+	}
+
+	dir := filepath.Dir(filePath)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(a.rootPath, dir)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return issues
+	}
+	tests := a.packageTestNames(dir)
+
+	for _, fn := range fileAnalysis.Functions {
+		if !fn.IsExported || hasMatchingTest(fn, tests) {
+			continue
+		}
+
+		name := fn.Name
+		if fn.Receiver != "" {
+			name = fn.Receiver + "." + fn.Name
+		}
+		issues = append(issues, Issue{
+			Type:       IssueTypeMissingTest,
+			Severity:   SeverityInfo,
+			Line:       fn.StartLine,
+			Message:    fmt.Sprintf("Exported function '%s' has no matching test", name),
+			Suggestion: fmt.Sprintf("Add a Test%s function in a _test.go file of this package", strings.ReplaceAll(name, ".", "_")),
+		})
+	}
+
+	return issues
+}
+
+// hasMatchingTest reports whether any test name appears to cover fn
+func hasMatchingTest(fn FunctionInfo, tests []string) bool {
+	candidates := []string{fn.Name}
+	if fn.Receiver != "" {
+		candidates = []string{fn.Receiver + "_" + fn.Name, fn.Receiver + fn.Name}
+	}
+
+	for _, test := range tests {
+		for _, candidate := range candidates {
+			if test == candidate || strings.HasPrefix(test, candidate+"_") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,340 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/context"
+)
+
+// ignoreMarker in a comment suppresses checks that support opting out
+const ignoreMarker = "katich:ignore"
+
+var (
+	// emptyCatchBlock matches catch blocks containing only whitespace and comments
+	emptyCatchBlock = regexp.MustCompile(`\bcatch\b\s*(\([^)]*\))?\s*\{((?:\s|//[^\n]*|/\*[\s\S]*?\*/)*)\}`)
+
+	// exceptClause matches a Python except clause ending its line
+	exceptClause = regexp.MustCompile(`^\s*except\b[^:]*:\s*(#.*)?$`)
+
+	// commentStart matches a token opening a comment, or the leading * of
+	// a block comment line
+	commentStart = regexp.MustCompile(`(//|#|/\*|^\*|<!--|--)`)
+)
+
+// maxDebtMessageLength bounds the comment text quoted in a tech debt issue
+const maxDebtMessageLength = 120
+
+// debtMatcher finds the configured debt markers, such as TODO or HACK, in
+// comments. The tech_debt check and the debt marker count behind
+// max_debt_growth and trend share it, so they always agree.
+type debtMatcher struct {
+	markers *regexp.Regexp
+}
+
+// debtMatchers holds the compiled matcher of each marker list
+var debtMatchers sync.Map
+
+// debtMatcherFor returns the matcher for markers, compiled once per marker
+// list. It returns nil, which matches nothing, when markers is empty.
+func debtMatcherFor(markers []string) *debtMatcher {
+	if len(markers) == 0 {
+		return nil
+	}
+	key := strings.Join(markers, "\x00")
+	if m, ok := debtMatchers.Load(key); ok {
+		return m.(*debtMatcher)
+	}
+
+	quoted := make([]string, 0, len(markers))
+	for _, marker := range markers {
+		quoted = append(quoted, regexp.QuoteMeta(marker))
+	}
+	m, _ := debtMatchers.LoadOrStore(key, &debtMatcher{
+		markers: regexp.MustCompile(`\b(` + strings.Join(quoted, "|") + `)\b`),
+	})
+	return m.(*debtMatcher)
+}
+
+// find returns the offset of the first debt marker in the comment of a
+// trimmed line, or -1. inComment reports that the line starts inside a
+// block comment; otherwise the marker must follow a comment token rather
+// than sit in a string earlier on the line. Lines opted out with
+// katich:ignore never match.
+func (m *debtMatcher) find(trimmed string, inComment bool) int {
+	if m == nil || strings.Contains(trimmed, ignoreMarker) {
+		return -1
+	}
+	for _, loc := range m.markers.FindAllStringIndex(trimmed, -1) {
+		if inComment || commentStart.MatchString(trimmed[:loc[0]]) {
+			return loc[0]
+		}
+	}
+	return -1
+}
+
+// checkDebtMarkers flags comments carrying one of the configured debt
+// markers, such as TODO or HACK, quoting the comment from the marker on.
+// lineOffset is added to reported lines for content extracted from a
+// larger file.
+func checkDebtMarkers(cfg config.AnalysisConfig, language string, content string, lineOffset int) []Issue {
+	issues := make([]Issue, 0)
+	matcher := debtMatcherFor(cfg.DebtMarkers)
+	if matcher == nil {
+		return issues
+	}
+
+	severity := Severity(cfg.DebtMarkerSeverity)
+	if severity == "" {
+		severity = SeverityInfo
+	}
+
+	syntax := commentSyntaxFor(context.Language(language))
+	state := &commentState{}
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		inComment := state.closeComment != ""
+		if _, hasComment := syntax.classify(trimmed, state); !hasComment {
+			continue
+		}
+		start := matcher.find(trimmed, inComment)
+		if start < 0 {
+			continue
+		}
+
+		text := strings.TrimSpace(trimmed[start:])
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(text, "*/"), "-->"))
+		if len(text) > maxDebtMessageLength {
+			text = text[:maxDebtMessageLength] + "..."
+		}
+		issues = append(issues, Issue{
+			Type:       IssueTypeTechDebt,
+			Severity:   severity,
+			Line:       i + 1 + lineOffset,
+			Message:    text,
+			Suggestion: "Resolve it, or track it in an issue and link it from the comment",
+		})
+	}
+
+	return issues
+}
+
+// checkEmptyCatchBlocks flags empty catch blocks in brace languages and
+// except clauses whose body is only `pass` or `...` in Python. lineOffset is
+// added to reported lines for content extracted from a larger file.
+func checkEmptyCatchBlocks(language string, content string, lineOffset int) []Issue {
+	issues := make([]Issue, 0)
+
+	switch context.Language(language) {
+	case context.LanguagePython:
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			if !exceptClause.MatchString(line) {
+				continue
+			}
+			body, bodyLine := nextCodeLine(lines, i+1)
+			if body != "pass" && body != "..." {
+				continue
+			}
+			if strings.Contains(line, ignoreMarker) || strings.Contains(lines[bodyLine], ignoreMarker) {
+				continue
+			}
+			issues = append(issues, emptyCatchIssue(i+1+lineOffset, "except"))
+		}
+
+	case context.LanguageJavaScript, context.LanguageTypeScript, context.LanguageJava,
+		context.LanguageKotlin, context.LanguageSwift, context.LanguagePHP,
+		context.LanguageCPP, context.LanguageCSharp, context.LanguageDart, context.LanguageObjectiveC:
+		for _, match := range emptyCatchBlock.FindAllStringSubmatchIndex(content, -1) {
+			if strings.Contains(content[match[0]:match[1]], ignoreMarker) {
+				continue
+			}
+			line := strings.Count(content[:match[0]], "\n") + 1
+			issues = append(issues, emptyCatchIssue(line+lineOffset, "catch"))
+		}
+	}
+
+	return issues
+}
+
+// nextCodeLine returns the first non-blank, non-comment line at or after
+// start, trimmed, along with its index
+func nextCodeLine(lines []string, start int) (string, int) {
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		// Drop a trailing comment, e.g. `pass  # katich:ignore`
+		if idx := strings.Index(trimmed, "#"); idx > 0 {
+			trimmed = strings.TrimSpace(trimmed[:idx])
+		}
+		return trimmed, i
+	}
+	return "", len(lines) - 1
+}
+
+// emptyCatchIssue builds an issue for a swallowed exception
+func emptyCatchIssue(line int, keyword string) Issue {
+	return Issue{
+		Type:       IssueTypeEmptyErrorHandling,
+		Severity:   SeverityWarning,
+		Line:       line,
+		Message:    "Empty " + keyword + " block swallows the exception",
+		Suggestion: "Handle or log the exception, or add a katich:ignore comment explaining why it is safe to ignore",
+	}
+}
+
+// checkIndentation flags files that mix tab and space indentation and, for
+// languages where indentation is conventionally a fixed width, lines that
+// break the file's dominant width. Go is skipped since gofmt owns its
+// indentation. Mixed indentation is a warning in Python, where it changes
+// meaning, and a style note elsewhere.
+func checkIndentation(language string, content string, lineOffset int) []Issue {
+	issues := make([]Issue, 0)
+
+	lang := context.Language(language)
+	if lang == context.LanguageGo || lang == context.LanguageUnknown {
+		return issues
+	}
+
+	severity := SeverityInfo
+	if lang == context.LanguagePython {
+		severity = SeverityWarning
+	}
+
+	lines := strings.Split(content, "\n")
+	tabLines, spaceLines := 0, 0
+	firstTab, firstSpace, firstMixed := 0, 0, 0
+	widths := make(map[int]int)
+
+	for i, line := range lines {
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if indent == "" || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		switch {
+		case strings.Contains(strings.TrimLeft(indent, "\t"), "\t"):
+			// A tab after spaces is never intentional alignment
+			if firstMixed == 0 {
+				firstMixed = i + 1
+			}
+		case indent[0] == '\t':
+			tabLines++
+			if firstTab == 0 {
+				firstTab = i + 1
+			}
+		default:
+			spaceLines++
+			if firstSpace == 0 {
+				firstSpace = i + 1
+			}
+			if !isCommentContinuation(line) {
+				widths[len(indent)]++
+			}
+		}
+	}
+
+	if firstMixed > 0 {
+		issues = append(issues, indentationIssue(severity, firstMixed+lineOffset,
+			"Line indents with a tab after spaces"))
+	}
+
+	if tabLines > 0 && spaceLines > 0 {
+		dominant, line := "tabs", firstSpace
+		if spaceLines > tabLines {
+			dominant, line = "spaces", firstTab
+		}
+		issues = append(issues, indentationIssue(severity, line+lineOffset,
+			"File mixes tab and space indentation (mostly "+dominant+")"))
+		return issues
+	}
+
+	if spaceLines > 0 && usesFixedIndentWidth(lang) {
+		width := dominantIndentWidth(widths)
+		if width == 0 {
+			return issues
+		}
+		for i, line := range lines {
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			if indent == 0 || strings.TrimSpace(line) == "" || isCommentContinuation(line) {
+				continue
+			}
+			if indent%width != 0 {
+				issues = append(issues, indentationIssue(severity, i+1+lineOffset,
+					fmt.Sprintf("Indentation is not a multiple of the file's %d-space indent", width)))
+				break
+			}
+		}
+	}
+
+	return issues
+}
+
+// usesFixedIndentWidth reports whether a language conventionally indents in
+// fixed steps, so off-width lines are likely mistakes rather than alignment
+func usesFixedIndentWidth(lang context.Language) bool {
+	switch lang {
+	case context.LanguagePython, context.LanguageJavaScript, context.LanguageTypeScript:
+		return true
+	}
+	return false
+}
+
+// dominantIndentWidth infers the indent step as the smallest indent used
+// repeatedly, provided most indented lines are multiples of it. Returns 0
+// when no step is evident.
+func dominantIndentWidth(widths map[int]int) int {
+	total := 0
+	for _, count := range widths {
+		total += count
+	}
+
+	minCount := total / 10
+	if minCount < 2 {
+		minCount = 2
+	}
+
+	step := 0
+	for width, count := range widths {
+		if count >= minCount && (step == 0 || width < step) {
+			step = width
+		}
+	}
+	if step == 0 {
+		return 0
+	}
+
+	matching := 0
+	for width, count := range widths {
+		if width%step == 0 {
+			matching += count
+		}
+	}
+	if matching*4 < total*3 {
+		return 0
+	}
+	return step
+}
+
+// isCommentContinuation reports whether a line continues a block comment,
+// e.g. " * text", which is offset by one space by convention
+func isCommentContinuation(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "*")
+}
+
+// indentationIssue builds an issue for inconsistent indentation
+func indentationIssue(severity Severity, line int, message string) Issue {
+	return Issue{
+		Type:       IssueTypeStyleViolation,
+		Severity:   severity,
+		Line:       line,
+		Message:    message,
+		Suggestion: "Reindent the file consistently, e.g. with your formatter or editor's convert-indentation command",
+	}
+}
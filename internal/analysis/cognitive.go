@@ -0,0 +1,186 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// cognitiveCounter accumulates the cognitive complexity of one function
+type cognitiveCounter struct {
+	name     string            // function name, to spot recursion
+	receiver string            // receiver variable of a method, if any
+	chained  map[ast.Expr]bool // logical operators already scored as part of a sequence
+	score    int
+}
+
+// cognitiveComplexity scores how hard a Go function is to follow, after
+// the SonarSource cognitive complexity rules: each if, else, loop, switch,
+// select, labeled jump and recursive call adds one; conditionals and loops
+// add one more per level they are nested in; and each run of like logical
+// operators in a condition adds one, so "a && b && c" costs 1 and
+// "a && b || c" costs 2. Unlike cyclomatic complexity a flat switch costs
+// the same however many cases it has.
+func cognitiveComplexity(funcDecl *ast.FuncDecl) int {
+	if funcDecl.Body == nil {
+		return 0
+	}
+
+	c := &cognitiveCounter{name: funcDecl.Name.Name, chained: make(map[ast.Expr]bool)}
+	if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 && len(funcDecl.Recv.List[0].Names) > 0 {
+		c.receiver = funcDecl.Recv.List[0].Names[0].Name
+	}
+	c.walk(funcDecl.Body, 0)
+	return c.score
+}
+
+// walk scores a subtree at the given nesting level
+func (c *cognitiveCounter) walk(node ast.Node, nesting int) {
+	if node == nil {
+		return
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.IfStmt:
+			c.score += 1 + nesting
+			c.walkIf(s, nesting)
+			return false
+
+		case *ast.ForStmt:
+			c.score += 1 + nesting
+			c.walkStmt(s.Init, nesting)
+			c.walkExpr(s.Cond, nesting)
+			c.walkStmt(s.Post, nesting)
+			c.walk(s.Body, nesting+1)
+			return false
+
+		case *ast.RangeStmt:
+			c.score += 1 + nesting
+			c.walkExpr(s.X, nesting)
+			c.walk(s.Body, nesting+1)
+			return false
+
+		case *ast.SwitchStmt:
+			c.score += 1 + nesting
+			c.walkStmt(s.Init, nesting)
+			c.walkExpr(s.Tag, nesting)
+			c.walk(s.Body, nesting+1)
+			return false
+
+		case *ast.TypeSwitchStmt:
+			c.score += 1 + nesting
+			c.walkStmt(s.Init, nesting)
+			c.walk(s.Body, nesting+1)
+			return false
+
+		case *ast.SelectStmt:
+			c.score += 1 + nesting
+			c.walk(s.Body, nesting+1)
+			return false
+
+		case *ast.FuncLit:
+			// Closures add nesting but no increment of their own
+			c.walk(s.Body, nesting+1)
+			return false
+
+		case *ast.BranchStmt:
+			if s.Label != nil || s.Tok == token.GOTO {
+				c.score++
+			}
+
+		case *ast.BinaryExpr:
+			if isLogical(s) && !c.chained[s] {
+				c.score += c.logicalSequences(s)
+			}
+
+		case *ast.CallExpr:
+			if c.isRecursive(s) {
+				c.score++
+			}
+		}
+		return true
+	})
+}
+
+// walkIf scores the branches of an if statement. An else or else if adds
+// one regardless of nesting, and its body nests like the if's own.
+func (c *cognitiveCounter) walkIf(s *ast.IfStmt, nesting int) {
+	c.walkStmt(s.Init, nesting)
+	c.walkExpr(s.Cond, nesting)
+	c.walk(s.Body, nesting+1)
+
+	switch e := s.Else.(type) {
+	case *ast.IfStmt:
+		c.score++
+		c.walkIf(e, nesting)
+	case *ast.BlockStmt:
+		c.score++
+		c.walk(e, nesting+1)
+	}
+}
+
+// walkStmt scores an optional statement
+func (c *cognitiveCounter) walkStmt(s ast.Stmt, nesting int) {
+	if s != nil {
+		c.walk(s, nesting)
+	}
+}
+
+// walkExpr scores an optional expression
+func (c *cognitiveCounter) walkExpr(e ast.Expr, nesting int) {
+	if e != nil {
+		c.walk(e, nesting)
+	}
+}
+
+// logicalSequences counts the runs of like operators in a chain of && and
+// || and marks the chain's inner operators as scored
+func (c *cognitiveCounter) logicalSequences(expr *ast.BinaryExpr) int {
+	ops := c.flattenLogical(expr, nil)
+	sequences := 0
+	for i, op := range ops {
+		if i == 0 || op != ops[i-1] {
+			sequences++
+		}
+	}
+	return sequences
+}
+
+// flattenLogical lists the logical operators of a chain in source order,
+// looking through parentheses
+func (c *cognitiveCounter) flattenLogical(expr ast.Expr, ops []token.Token) []token.Token {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = paren.X
+	}
+
+	binary, ok := expr.(*ast.BinaryExpr)
+	if !ok || !isLogical(binary) {
+		return ops
+	}
+	c.chained[binary] = true
+	ops = c.flattenLogical(binary.X, ops)
+	ops = append(ops, binary.Op)
+	return c.flattenLogical(binary.Y, ops)
+}
+
+// isRecursive reports whether a call invokes the function being scored,
+// either directly or as a method on the same receiver
+func (c *cognitiveCounter) isRecursive(call *ast.CallExpr) bool {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return c.receiver == "" && fn.Name == c.name
+	case *ast.SelectorExpr:
+		recv, ok := fn.X.(*ast.Ident)
+		return ok && c.receiver != "" && recv.Name == c.receiver && fn.Sel.Name == c.name
+	}
+	return false
+}
+
+// isLogical reports whether a binary expression is && or ||
+func isLogical(expr *ast.BinaryExpr) bool {
+	return expr.Op == token.LAND || expr.Op == token.LOR
+}
@@ -2,15 +2,39 @@ package analysis
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"unicode"
+
+	"github.com/katichai/katich/internal/context"
 )
 
 // DuplicationDetector detects code duplication
-type DuplicationDetector struct{}
+type DuplicationDetector struct {
+	minLines int                // smallest duplicated block reported by DetectDuplicates
+	files    *context.FileCache // nil reads from disk
+}
+
+// defaultMinDuplicateLines is the block size used when none is configured
+const defaultMinDuplicateLines = 6
+
+// maxDuplicateOccurrences skips windows repeated more often than this;
+// they are boilerplate such as error checks rather than copied logic
+const maxDuplicateOccurrences = 20
+
+// NewDuplicationDetector creates a new duplication detector that reports
+// blocks of at least minLines significant lines; 0 uses the default
+func NewDuplicationDetector(minLines int) *DuplicationDetector {
+	if minLines <= 0 {
+		minLines = defaultMinDuplicateLines
+	}
+	return &DuplicationDetector{minLines: minLines}
+}
 
-// NewDuplicationDetector creates a new duplication detector
-func NewDuplicationDetector() *DuplicationDetector {
-	return &DuplicationDetector{}
+// SetFileCache makes DetectDuplicates read sources through a cache shared
+// with the analysis
+func (d *DuplicationDetector) SetFileCache(files *context.FileCache) {
+	d.files = files
 }
 
 // DuplicateBlock represents a duplicated code block
@@ -25,16 +49,223 @@ type DuplicateBlock struct {
 	Similarity float64 `json:"similarity"`
 }
 
-// DetectDuplicates detects duplicate code blocks
+// DetectDuplicates finds blocks of at least minLines lines that appear in
+// two functions. Lines are compared after normalizing identifiers, literals
+// and whitespace, so a copy with renamed variables still matches; its
+// similarity is the share of lines that are also identical as written.
+// Blank lines, comments and lines of only brackets are ignored. Sources
+// are read from each analysis' FilePath.
 func (d *DuplicationDetector) DetectDuplicates(files map[string]*FileAnalysis) []DuplicateBlock {
 	duplicates := make([]DuplicateBlock, 0)
 
-	// Simple hash-based duplicate detection
-	// For now, just return empty - full implementation would use more sophisticated algorithms
-	
+	bodies := make([]*codeBody, 0)
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		bodies = append(bodies, functionBodies(path, files[path], d.files)...)
+	}
+
+	// Index every window of minLines normalized lines by its rolling hash
+	windows := make(map[uint64][]codeWindow)
+	for b, body := range bodies {
+		for start, hash := range body.windowHashes(d.minLines) {
+			windows[hash] = append(windows[hash], codeWindow{body: b, start: start})
+		}
+	}
+
+	hashes := make([]uint64, 0, len(windows))
+	for hash, occurrences := range windows {
+		if len(occurrences) > 1 && len(occurrences) <= maxDuplicateOccurrences {
+			hashes = append(hashes, hash)
+		}
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+
+	seen := make(map[[4]int]bool)
+	for _, hash := range hashes {
+		occurrences := windows[hash]
+		for i := 0; i < len(occurrences); i++ {
+			for j := i + 1; j < len(occurrences); j++ {
+				a, b := occurrences[i], occurrences[j]
+				if a.body == b.body {
+					continue
+				}
+				bodyA, bodyB := bodies[a.body], bodies[b.body]
+
+				// Only report a block from its first line; windows inside
+				// an already found block are skipped
+				if !bodyA.linesEqual(bodyB, a.start, b.start, d.minLines) {
+					continue
+				}
+				if a.start > 0 && b.start > 0 && bodyA.lines[a.start-1].normalized == bodyB.lines[b.start-1].normalized {
+					continue
+				}
+				if overlaps(bodyA, bodyB, a.start, b.start, d.minLines) {
+					// Nested functions share their lines with the outer one
+					continue
+				}
+				key := [4]int{a.body, a.start, b.body, b.start}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				length := d.minLines
+				for a.start+length < len(bodyA.lines) && b.start+length < len(bodyB.lines) &&
+					bodyA.lines[a.start+length].normalized == bodyB.lines[b.start+length].normalized {
+					length++
+				}
+				duplicates = append(duplicates, newDuplicateBlock(bodyA, bodyB, a.start, b.start, length))
+			}
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Lines != duplicates[j].Lines {
+			return duplicates[i].Lines > duplicates[j].Lines
+		}
+		if duplicates[i].File1 != duplicates[j].File1 {
+			return duplicates[i].File1 < duplicates[j].File1
+		}
+		return duplicates[i].StartLine1 < duplicates[j].StartLine1
+	})
+
+	return dropOverlappingBlocks(duplicates)
+}
+
+// minDuplicateTypeFields is the smallest struct considered for duplicate
+// type detection; tiny structs match by coincidence too often
+const minDuplicateTypeFields = 3
+
+// DuplicateType is a struct shape declared in more than one file
+type DuplicateType struct {
+	Fields    int            `json:"fields"`
+	Locations []TypeLocation `json:"locations"`
+}
+
+// TypeLocation identifies a type declaration
+type TypeLocation struct {
+	File string `json:"file"`
+	Name string `json:"name"`
+	Line int    `json:"line"`
+}
+
+// DetectDuplicateTypes finds structs with identical field names and types
+// declared in different files, regardless of field order or type name
+func (d *DuplicationDetector) DetectDuplicateTypes(files map[string]*FileAnalysis) []DuplicateType {
+	shapes := make(map[string]*DuplicateType)
+
+	for path, fileAnalysis := range files {
+		for _, class := range fileAnalysis.Classes {
+			if len(class.Fields) < minDuplicateTypeFields {
+				continue
+			}
+
+			fields := make([]string, 0, len(class.Fields))
+			for _, field := range class.Fields {
+				fields = append(fields, field.Name+" "+field.Type)
+			}
+			sort.Strings(fields)
+			key := strings.Join(fields, ";")
+
+			shape, ok := shapes[key]
+			if !ok {
+				shape = &DuplicateType{Fields: len(fields)}
+				shapes[key] = shape
+			}
+			shape.Locations = append(shape.Locations, TypeLocation{File: path, Name: class.Name, Line: class.StartLine})
+		}
+	}
+
+	duplicates := make([]DuplicateType, 0)
+	for _, shape := range shapes {
+		if !spansFiles(shape.Locations) {
+			continue
+		}
+		sort.Slice(shape.Locations, func(i, j int) bool {
+			if shape.Locations[i].File != shape.Locations[j].File {
+				return shape.Locations[i].File < shape.Locations[j].File
+			}
+			return shape.Locations[i].Line < shape.Locations[j].Line
+		})
+		duplicates = append(duplicates, *shape)
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Fields != duplicates[j].Fields {
+			return duplicates[i].Fields > duplicates[j].Fields
+		}
+		return duplicates[i].Locations[0].File < duplicates[j].Locations[0].File
+	})
+
+	return duplicates
+}
+
+// minDuplicateLiteralElements is the smallest literal considered for
+// duplicate data detection
+const minDuplicateLiteralElements = 4
+
+// DuplicateLiteral is a constant slice, array or map literal repeated in
+// more than one file
+type DuplicateLiteral struct {
+	Type      string         `json:"type"`
+	Elements  int            `json:"elements"`
+	Locations []TypeLocation `json:"locations"`
+}
+
+// DetectDuplicateLiterals finds identical data literals, such as copied
+// lookup tables, in different files
+func (d *DuplicationDetector) DetectDuplicateLiterals(files map[string]*FileAnalysis) []DuplicateLiteral {
+	groups := make(map[string]*DuplicateLiteral)
+
+	for path, fileAnalysis := range files {
+		for _, lit := range fileAnalysis.Literals {
+			group, ok := groups[lit.Hash]
+			if !ok {
+				group = &DuplicateLiteral{Type: lit.Type, Elements: lit.Elements}
+				groups[lit.Hash] = group
+			}
+			group.Locations = append(group.Locations, TypeLocation{File: path, Name: lit.Name, Line: lit.Line})
+		}
+	}
+
+	duplicates := make([]DuplicateLiteral, 0)
+	for _, group := range groups {
+		if !spansFiles(group.Locations) {
+			continue
+		}
+		sort.Slice(group.Locations, func(i, j int) bool {
+			if group.Locations[i].File != group.Locations[j].File {
+				return group.Locations[i].File < group.Locations[j].File
+			}
+			return group.Locations[i].Line < group.Locations[j].Line
+		})
+		duplicates = append(duplicates, *group)
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Elements != duplicates[j].Elements {
+			return duplicates[i].Elements > duplicates[j].Elements
+		}
+		return duplicates[i].Locations[0].File < duplicates[j].Locations[0].File
+	})
+
 	return duplicates
 }
 
+// spansFiles reports whether type locations come from more than one file
+func spansFiles(locations []TypeLocation) bool {
+	for _, location := range locations[1:] {
+		if location.File != locations[0].File {
+			return true
+		}
+	}
+	return false
+}
+
 // AICodeDetector detects AI-generated code patterns
 type AICodeDetector struct{}
 
@@ -143,9 +374,143 @@ func (s *StyleChecker) CheckStyle(analysis *FileAnalysis) []Issue {
 		}
 	}
 
+	if analysis.Language == "Go" {
+		issues = append(issues, s.checkGoNaming(analysis)...)
+	}
+
 	return issues
 }
 
+// goInitialisms are the initialisms Go code conventionally writes in a
+// single case, e.g. URL or url but not Url
+var goInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "JWT": true,
+	"RPC": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true,
+	"UDP": true, "UI": true, "URI": true, "URL": true, "UUID": true,
+	"XML": true,
+}
+
+// checkGoNaming flags Go names that break the language's conventions:
+// mixed-case initialisms (Url instead of URL), underscores in unexported
+// names and exported names that repeat the package name (http.HTTPClient)
+func (s *StyleChecker) checkGoNaming(analysis *FileAnalysis) []Issue {
+	issues := make([]Issue, 0)
+
+	check := func(kind, name string, line int, exported, packageLevel bool) {
+		if fixed := fixInitialisms(name); fixed != name {
+			issues = append(issues, Issue{
+				Type:       IssueTypeNaming,
+				Severity:   SeverityInfo,
+				Line:       line,
+				Message:    fmt.Sprintf("%s '%s' mixes the case of an initialism", kind, name),
+				Suggestion: fmt.Sprintf("Rename to '%s'; Go writes initialisms in a single case", fixed),
+			})
+		}
+
+		if !exported && strings.Trim(name, "_") != "" && strings.Contains(strings.TrimLeft(name, "_"), "_") {
+			issues = append(issues, Issue{
+				Type:       IssueTypeNaming,
+				Severity:   SeverityInfo,
+				Line:       line,
+				Message:    fmt.Sprintf("%s '%s' uses underscores", kind, name),
+				Suggestion: fmt.Sprintf("Rename to '%s'; Go uses mixedCaps rather than underscores", toMixedCaps(name)),
+			})
+		}
+
+		if exported && packageLevel {
+			if trimmed, ok := trimPackageStutter(analysis.Package, name); ok {
+				issues = append(issues, Issue{
+					Type:       IssueTypeNaming,
+					Severity:   SeverityInfo,
+					Line:       line,
+					Message:    fmt.Sprintf("%s '%s' repeats the package name: callers write %s.%s", kind, name, analysis.Package, name),
+					Suggestion: fmt.Sprintf("Rename to '%s' so it reads as %s.%s", trimmed, analysis.Package, trimmed),
+				})
+			}
+		}
+	}
+
+	for _, fn := range analysis.Functions {
+		kind := "Function"
+		if fn.Receiver != "" {
+			kind = "Method"
+		}
+		check(kind, fn.Name, fn.StartLine, fn.IsExported, fn.Receiver == "")
+	}
+	for _, class := range analysis.Classes {
+		check("Type", class.Name, class.StartLine, class.IsExported, true)
+	}
+
+	return issues
+}
+
+// splitCamelCase splits an identifier into its words, keeping runs of
+// capitals together: HTTPServerUrl -> HTTP, Server, Url
+func splitCamelCase(name string) []string {
+	runes := []rune(name)
+	words := make([]string, 0)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := unicode.IsUpper(cur) && !unicode.IsUpper(prev) ||
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) ||
+			cur == '_' || prev == '_'
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// fixInitialisms rewrites initialisms written in mixed case, e.g. userId ->
+// userID. All-lowercase initialisms, as in urlPath, are left alone.
+func fixInitialisms(name string) string {
+	words := splitCamelCase(name)
+	for i, word := range words {
+		upper := strings.ToUpper(word)
+		if goInitialisms[upper] && word != upper && word != strings.ToLower(word) {
+			words[i] = upper
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// toMixedCaps joins the underscore-separated parts of a name in mixedCaps,
+// e.g. parse_http_url -> parseHTTPURL
+func toMixedCaps(name string) string {
+	parts := strings.Split(strings.Trim(name, "_"), "_")
+	var b strings.Builder
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		if i > 0 {
+			if goInitialisms[strings.ToUpper(part)] {
+				part = strings.ToUpper(part)
+			} else {
+				part = strings.ToUpper(part[:1]) + part[1:]
+			}
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// trimPackageStutter returns name without a leading package name, if it
+// starts with one followed by another word
+func trimPackageStutter(pkg, name string) (string, bool) {
+	if pkg == "" || pkg == "main" || len(name) <= len(pkg) {
+		return "", false
+	}
+	if !strings.EqualFold(name[:len(pkg)], pkg) || !unicode.IsUpper(rune(name[len(pkg)])) {
+		return "", false
+	}
+	return name[len(pkg):], true
+}
+
 // hasInvalidNaming checks for invalid naming
 func (s *StyleChecker) hasInvalidNaming(name string) bool {
 	// Very basic check - can be enhanced
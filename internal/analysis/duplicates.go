@@ -0,0 +1,252 @@
+package analysis
+
+import (
+	"go/token"
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"github.com/katichai/katich/internal/context"
+)
+
+// duplicateKeywords are kept as written when lines are normalized, so that
+// e.g. "return x" and "x = y" do not collapse to the same tokens. Go's
+// keywords are added from go/token.
+var duplicateKeywords = map[string]bool{
+	"and": true, "as": true, "async": true, "await": true, "catch": true, "class": true,
+	"def": true, "del": true, "do": true, "elif": true, "end": true, "except": true,
+	"false": true, "False": true, "finally": true, "in": true, "is": true, "let": true,
+	"new": true, "nil": true, "None": true, "not": true, "null": true, "or": true,
+	"pass": true, "raise": true, "self": true, "this": true, "throw": true, "true": true,
+	"True": true, "try": true, "undefined": true, "while": true, "with": true, "yield": true,
+}
+
+// codeLine is one significant source line of a function body
+type codeLine struct {
+	number     int
+	raw        string // trimmed source, for similarity
+	normalized string // identifiers and literals replaced by placeholders
+}
+
+// codeBody holds the significant lines of one function
+type codeBody struct {
+	path  string
+	lines []codeLine
+}
+
+// codeWindow is the start of a run of lines within a body
+type codeWindow struct {
+	body  int
+	start int
+}
+
+// functionBodies reads a file through files and returns the normalized
+// lines of each of its functions. Files that cannot be read or have no
+// functions yield none.
+func functionBodies(path string, fileAnalysis *FileAnalysis, files *context.FileCache) []*codeBody {
+	if fileAnalysis == nil || len(fileAnalysis.Functions) == 0 {
+		return nil
+	}
+	content, err := files.ReadFile(fileAnalysis.FilePath)
+	if err != nil {
+		return nil
+	}
+
+	syntax := commentSyntaxFor(context.Language(fileAnalysis.Language))
+	lines := make([]*codeLine, 0)
+	state := commentState{}
+	for i, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		inString := state.closeString != ""
+		hasCode, _ := syntax.classify(trimmed, &state)
+		if !hasCode || inString {
+			// Continuations of multi-line strings are data, not code
+			lines = append(lines, nil)
+			continue
+		}
+		normalized, significant := normalizeCodeLine(trimmed, syntax)
+		if !significant {
+			lines = append(lines, nil)
+			continue
+		}
+		lines = append(lines, &codeLine{number: i + 1, raw: trimmed, normalized: normalized})
+	}
+
+	bodies := make([]*codeBody, 0, len(fileAnalysis.Functions))
+	for _, fn := range fileAnalysis.Functions {
+		body := &codeBody{path: path}
+		for n := fn.StartLine; n <= fn.EndLine && n <= len(lines); n++ {
+			if n >= 1 && lines[n-1] != nil {
+				body.lines = append(body.lines, *lines[n-1])
+			}
+		}
+		if len(body.lines) > 0 {
+			bodies = append(bodies, body)
+		}
+	}
+	return bodies
+}
+
+// normalizeCodeLine reduces a line to its token shape: identifiers become
+// "ID", numbers "N" and string literals "S", keywords and punctuation are
+// kept and whitespace and trailing comments dropped. A line of punctuation
+// alone, such as a closing brace, is not significant.
+func normalizeCodeLine(line string, syntax commentSyntax) (string, bool) {
+	tokens := make([]string, 0, 8)
+	significant := false
+
+	i := 0
+	for i < len(line) {
+		c := rune(line[i])
+		switch {
+		case isWhitespace(c):
+			i++
+
+		case syntax.startsLineComment(line[i:]),
+			syntax.blockOpen != "" && strings.HasPrefix(line[i:], syntax.blockOpen):
+			i = len(line)
+
+		case c == '"' || c == '\'' || (syntax.rawQuote != "" && strings.HasPrefix(line[i:], syntax.rawQuote)):
+			tokens = append(tokens, "S")
+			significant = true
+			if c == '"' || c == '\'' {
+				i = skipQuoted(line, i)
+			} else if end := strings.Index(line[i+1:], syntax.rawQuote); end >= 0 {
+				i += end + 2
+			} else {
+				i = len(line)
+			}
+
+		case unicode.IsDigit(c):
+			for i < len(line) && (isIdentRune(rune(line[i])) || line[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, "N")
+			significant = true
+
+		case isIdentRune(c) || c >= 0x80:
+			start := i
+			for i < len(line) && (isIdentRune(rune(line[i])) || line[i] >= 0x80) {
+				i++
+			}
+			word := line[start:i]
+			if duplicateKeywords[word] || token.Lookup(word).IsKeyword() {
+				tokens = append(tokens, word)
+			} else {
+				tokens = append(tokens, "ID")
+			}
+			significant = true
+
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+
+	return strings.Join(tokens, " "), significant
+}
+
+// isIdentRune reports whether an ASCII character may appear in an identifier
+func isIdentRune(c rune) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// windowHashes returns the hash of every run of size consecutive lines,
+// indexed by the run's first line
+func (b *codeBody) windowHashes(size int) []uint64 {
+	if len(b.lines) < size {
+		return nil
+	}
+
+	lineHashes := make([]uint64, len(b.lines))
+	for i, line := range b.lines {
+		h := fnv.New64a()
+		h.Write([]byte(line.normalized))
+		lineHashes[i] = h.Sum64()
+	}
+
+	// Polynomial rolling hash over the line hashes
+	const base = 1000003
+	var power uint64 = 1
+	for i := 1; i < size; i++ {
+		power *= base
+	}
+
+	hashes := make([]uint64, 0, len(b.lines)-size+1)
+	var hash uint64
+	for i, lineHash := range lineHashes {
+		if i >= size {
+			hash -= lineHashes[i-size] * power
+		}
+		hash = hash*base + lineHash
+		if i >= size-1 {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
+
+// linesEqual confirms that two runs of n lines match, ruling out hash
+// collisions
+func (b *codeBody) linesEqual(other *codeBody, start, otherStart, n int) bool {
+	for i := 0; i < n; i++ {
+		if b.lines[start+i].normalized != other.lines[otherStart+i].normalized {
+			return false
+		}
+	}
+	return true
+}
+
+// overlaps reports whether two runs of n lines cover the same source lines
+func overlaps(a, b *codeBody, startA, startB, n int) bool {
+	if a.path != b.path {
+		return false
+	}
+	return a.lines[startA].number <= b.lines[startB+n-1].number &&
+		b.lines[startB].number <= a.lines[startA+n-1].number
+}
+
+// dropOverlappingBlocks keeps the longest of the blocks that pair the same
+// code. Runs of repetitive lines, such as a list of flag registrations,
+// otherwise match each other at every offset. blocks must be sorted
+// longest first.
+func dropOverlappingBlocks(blocks []DuplicateBlock) []DuplicateBlock {
+	kept := make([]DuplicateBlock, 0, len(blocks))
+	for _, block := range blocks {
+		overlapping := false
+		for _, other := range kept {
+			if block.File1 == other.File1 && block.File2 == other.File2 &&
+				block.StartLine1 <= other.EndLine1 && other.StartLine1 <= block.EndLine1 &&
+				block.StartLine2 <= other.EndLine2 && other.StartLine2 <= block.EndLine2 {
+				overlapping = true
+				break
+			}
+		}
+		if !overlapping {
+			kept = append(kept, block)
+		}
+	}
+	return kept
+}
+
+// newDuplicateBlock describes a matched run of length lines, with the share
+// of lines that are identical before normalization as its similarity
+func newDuplicateBlock(a, b *codeBody, startA, startB, length int) DuplicateBlock {
+	identical := 0
+	for i := 0; i < length; i++ {
+		if a.lines[startA+i].raw == b.lines[startB+i].raw {
+			identical++
+		}
+	}
+
+	return DuplicateBlock{
+		File1:      a.path,
+		StartLine1: a.lines[startA].number,
+		EndLine1:   a.lines[startA+length-1].number,
+		File2:      b.path,
+		StartLine2: b.lines[startB].number,
+		EndLine2:   b.lines[startB+length-1].number,
+		Lines:      length,
+		Similarity: float64(identical) / float64(length),
+	}
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"go/scanner"
+	"go/token"
+	"math"
+)
+
+// goHalsteadVolume returns the Halstead volume of Go source, N·log2(n),
+// where N counts every operator and operand token and n the distinct ones.
+// Keywords, operators and delimiters are operators; identifiers and
+// literals are operands.
+func goHalsteadVolume(content []byte) float64 {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(content))
+
+	var s scanner.Scanner
+	s.Init(file, content, nil, 0)
+
+	operators := make(map[string]bool)
+	operands := make(map[string]bool)
+	total := 0
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		switch {
+		case tok == token.SEMICOLON && lit == "\n":
+			// Inserted at line ends, not written
+			continue
+		case tok == token.IDENT || tok.IsLiteral():
+			operands[lit] = true
+		case tok.IsOperator() || tok.IsKeyword():
+			operators[tok.String()] = true
+		default:
+			continue
+		}
+		total++
+	}
+
+	distinct := len(operators) + len(operands)
+	if distinct < 2 {
+		return 0
+	}
+	return float64(total) * math.Log2(float64(distinct))
+}
+
+// goMaintainability returns the Halstead volume of a Go file's functions
+// and the file's maintainability index, which as in the original SEI
+// definition takes the average volume, complexity and length of the
+// functions. volumes holds the volume of each function. A file without
+// functions is measured as a whole.
+func goMaintainability(content []byte, functions []FunctionInfo, volumes []float64, linesOfCode int) (volume, index float64) {
+	if len(functions) == 0 || len(volumes) != len(functions) {
+		volume = goHalsteadVolume(content)
+		return volume, maintainabilityIndex(volume, 0, float64(linesOfCode))
+	}
+
+	complexity, lines := 0, 0
+	for i, fn := range functions {
+		volume += volumes[i]
+		complexity += fn.Complexity
+		lines += fn.LOC
+	}
+	count := float64(len(functions))
+	return volume, maintainabilityIndex(volume/count, float64(complexity)/count, float64(lines)/count)
+}
+
+// maintainabilityIndex combines Halstead volume, cyclomatic complexity and
+// lines of code into the maintainability index, rescaled to 0-100 as in
+// Visual Studio: 100 is trivially maintainable, below 20 is hard to
+// maintain. It is 0 when the volume or line count is unknown.
+func maintainabilityIndex(volume, complexity, linesOfCode float64) float64 {
+	if volume <= 0 || linesOfCode <= 0 {
+		return 0
+	}
+
+	index := 171 - 5.2*math.Log(volume) - 0.23*complexity - 16.2*math.Log(linesOfCode)
+	return math.Max(0, math.Min(100, index*100/171))
+}
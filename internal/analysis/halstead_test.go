@@ -0,0 +1,33 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+// The expected values are worked out by hand for
+//
+//	func add(a, b int) int { return a + b }
+//
+// Operators: func ( , ) { return + }, 8 in total and 8 distinct.
+// Operands: add a b int int a b, 7 in total and 4 distinct (add, a, b, int).
+// V = N·log2(n) = 15·log2(12) ≈ 53.77.
+// The function spans 1 line with complexity 1, so
+// MI = (171 - 5.2·ln(53.77) - 0.23·1 - 16.2·ln(1))·100/171 ≈ 87.75.
+func TestGoMaintainabilityFixture(t *testing.T) {
+	const wantVolume, wantIndex = 53.77, 87.75
+	src := []byte("package p\n\nfunc add(a, b int) int { return a + b }\n")
+
+	analysis, err := NewGoParser(config.DefaultConfig().Analysis).ParseSource("p.go", src)
+	if err != nil {
+		t.Fatalf("ParseSource: %v", err)
+	}
+	if got := analysis.Metrics.HalsteadVolume; math.Abs(got-wantVolume) > 0.01 {
+		t.Errorf("Halstead volume = %.2f, want %.2f", got, wantVolume)
+	}
+	if got := analysis.Metrics.MaintainabilityIndex; math.Abs(got-wantIndex) > 0.01 {
+		t.Errorf("maintainability index = %.2f, want %.2f", got, wantIndex)
+	}
+}
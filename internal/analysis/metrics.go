@@ -1,5 +1,11 @@
 package analysis
 
+import (
+	"strings"
+
+	"github.com/katichai/katich/internal/context"
+)
+
 // CodeMetrics represents metrics for a code file or function
 type CodeMetrics struct {
 	LinesOfCode          int     `json:"lines_of_code"`
@@ -11,19 +17,27 @@ type CodeMetrics struct {
 	ImportCount          int     `json:"import_count"`
 	MaxFunctionLength    int     `json:"max_function_length"`
 	AvgFunctionLength    float64 `json:"avg_function_length"`
+	DebtMarkers          int     `json:"debt_markers"`                    // comments with an analysis.debt_markers marker
+	HalsteadVolume       float64 `json:"halstead_volume,omitempty"`       // Go only
+	MaintainabilityIndex float64 `json:"maintainability_index,omitempty"` // 0-100, higher is better; Go only
 }
 
 // FunctionInfo represents information about a function
 type FunctionInfo struct {
 	Name       string   `json:"name"`
+	Receiver   string   `json:"receiver,omitempty"` // receiver type for methods
 	StartLine  int      `json:"start_line"`
 	EndLine    int      `json:"end_line"`
 	LOC        int      `json:"loc"`
 	Complexity int      `json:"complexity"`
+	CognitiveComplexity int `json:"cognitive_complexity,omitempty"` // nesting-weighted, Go only
+	Returns    int      `json:"returns"`
 	Parameters []string `json:"parameters"`
+	ParameterTypes []string `json:"parameter_types,omitempty"` // parallel to Parameters
 	ReturnType string   `json:"return_type,omitempty"`
 	IsExported bool     `json:"is_exported"`
 	Comments   string   `json:"comments,omitempty"`
+	Decorators []string `json:"decorators,omitempty"` // Python decorators, without arguments
 }
 
 // ClassInfo represents information about a class/struct
@@ -35,6 +49,7 @@ type ClassInfo struct {
 	Fields     []FieldInfo    `json:"fields"`
 	IsExported bool           `json:"is_exported"`
 	Comments   string         `json:"comments,omitempty"`
+	Decorators []string       `json:"decorators,omitempty"`
 }
 
 // FieldInfo represents a class field/property
@@ -53,11 +68,24 @@ type ImportInfo struct {
 type FileAnalysis struct {
 	FilePath   string         `json:"file_path"`
 	Language   string         `json:"language"`
+	Package    string         `json:"package,omitempty"` // Go package name
 	Metrics    CodeMetrics    `json:"metrics"`
 	Functions  []FunctionInfo `json:"functions"`
 	Classes    []ClassInfo    `json:"classes"`
 	Imports    []ImportInfo   `json:"imports"`
 	Issues     []Issue        `json:"issues,omitempty"`
+	Sections   []string       `json:"sections,omitempty"` // single-file component blocks
+	Literals   []LiteralInfo  `json:"literals,omitempty"` // constant slice, array and map literals
+}
+
+// LiteralInfo describes a composite literal made only of constants, such
+// as a lookup table
+type LiteralInfo struct {
+	Name     string `json:"name,omitempty"` // variable the literal is assigned to
+	Type     string `json:"type"`
+	Line     int    `json:"line"`
+	Elements int    `json:"elements"`
+	Hash     string `json:"hash"` // hash of the normalized literal source
 }
 
 // Issue represents a code quality issue
@@ -80,6 +108,20 @@ const (
 	IssueTypeDuplication     IssueType = "duplication"
 	IssueTypeUnusedCode      IssueType = "unused_code"
 	IssueTypeStyleViolation  IssueType = "style_violation"
+	IssueTypeErrorWrapping   IssueType = "error_wrapping"
+	IssueTypeSimplification  IssueType = "simplification"
+	IssueTypeReturnCount     IssueType = "return_count"
+	IssueTypeEmptyErrorHandling IssueType = "empty_error_handling"
+	IssueTypeMissingTest     IssueType = "missing_test"
+	IssueTypeParameterStruct IssueType = "parameter_struct"
+	IssueTypeHardcodedHost   IssueType = "hardcoded_host"
+	IssueTypeHardcodedSecret IssueType = "hardcoded_secret"
+	IssueTypePurity          IssueType = "purity"
+	IssueTypeReturnShape     IssueType = "return_shape"
+	IssueTypeAIReview        IssueType = "ai_review"
+	IssueTypeTechDebt        IssueType = "tech_debt"
+	IssueTypeIgnoredError    IssueType = "ignored_error"
+	IssueTypePanic           IssueType = "panic"
 )
 
 // Severity indicates issue severity
@@ -91,9 +133,16 @@ const (
 	SeverityError   Severity = "error"
 )
 
-// CalculateBasicMetrics calculates basic metrics from source code
-func CalculateBasicMetrics(content string) CodeMetrics {
+// CalculateBasicMetrics calculates basic metrics from source code. Lines
+// are classified with the comment syntax of the language, so the inside of
+// a block comment or a Python docstring counts as comments while a line
+// with code next to a comment counts as code. Comments carrying one of
+// debtMarkers are counted as the tech_debt check reports them.
+func CalculateBasicMetrics(language string, content string, debtMarkers []string) CodeMetrics {
 	lines := splitLines(content)
+	syntax := commentSyntaxFor(context.Language(language))
+	state := &commentState{}
+	debt := debtMatcherFor(debtMarkers)
 	
 	metrics := CodeMetrics{}
 	
@@ -102,10 +151,18 @@ func CalculateBasicMetrics(content string) CodeMetrics {
 		
 		if trimmed == "" {
 			metrics.BlankLines++
-		} else if isComment(trimmed) {
-			metrics.LinesOfComments++
-		} else {
+			continue
+		}
+
+		inComment := state.closeComment != ""
+		hasCode, hasComment := syntax.classify(trimmed, state)
+		if hasCode {
 			metrics.LinesOfCode++
+		} else {
+			metrics.LinesOfComments++
+		}
+		if hasComment && debt.find(trimmed, inComment) >= 0 {
+			metrics.DebtMarkers++
 		}
 	}
 	
@@ -156,14 +213,125 @@ func isWhitespace(r rune) bool {
 	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
 }
 
-// isComment checks if a line is a comment (basic check)
-func isComment(line string) bool {
-	if len(line) < 2 {
-		return false
+// commentSyntax describes how a language writes comments and the string
+// literals that can span lines
+type commentSyntax struct {
+	line       []string // line comment prefixes, e.g. "//"
+	blockOpen  string   // e.g. "/*"
+	blockClose string
+	docStrings bool   // a triple-quoted string starting a line is a docstring (Python)
+	rawQuote   string // quote of string literals that may span lines, e.g. "`"
+}
+
+// commentState carries what is still open at the end of a line
+type commentState struct {
+	closeComment string // delimiter ending the open block comment or docstring
+	closeString  string // delimiter ending the open multi-line string
+}
+
+// commentSyntaxFor returns the comment syntax of a language. Unknown
+// languages accept both // and # line comments and /* */ blocks.
+func commentSyntaxFor(lang context.Language) commentSyntax {
+	switch lang {
+	case context.LanguagePython:
+		return commentSyntax{line: []string{"#"}, docStrings: true}
+	case context.LanguageRuby, context.LanguageElixir, context.LanguageShell:
+		return commentSyntax{line: []string{"#"}}
+	case context.LanguageGo, context.LanguageJavaScript, context.LanguageTypeScript:
+		return commentSyntax{line: []string{"//"}, blockOpen: "/*", blockClose: "*/", rawQuote: "`"}
+	case context.LanguagePHP:
+		return commentSyntax{line: []string{"//", "#"}, blockOpen: "/*", blockClose: "*/"}
+	case context.LanguageJava, context.LanguageKotlin, context.LanguageSwift, context.LanguageRust,
+		context.LanguageC, context.LanguageCPP, context.LanguageCSharp, context.LanguageScala,
+		context.LanguageDart, context.LanguageObjectiveC:
+		return commentSyntax{line: []string{"//"}, blockOpen: "/*", blockClose: "*/"}
 	}
-	
-	// Check for common comment patterns
-	return (line[0] == '/' && line[1] == '/') || // Go, JS, Java
-		   (line[0] == '#') ||                    // Python, Ruby
-		   (line[0] == '/' && line[1] == '*')     // Multi-line
+	return commentSyntax{line: []string{"//", "#"}, blockOpen: "/*", blockClose: "*/"}
+}
+
+// classify reports whether a trimmed line holds code and whether it holds
+// a comment, updating state for comments and strings left open
+func (s commentSyntax) classify(line string, state *commentState) (hasCode, hasComment bool) {
+	i := 0
+	for i < len(line) {
+		switch {
+		case state.closeComment != "":
+			hasComment = true
+			end := strings.Index(line[i:], state.closeComment)
+			if end < 0 {
+				return hasCode, hasComment
+			}
+			i += end + len(state.closeComment)
+			state.closeComment = ""
+
+		case state.closeString != "":
+			hasCode = true
+			end := strings.Index(line[i:], state.closeString)
+			if end < 0 {
+				return hasCode, hasComment
+			}
+			i += end + len(state.closeString)
+			state.closeString = ""
+
+		case isWhitespace(rune(line[i])):
+			i++
+
+		case s.startsLineComment(line[i:]):
+			return hasCode, true
+
+		case s.blockOpen != "" && strings.HasPrefix(line[i:], s.blockOpen):
+			state.closeComment = s.blockClose
+			i += len(s.blockOpen)
+
+		case s.docStrings && (strings.HasPrefix(line[i:], `"""`) || strings.HasPrefix(line[i:], "'''")):
+			// A triple-quoted string is a docstring when nothing precedes
+			// it, and a multi-line string value otherwise
+			if hasCode {
+				state.closeString = line[i : i+3]
+			} else {
+				state.closeComment = line[i : i+3]
+			}
+			i += 3
+
+		case s.rawQuote != "" && strings.HasPrefix(line[i:], s.rawQuote):
+			hasCode = true
+			state.closeString = s.rawQuote
+			i += len(s.rawQuote)
+
+		case line[i] == '"' || line[i] == '\'':
+			// Skip the string so comment markers inside it are ignored
+			hasCode = true
+			i = skipQuoted(line, i)
+
+		default:
+			hasCode = true
+			i++
+		}
+	}
+	return hasCode, hasComment
+}
+
+// startsLineComment reports whether text begins with a line comment
+func (s commentSyntax) startsLineComment(text string) bool {
+	for _, prefix := range s.line {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipQuoted returns the index just past the single-line string literal
+// opening at start, or the end of the line if it is not closed
+func skipQuoted(line string, start int) int {
+	quote := line[start]
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(line)
 }
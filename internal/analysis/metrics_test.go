@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+func TestCalculateBasicMetricsComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		content  string
+		code     int
+		comments int
+		blank    int
+	}{
+		{
+			name:     "Go block comments",
+			language: "Go",
+			content: `// Package p does things
+package p
+
+/* A block comment
+   spanning lines
+*/
+func f() {} /* trailing */
+/* leading */ var x = 1
+var s = "/* not a comment"
+var y = 2
+`,
+			code: 5, comments: 4, blank: 1,
+		},
+		{
+			name:     "Go raw string",
+			language: "Go",
+			content:  "var q = `\n// inside a raw string\n`\n",
+			code:     3, comments: 0, blank: 0,
+		},
+		{
+			name:     "JavaScript doc comment",
+			language: "JavaScript",
+			content: `/**
+ * Adds numbers.
+ */
+const add = (a, b) => a + b; // trailing comment
+
+// a line comment
+const url = "http://example.com";
+`,
+			code: 2, comments: 4, blank: 1,
+		},
+		{
+			name:     "Python docstrings",
+			language: "Python",
+			content: `"""Module docstring
+spanning lines.
+"""
+# a comment
+def f():
+    '''One-line docstring.'''
+    text = """a multi-line
+string value"""
+    return text  # trailing
+`,
+			code: 4, comments: 5, blank: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := CalculateBasicMetrics(tt.language, tt.content, nil)
+			if metrics.LinesOfCode != tt.code || metrics.LinesOfComments != tt.comments || metrics.BlankLines != tt.blank {
+				t.Errorf("code/comments/blank = %d/%d/%d, want %d/%d/%d",
+					metrics.LinesOfCode, metrics.LinesOfComments, metrics.BlankLines, tt.code, tt.comments, tt.blank)
+			}
+		})
+	}
+}
+
+// The debt marker count behind max_debt_growth and trend must agree with
+// the tech_debt issues for the same markers
+func TestDebtMarkerCountMatchesCheck(t *testing.T) {
+	content := `package p
+
+// HACK: retry until the API is fixed
+// TODO: not a configured marker
+var s = "HACK in a string"
+
+/*
+ * HACK inside a block comment
+ */
+func f() {} // HACK katich:ignore
+`
+	cfg := config.DefaultConfig().Analysis
+	cfg.DebtMarkers = []string{"HACK"}
+
+	metrics := CalculateBasicMetrics("Go", content, cfg.DebtMarkers)
+	issues := checkDebtMarkers(cfg, "Go", content, 0)
+	if metrics.DebtMarkers != 2 || len(issues) != 2 {
+		t.Errorf("counted %d debt markers and reported %d issues, want 2 and 2", metrics.DebtMarkers, len(issues))
+	}
+
+	if got := CalculateBasicMetrics("Go", content, nil).DebtMarkers; got != 0 {
+		t.Errorf("counted %d debt markers with none configured, want 0", got)
+	}
+}
@@ -1,19 +1,29 @@
 package analysis
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"os"
+	"sort"
+	"strings"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/context"
 )
 
 // GoParser parses Go source files
-type GoParser struct{}
+type GoParser struct {
+	config config.AnalysisConfig
+}
 
-// NewGoParser creates a new Go parser
-func NewGoParser() *GoParser {
-	return &GoParser{}
+// NewGoParser creates a new Go parser using the given analysis settings
+func NewGoParser(cfg config.AnalysisConfig) *GoParser {
+	return &GoParser{
+		config: cfg,
+	}
 }
 
 // ParseFile parses a Go source file
@@ -24,6 +34,12 @@ func (p *GoParser) ParseFile(filePath string) (*FileAnalysis, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	return p.ParseSource(filePath, content)
+}
+
+// ParseSource parses Go source that has already been read, such as the
+// content of a file at a specific git revision
+func (p *GoParser) ParseSource(filePath string, content []byte) (*FileAnalysis, error) {
 	// Parse AST
 	fset := token.NewFileSet()
 	file, err := parser.ParseFile(fset, filePath, content, parser.ParseComments)
@@ -34,6 +50,7 @@ func (p *GoParser) ParseFile(filePath string) (*FileAnalysis, error) {
 	analysis := &FileAnalysis{
 		FilePath:  filePath,
 		Language:  "Go",
+		Package:   file.Name.Name,
 		Functions: make([]FunctionInfo, 0),
 		Classes:   make([]ClassInfo, 0),
 		Imports:   make([]ImportInfo, 0),
@@ -52,31 +69,40 @@ func (p *GoParser) ParseFile(filePath string) (*FileAnalysis, error) {
 	}
 
 	// Walk AST
+	recorded := make(map[*ast.CompositeLit]bool)
+	typed := &fileTypes{fset: fset, file: file}
+	volumes := make([]float64, 0)
+	var declared map[string]*ast.FuncType
+	if p.isEnabled(IssueTypeIgnoredError) {
+		declared = declaredFuncTypes(file)
+	}
 	ast.Inspect(file, func(n ast.Node) bool {
 		switch node := n.(type) {
 		case *ast.FuncDecl:
 			funcInfo := p.extractFunction(node, fset)
 			analysis.Functions = append(analysis.Functions, funcInfo)
+			volumes = append(volumes, goHalsteadVolume(content[fset.Position(node.Pos()).Offset:fset.Position(node.End()).Offset]))
 			
 			// Check for issues
-			if funcInfo.Complexity > 10 {
-				analysis.Issues = append(analysis.Issues, Issue{
-					Type:     IssueTypeComplexity,
-					Severity: SeverityWarning,
-					Line:     funcInfo.StartLine,
-					Message:  fmt.Sprintf("Function '%s' has high complexity: %d", funcInfo.Name, funcInfo.Complexity),
-					Suggestion: "Consider breaking down this function into smaller functions",
-				})
+			analysis.Issues = append(analysis.Issues, checkFunctionThresholds(p.config, funcInfo)...)
+			analysis.Issues = append(analysis.Issues, checkParameterStruct(funcInfo)...)
+			analysis.Issues = append(analysis.Issues, p.checkRedundantConditionals(node, fset, typed)...)
+			analysis.Issues = append(analysis.Issues, p.checkEmptyErrorHandling(node, file, fset)...)
+
+			if p.isEnabled(IssueTypeErrorWrapping) {
+				analysis.Issues = append(analysis.Issues, p.checkErrorWrapping(node, fset)...)
 			}
-			
-			if funcInfo.LOC > 50 {
-				analysis.Issues = append(analysis.Issues, Issue{
-					Type:     IssueTypeFunctionLength,
-					Severity: SeverityWarning,
-					Line:     funcInfo.StartLine,
-					Message:  fmt.Sprintf("Function '%s' is too long: %d lines", funcInfo.Name, funcInfo.LOC),
-					Suggestion: "Consider refactoring into smaller functions",
-				})
+			if p.isEnabled(IssueTypePurity) {
+				analysis.Issues = append(analysis.Issues, checkPurity(node, file, fset)...)
+			}
+			if p.isEnabled(IssueTypeReturnShape) {
+				analysis.Issues = append(analysis.Issues, checkReturnShapes(node, fset)...)
+			}
+			if p.isEnabled(IssueTypeIgnoredError) {
+				analysis.Issues = append(analysis.Issues, checkIgnoredErrors(node, file, fset, declared)...)
+			}
+			if p.isEnabled(IssueTypePanic) && !context.IsTestFile(filePath) {
+				analysis.Issues = append(analysis.Issues, checkPanics(node, file, fset)...)
 			}
 
 		case *ast.TypeSpec:
@@ -84,12 +110,40 @@ func (p *GoParser) ParseFile(filePath string) (*FileAnalysis, error) {
 				classInfo := p.extractStruct(node, structType, fset)
 				analysis.Classes = append(analysis.Classes, classInfo)
 			}
+
+		case *ast.ValueSpec:
+			// Record named literals here so the name is known
+			for i, value := range node.Values {
+				if lit, ok := value.(*ast.CompositeLit); ok && i < len(node.Names) && isDataLiteral(lit) {
+					analysis.Literals = append(analysis.Literals, extractLiteral(lit, node.Names[i].Name, fset))
+					recorded[lit] = true
+				}
+			}
+
+		case *ast.CompositeLit:
+			if recorded[node] {
+				return false
+			}
+			if isDataLiteral(node) {
+				analysis.Literals = append(analysis.Literals, extractLiteral(node, "", fset))
+				return false
+			}
 		}
 		return true
 	})
+	attachMethods(analysis)
+
+	analysis.Issues = append(analysis.Issues, checkDebtMarkers(p.config, analysis.Language, string(content), 0)...)
+	if !isTestOrConfigPath(filePath) {
+		lines := strings.Split(string(content), "\n")
+		analysis.Issues = append(analysis.Issues, checkGoHardcodedHosts(file, fset, lines, p.config.HostAllowlist)...)
+		analysis.Issues = append(analysis.Issues, checkGoHardcodedSecrets(file, fset, lines)...)
+	}
 
 	// Calculate metrics
-	analysis.Metrics = p.calculateMetrics(string(content), analysis)
+	analysis.Metrics = calculateMetrics(string(content), analysis, p.config.DebtMarkers)
+	analysis.Metrics.HalsteadVolume, analysis.Metrics.MaintainabilityIndex = goMaintainability(content,
+		analysis.Functions, volumes, analysis.Metrics.LinesOfCode)
 
 	return analysis, nil
 }
@@ -108,17 +162,32 @@ func (p *GoParser) extractFunction(funcDecl *ast.FuncDecl, fset *token.FileSet)
 		IsExported: funcDecl.Name.IsExported(),
 	}
 
-	// Extract parameters
+	// Record the receiver type for methods
+	if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+		funcInfo.Receiver = receiverTypeName(funcDecl.Recv.List[0].Type)
+	}
+
+	// Extract parameters; unnamed ones are recorded as "_" so the types
+	// stay complete
 	if funcDecl.Type.Params != nil {
 		for _, param := range funcDecl.Type.Params.List {
+			paramType := nodeString(fset, param.Type)
+			if len(param.Names) == 0 {
+				funcInfo.Parameters = append(funcInfo.Parameters, "_")
+				funcInfo.ParameterTypes = append(funcInfo.ParameterTypes, paramType)
+			}
 			for _, name := range param.Names {
 				funcInfo.Parameters = append(funcInfo.Parameters, name.Name)
+				funcInfo.ParameterTypes = append(funcInfo.ParameterTypes, paramType)
 			}
 		}
 	}
+	funcInfo.ReturnType = resultTypeString(funcDecl.Type.Results, fset)
 
 	// Calculate complexity
 	funcInfo.Complexity = p.calculateComplexity(funcDecl)
+	funcInfo.CognitiveComplexity = cognitiveComplexity(funcDecl)
+	funcInfo.Returns = p.countReturns(funcDecl)
 
 	// Extract comments
 	if funcDecl.Doc != nil {
@@ -128,6 +197,63 @@ func (p *GoParser) extractFunction(funcDecl *ast.FuncDecl, fset *token.FileSet)
 	return funcInfo
 }
 
+// attachMethods copies each method into the Methods of its receiver's
+// struct. Methods stay in Functions too, so function metrics still cover
+// them; methods on types declared in another file are not attached.
+func attachMethods(analysis *FileAnalysis) {
+	classes := make(map[string]int, len(analysis.Classes))
+	for i, class := range analysis.Classes {
+		classes[class.Name] = i
+	}
+	for _, fn := range analysis.Functions {
+		if i, ok := classes[fn.Receiver]; ok && fn.Receiver != "" {
+			analysis.Classes[i].Methods = append(analysis.Classes[i].Methods, fn)
+		}
+	}
+}
+
+// resultTypeString renders a result list as it appears in the signature,
+// e.g. "error", "(int, error)" or "(n int, err error)"
+func resultTypeString(results *ast.FieldList, fset *token.FileSet) string {
+	if results == nil || len(results.List) == 0 {
+		return ""
+	}
+	if len(results.List) == 1 && len(results.List[0].Names) == 0 {
+		return nodeString(fset, results.List[0].Type)
+	}
+
+	parts := make([]string, 0, len(results.List))
+	for _, field := range results.List {
+		fieldType := nodeString(fset, field.Type)
+		if len(field.Names) == 0 {
+			parts = append(parts, fieldType)
+			continue
+		}
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		parts = append(parts, strings.Join(names, ", ")+" "+fieldType)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// receiverTypeName returns the base type name of a method receiver,
+// stripping pointers and type parameters (e.g. *Cache[K] -> Cache)
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
 // extractStruct extracts struct information
 func (p *GoParser) extractStruct(typeSpec *ast.TypeSpec, structType *ast.StructType, fset *token.FileSet) ClassInfo {
 	startPos := fset.Position(structType.Pos())
@@ -148,7 +274,7 @@ func (p *GoParser) extractStruct(typeSpec *ast.TypeSpec, structType *ast.StructT
 			for _, name := range field.Names {
 				fieldInfo := FieldInfo{
 					Name: name.Name,
-					Type: fmt.Sprintf("%v", field.Type),
+					Type: nodeString(fset, field.Type),
 				}
 				classInfo.Fields = append(classInfo.Fields, fieldInfo)
 			}
@@ -158,6 +284,72 @@ func (p *GoParser) extractStruct(typeSpec *ast.TypeSpec, structType *ast.StructT
 	return classInfo
 }
 
+// isDataLiteral reports whether a composite literal is a slice, array or
+// map of at least minDuplicateLiteralElements constant elements
+func isDataLiteral(lit *ast.CompositeLit) bool {
+	switch lit.Type.(type) {
+	case *ast.ArrayType, *ast.MapType:
+	default:
+		return false
+	}
+
+	if len(lit.Elts) < minDuplicateLiteralElements {
+		return false
+	}
+	for _, elt := range lit.Elts {
+		if !isConstantExpr(elt) {
+			return false
+		}
+	}
+	return true
+}
+
+// isConstantExpr reports whether an expression is built only from literals
+// and identifiers, e.g. "a", -1, Red or {1, 2}
+func isConstantExpr(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit, *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		return isConstantExpr(e.X)
+	case *ast.UnaryExpr:
+		return isConstantExpr(e.X)
+	case *ast.KeyValueExpr:
+		return isConstantExpr(e.Key) && isConstantExpr(e.Value)
+	case *ast.CompositeLit:
+		for _, elt := range e.Elts {
+			if !isConstantExpr(elt) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// extractLiteral records a data literal. Map entries are sorted before
+// hashing since their order carries no meaning.
+func extractLiteral(lit *ast.CompositeLit, name string, fset *token.FileSet) LiteralInfo {
+	typeName := nodeString(fset, lit.Type)
+
+	elements := make([]string, 0, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		elements = append(elements, nodeString(fset, elt))
+	}
+	if _, ok := lit.Type.(*ast.MapType); ok {
+		sort.Strings(elements)
+	}
+
+	hash := sha256.Sum256([]byte(typeName + "{" + strings.Join(elements, ",") + "}"))
+	return LiteralInfo{
+		Name:     name,
+		Type:     typeName,
+		Line:     fset.Position(lit.Pos()).Line,
+		Elements: len(lit.Elts),
+		Hash:     fmt.Sprintf("%x", hash[:8]),
+	}
+}
+
 // calculateComplexity calculates cyclomatic complexity
 func (p *GoParser) calculateComplexity(funcDecl *ast.FuncDecl) int {
 	complexity := 1 // Base complexity
@@ -188,9 +380,30 @@ func (p *GoParser) calculateComplexity(funcDecl *ast.FuncDecl) int {
 	return complexity
 }
 
-// calculateMetrics calculates overall file metrics
-func (p *GoParser) calculateMetrics(content string, analysis *FileAnalysis) CodeMetrics {
-	metrics := CalculateBasicMetrics(content)
+// countReturns counts return statements, excluding those in closures
+func (p *GoParser) countReturns(funcDecl *ast.FuncDecl) int {
+	if funcDecl.Body == nil {
+		return 0
+	}
+
+	count := 0
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			count++
+		}
+		return true
+	})
+
+	return count
+}
+
+// calculateMetrics calculates overall file metrics from the content and the
+// functions, classes and imports a parser extracted
+func calculateMetrics(content string, analysis *FileAnalysis, debtMarkers []string) CodeMetrics {
+	metrics := CalculateBasicMetrics(analysis.Language, content, debtMarkers)
 	
 	metrics.FunctionCount = len(analysis.Functions)
 	metrics.ClassCount = len(analysis.Classes)
@@ -0,0 +1,58 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+// goFunc returns a file holding one function whose body is n copies of stmt,
+// so it spans n+2 lines
+func goFunc(n int, stmt string) []byte {
+	var b strings.Builder
+	b.WriteString("package p\n\nfunc f(x int) {\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "\t"+stmt+"\n", i)
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+func hasIssue(issues []Issue, typ IssueType) bool {
+	for _, issue := range issues {
+		if issue.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGoParserThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.AnalysisConfig
+		content []byte
+		typ     IssueType
+		want    bool
+	}{
+		// Each if adds one to the base complexity of 1
+		{"complexity at threshold", config.AnalysisConfig{ComplexityThreshold: 5, MaxFunctionLength: 100}, goFunc(4, "if x > %d {}"), IssueTypeComplexity, false},
+		{"complexity above threshold", config.AnalysisConfig{ComplexityThreshold: 5, MaxFunctionLength: 100}, goFunc(5, "if x > %d {}"), IssueTypeComplexity, true},
+		{"length at threshold", config.AnalysisConfig{ComplexityThreshold: 100, MaxFunctionLength: 12}, goFunc(10, "x += %d"), IssueTypeFunctionLength, false},
+		{"length above threshold", config.AnalysisConfig{ComplexityThreshold: 100, MaxFunctionLength: 12}, goFunc(11, "x += %d"), IssueTypeFunctionLength, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis, err := NewGoParser(tt.cfg).ParseSource("p.go", tt.content)
+			if err != nil {
+				t.Fatalf("ParseSource: %v", err)
+			}
+			if got := hasIssue(analysis.Issues, tt.typ); got != tt.want {
+				t.Errorf("issue %s reported = %v, want %v (issues: %+v)", tt.typ, got, tt.want, analysis.Issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,514 @@
+package analysis
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/context"
+)
+
+var (
+	pyDefHeader    = regexp.MustCompile(`^(?:async\s+)?def\s+(\w+)\s*\(`)
+	pyClassHeader  = regexp.MustCompile(`^class\s+(\w+)`)
+	pyImport       = regexp.MustCompile(`^import\s+(.+)$`)
+	pyFromImport   = regexp.MustCompile(`^from\s+(\S+)\s+import\b`)
+	pyClassField   = regexp.MustCompile(`^(\w+)\s*(?::\s*([^=]+?)\s*(?:=[^=]|$)|=[^=])`)
+	pySelfField    = regexp.MustCompile(`^self\.(\w+)\s*(?::\s*([^=]+?))?\s*=[^=]`)
+	pyDecisionWord = regexp.MustCompile(`\b(if|elif|for|while|except|and|or)\b`)
+	pyReturnWord   = regexp.MustCompile(`\breturn\b`)
+	pyDocString    = regexp.MustCompile(`^(?:[rRbBuUfF]*""\s*)+$`)
+)
+
+// PythonParser parses Python source files. Python has no parser in the
+// standard library, so the source is split into logical lines and blocks
+// are recovered from their indentation.
+type PythonParser struct {
+	config config.AnalysisConfig
+}
+
+// NewPythonParser creates a new Python parser using the given analysis settings
+func NewPythonParser(cfg config.AnalysisConfig) *PythonParser {
+	return &PythonParser{config: cfg}
+}
+
+// ParseFile parses a Python source file
+func (p *PythonParser) ParseFile(filePath string) (*FileAnalysis, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return p.ParseSource(filePath, content)
+}
+
+// ParseSource extracts functions, classes and imports from Python source
+// that has already been read
+func (p *PythonParser) ParseSource(filePath string, content []byte) (*FileAnalysis, error) {
+	analysis := &FileAnalysis{
+		FilePath:  filePath,
+		Language:  string(context.LanguagePython),
+		Functions: make([]FunctionInfo, 0),
+		Classes:   make([]ClassInfo, 0),
+		Imports:   make([]ImportInfo, 0),
+		Issues:    make([]Issue, 0),
+	}
+
+	b := &pyBlockWalker{analysis: analysis, returnShapes: checkEnabled(p.config, IssueTypeReturnShape)}
+	for _, line := range pythonLogicalLines(string(content)) {
+		b.visit(line)
+	}
+	b.closeTo(-1)
+
+	for _, fn := range analysis.Functions {
+		analysis.Issues = append(analysis.Issues, checkFunctionThresholds(p.config, fn)...)
+	}
+	analysis.Issues = append(analysis.Issues, checkEmptyCatchBlocks(analysis.Language, string(content), 0)...)
+	analysis.Issues = append(analysis.Issues, checkIndentation(analysis.Language, string(content), 0)...)
+	analysis.Issues = append(analysis.Issues, checkDebtMarkers(p.config, analysis.Language, string(content), 0)...)
+	if !isTestOrConfigPath(filePath) {
+		analysis.Issues = append(analysis.Issues, checkHardcodedHostLines(string(content), 0, p.config.HostAllowlist)...)
+		analysis.Issues = append(analysis.Issues, checkHardcodedSecretLines(string(content), 0)...)
+	}
+
+	analysis.Metrics = calculateMetrics(string(content), analysis, p.config.DebtMarkers)
+
+	return analysis, nil
+}
+
+// pyLine is a logical line: physical lines joined across open brackets and
+// backslash continuations, with comments dropped and string literals
+// blanked to "" so keywords inside them are not counted
+type pyLine struct {
+	text      string
+	indent    int
+	startLine int
+	endLine   int
+	doc       string // literal content when the line is only a string, e.g. a docstring
+}
+
+// pythonLogicalLines splits Python source into non-blank logical lines
+func pythonLogicalLines(content string) []pyLine {
+	lines := make([]pyLine, 0)
+
+	var text strings.Builder
+	literals := make([]string, 0)
+	line, startLine, indent := 1, 1, 0
+	depth := 0
+	atStart := true
+
+	flush := func() {
+		trimmed := strings.TrimSpace(text.String())
+		if trimmed != "" {
+			l := pyLine{text: trimmed, indent: indent, startLine: startLine, endLine: line}
+			if pyDocString.MatchString(trimmed) {
+				l.doc = dedentDoc(strings.Join(literals, ""))
+			}
+			lines = append(lines, l)
+		}
+		text.Reset()
+		literals = literals[:0]
+		atStart = true
+	}
+
+	for i := 0; i < len(content); i++ {
+		if atStart {
+			// Measure indentation the way Python does, with tabs to the
+			// next multiple of eight
+			indent, startLine = 0, line
+			for ; i < len(content) && (content[i] == ' ' || content[i] == '\t' || content[i] == '\f'); i++ {
+				if content[i] == '\t' {
+					indent = (indent/8 + 1) * 8
+				} else {
+					indent++
+				}
+			}
+			atStart = false
+			if i == len(content) {
+				break
+			}
+		}
+
+		c := content[i]
+		switch {
+		case c == '#':
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+		case c == '\\' && i+1 < len(content) && content[i+1] == '\n':
+			text.WriteByte(' ')
+			line++
+			i++
+		case c == '"' || c == '\'':
+			literal, end, newlines := scanPythonString(content, i)
+			literals = append(literals, literal)
+			text.WriteString(`""`)
+			line += newlines
+			i = end
+		case c == '\n':
+			if depth > 0 {
+				text.WriteByte(' ')
+			} else {
+				flush()
+			}
+			line++
+		case c == '(' || c == '[' || c == '{':
+			depth++
+			text.WriteByte(c)
+		case c == ')' || c == ']' || c == '}':
+			if depth > 0 {
+				depth--
+			}
+			text.WriteByte(c)
+		default:
+			text.WriteByte(c)
+		}
+	}
+	flush()
+
+	return lines
+}
+
+// dedentDoc strips the indentation a multi-line docstring picks up from
+// the surrounding code
+func dedentDoc(doc string) string {
+	lines := strings.Split(strings.TrimSpace(doc), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// scanPythonString reads the string literal opening at start. It returns
+// the literal's content, the index of its last character and the number of
+// newlines it spans. An unterminated single-quoted string ends at the end
+// of its line.
+func scanPythonString(content string, start int) (string, int, int) {
+	quote := content[start : start+1]
+	if strings.HasPrefix(content[start:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+
+	newlines := 0
+	i := start + len(quote)
+	for i < len(content) {
+		switch {
+		case content[i] == '\\' && i+1 < len(content):
+			if content[i+1] == '\n' {
+				newlines++
+			}
+			i += 2
+			continue
+		case strings.HasPrefix(content[i:], quote):
+			return content[start+len(quote) : i], i + len(quote) - 1, newlines
+		case content[i] == '\n':
+			if len(quote) == 1 {
+				return content[start+1 : i], i - 1, newlines
+			}
+			newlines++
+		}
+		i++
+	}
+
+	return content[min(start+len(quote), len(content)):], len(content) - 1, newlines
+}
+
+// pyBlock is an open def or class
+type pyBlock struct {
+	indent   int
+	fn       *FunctionInfo // nil for classes
+	class    int           // index into Classes for a class, or the owning class for a method; -1 otherwise
+	bodySeen bool
+	returns  map[string]bool // kinds of value returned, see pythonValueKind
+}
+
+// pyBlockWalker tracks the open blocks while visiting logical lines in order
+type pyBlockWalker struct {
+	analysis     *FileAnalysis
+	stack        []*pyBlock
+	decorators   []string
+	returnShapes bool // report functions returning different kinds of values
+}
+
+// visit handles one logical line
+func (w *pyBlockWalker) visit(l pyLine) {
+	w.closeTo(l.indent)
+	for _, b := range w.stack {
+		if b.fn != nil {
+			b.fn.EndLine = l.endLine
+		} else {
+			w.analysis.Classes[b.class].EndLine = l.endLine
+		}
+	}
+
+	// Everything inside a function, including nested functions and
+	// classes, counts toward that function as closures do in Go
+	if len(w.stack) > 0 && w.stack[len(w.stack)-1].fn != nil {
+		top := w.stack[len(w.stack)-1]
+		if !top.bodySeen && l.doc != "" {
+			top.fn.Comments = l.doc
+		}
+		top.bodySeen = true
+		w.countBody(top, l.text)
+		w.addImports(l.text)
+		return
+	}
+
+	if strings.HasPrefix(l.text, "@") {
+		decorator := strings.TrimSpace(strings.TrimPrefix(l.text, "@"))
+		if idx := strings.Index(decorator, "("); idx >= 0 {
+			decorator = strings.TrimSpace(decorator[:idx])
+		}
+		w.decorators = append(w.decorators, decorator)
+		return
+	}
+	decorators := w.decorators
+	w.decorators = nil
+
+	if m := pyDefHeader.FindStringSubmatch(l.text); m != nil {
+		w.openFunction(l, m[1], decorators)
+		return
+	}
+	if m := pyClassHeader.FindStringSubmatch(l.text); m != nil {
+		w.openClass(l, m[1], decorators)
+		return
+	}
+
+	if len(w.stack) > 0 {
+		top := w.stack[len(w.stack)-1]
+		class := &w.analysis.Classes[top.class]
+		if !top.bodySeen && l.doc != "" {
+			class.Comments = l.doc
+		}
+		top.bodySeen = true
+		if m := pyClassField.FindStringSubmatch(l.text); m != nil {
+			addPythonField(class, m[1], m[2])
+		}
+	}
+
+	w.addImports(l.text)
+}
+
+// openFunction starts a def block. A def directly inside a class becomes
+// a method, with self or cls dropped from its parameters.
+func (w *pyBlockWalker) openFunction(l pyLine, name string, decorators []string) {
+	params, rest := splitPythonHeader(l.text)
+
+	fn := &FunctionInfo{
+		Name:       name,
+		StartLine:  l.startLine,
+		EndLine:    l.endLine,
+		Complexity: 1,
+		Parameters: make([]string, 0),
+		IsExported: isPythonPublic(name),
+		Decorators: decorators,
+	}
+
+	owner := -1
+	if len(w.stack) > 0 {
+		owner = w.stack[len(w.stack)-1].class
+		fn.Receiver = w.analysis.Classes[owner].Name
+	}
+
+	names, types := parsePythonParams(params)
+	if owner >= 0 && len(names) > 0 && (names[0] == "self" || names[0] == "cls") && !hasDecorator(decorators, "staticmethod") {
+		names, types = names[1:], types[1:]
+	}
+	fn.Parameters = append(fn.Parameters, names...)
+	fn.ParameterTypes = types
+
+	// The header ends at the first top-level colon; a return annotation
+	// sits between the parameters and that colon
+	header, body := splitAtTopLevelColon(rest)
+	if annotation, ok := strings.CutPrefix(strings.TrimSpace(header), "->"); ok {
+		fn.ReturnType = strings.TrimSpace(annotation)
+	}
+
+	block := &pyBlock{indent: l.indent, fn: fn, class: owner, returns: make(map[string]bool)}
+	w.stack = append(w.stack, block)
+	if body = strings.TrimSpace(body); body != "" {
+		block.bodySeen = true
+		w.countBody(block, body)
+	}
+}
+
+// openClass starts a class block
+func (w *pyBlockWalker) openClass(l pyLine, name string, decorators []string) {
+	w.analysis.Classes = append(w.analysis.Classes, ClassInfo{
+		Name:       name,
+		StartLine:  l.startLine,
+		EndLine:    l.endLine,
+		Methods:    make([]FunctionInfo, 0),
+		Fields:     make([]FieldInfo, 0),
+		IsExported: isPythonPublic(name),
+		Decorators: decorators,
+	})
+	w.stack = append(w.stack, &pyBlock{indent: l.indent, class: len(w.analysis.Classes) - 1})
+}
+
+// closeTo closes every block that a line at the given indent ends; -1
+// closes them all
+func (w *pyBlockWalker) closeTo(indent int) {
+	for len(w.stack) > 0 {
+		top := w.stack[len(w.stack)-1]
+		if indent > top.indent {
+			return
+		}
+		w.stack = w.stack[:len(w.stack)-1]
+
+		if top.fn == nil {
+			continue
+		}
+		fn := *top.fn
+		fn.LOC = fn.EndLine - fn.StartLine + 1
+		w.analysis.Functions = append(w.analysis.Functions, fn)
+		if w.returnShapes && len(top.returns) > 1 {
+			w.analysis.Issues = append(w.analysis.Issues, returnShapeIssue(fn.Name, fn.StartLine, sortedKeys(top.returns)))
+		}
+		if top.class >= 0 {
+			class := &w.analysis.Classes[top.class]
+			class.Methods = append(class.Methods, fn)
+		}
+	}
+}
+
+// countBody adds a line of a function's body to its complexity and return
+// count, and records instance attributes assigned in methods
+func (w *pyBlockWalker) countBody(b *pyBlock, text string) {
+	fn := b.fn
+	fn.Complexity += len(pyDecisionWord.FindAllString(text, -1))
+	if strings.HasPrefix(text, "case ") {
+		fn.Complexity++
+	}
+	fn.Returns += len(pyReturnWord.FindAllString(text, -1))
+	pythonReturnKinds(text, b.returns)
+
+	if b.class >= 0 {
+		if m := pySelfField.FindStringSubmatch(text); m != nil {
+			addPythonField(&w.analysis.Classes[b.class], m[1], m[2])
+		}
+	}
+}
+
+// addImports records the modules an import statement pulls in
+func (w *pyBlockWalker) addImports(text string) {
+	if m := pyFromImport.FindStringSubmatch(text); m != nil {
+		w.analysis.Imports = append(w.analysis.Imports, ImportInfo{Path: m[1]})
+		return
+	}
+
+	m := pyImport.FindStringSubmatch(text)
+	if m == nil {
+		return
+	}
+	for _, spec := range strings.Split(m[1], ",") {
+		fields := strings.Fields(spec)
+		if len(fields) == 0 {
+			continue
+		}
+		imp := ImportInfo{Path: fields[0]}
+		if len(fields) == 3 && fields[1] == "as" {
+			imp.Alias = fields[2]
+		}
+		w.analysis.Imports = append(w.analysis.Imports, imp)
+	}
+}
+
+// addPythonField adds a class or instance attribute unless it is already
+// known
+func addPythonField(class *ClassInfo, name, annotation string) {
+	for _, field := range class.Fields {
+		if field.Name == name {
+			return
+		}
+	}
+	class.Fields = append(class.Fields, FieldInfo{Name: name, Type: strings.TrimSpace(annotation)})
+}
+
+// splitPythonHeader returns the parameter list of a def header and the
+// text after its closing parenthesis
+func splitPythonHeader(text string) (string, string) {
+	open := strings.Index(text, "(")
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return text[open+1 : i], text[i+1:]
+			}
+		}
+	}
+	return text[open+1:], ""
+}
+
+// splitAtTopLevelColon splits text at its first colon outside brackets
+func splitAtTopLevelColon(text string) (string, string) {
+	parts := splitTopLevel(text, ':')
+	if len(parts) < 2 {
+		return text, ""
+	}
+	return parts[0], text[len(parts[0])+1:]
+}
+
+// parsePythonParams returns the names and annotations of a parameter
+// list. The bare "/" and "*" markers are skipped and "*args" and
+// "**kwargs" keep their names without the stars.
+func parsePythonParams(params string) ([]string, []string) {
+	names := make([]string, 0)
+	types := make([]string, 0)
+	for _, param := range splitTopLevel(params, ',') {
+		param = strings.TrimSpace(param)
+		if param == "" || param == "/" || param == "*" {
+			continue
+		}
+		param, _, _ = strings.Cut(param, "=")
+		name, annotation, _ := strings.Cut(param, ":")
+		names = append(names, strings.TrimLeft(strings.TrimSpace(name), "*"))
+		types = append(types, strings.TrimSpace(annotation))
+	}
+	return names, types
+}
+
+// splitTopLevel splits text at sep characters outside brackets
+func splitTopLevel(text string, sep byte) []string {
+	parts := make([]string, 0)
+	depth, start := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, text[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// isPythonPublic reports whether a name is public by Python convention:
+// no leading underscore, except for dunder names such as __init__
+func isPythonPublic(name string) bool {
+	if strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__") {
+		return true
+	}
+	return !strings.HasPrefix(name, "_")
+}
+
+// hasDecorator reports whether a decorator list contains name
+func hasDecorator(decorators []string, name string) bool {
+	for _, decorator := range decorators {
+		if decorator == name {
+			return true
+		}
+	}
+	return false
+}
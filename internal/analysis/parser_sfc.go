@@ -0,0 +1,256 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/context"
+)
+
+// SFCBlock is a top-level block of a Vue or Svelte single-file component
+type SFCBlock struct {
+	Tag       string // script, template or style
+	Attrs     string
+	Content   string
+	StartLine int // line of the opening tag
+	EndLine   int // line of the closing tag
+}
+
+// Lang returns the block's lang attribute, if any
+func (b SFCBlock) Lang() string {
+	if m := sfcLangAttr.FindStringSubmatch(b.Attrs); m != nil {
+		return strings.ToLower(m[1])
+	}
+	return ""
+}
+
+// Label describes the block for display, e.g. "script setup" or "style scoped"
+func (b SFCBlock) Label() string {
+	label := b.Tag
+	for _, attr := range []string{"setup", "scoped", "module", "context=\"module\""} {
+		if strings.Contains(b.Attrs, attr) {
+			label += " " + strings.SplitN(attr, "=", 2)[0]
+		}
+	}
+	return label
+}
+
+var sfcLangAttr = regexp.MustCompile(`\blang\s*=\s*["']?([\w-]+)`)
+
+// IsComponentFile reports whether a path is a Vue or Svelte component
+func IsComponentFile(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".vue", ".svelte":
+		return true
+	}
+	return false
+}
+
+// SFCParser parses Vue and Svelte single-file components
+type SFCParser struct {
+	config config.AnalysisConfig
+}
+
+// NewSFCParser creates a new single-file component parser
+func NewSFCParser(cfg config.AnalysisConfig) *SFCParser {
+	return &SFCParser{config: cfg}
+}
+
+// ParseFile parses a component file
+func (p *SFCParser) ParseFile(filePath string) (*FileAnalysis, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.ParseSource(filePath, content)
+}
+
+// ParseSource analyzes a component's script blocks and records which
+// sections it contains. The component counts as TypeScript when any
+// script block declares lang="ts".
+func (p *SFCParser) ParseSource(filePath string, content []byte) (*FileAnalysis, error) {
+	analysis := &FileAnalysis{
+		FilePath:  filePath,
+		Language:  string(context.LanguageJavaScript),
+		Functions: make([]FunctionInfo, 0),
+		Classes:   make([]ClassInfo, 0),
+		Imports:   make([]ImportInfo, 0),
+		Issues:    make([]Issue, 0),
+		Sections:  make([]string, 0),
+	}
+
+	scripts := make([]SFCBlock, 0)
+	for _, block := range ParseSFCBlocks(string(content)) {
+		analysis.Sections = append(analysis.Sections, block.Label())
+		if block.Tag != "script" {
+			continue
+		}
+		switch block.Lang() {
+		case "ts", "typescript", "tsx":
+			analysis.Language = string(context.LanguageTypeScript)
+		}
+		scripts = append(scripts, block)
+	}
+
+	scriptSource := make([]string, 0, len(scripts))
+	for _, block := range scripts {
+		scriptSource = append(scriptSource, block.Content)
+		analysis.Issues = append(analysis.Issues, checkEmptyCatchBlocks(analysis.Language, block.Content, block.StartLine)...)
+		analysis.Issues = append(analysis.Issues, checkIndentation(analysis.Language, block.Content, block.StartLine)...)
+		analysis.Issues = append(analysis.Issues, checkDebtMarkers(p.config, analysis.Language, block.Content, block.StartLine)...)
+		if !isTestOrConfigPath(filePath) {
+			analysis.Issues = append(analysis.Issues, checkHardcodedHostLines(block.Content, block.StartLine, p.config.HostAllowlist)...)
+			analysis.Issues = append(analysis.Issues, checkHardcodedSecretLines(block.Content, block.StartLine)...)
+		}
+	}
+
+	analysis.Metrics = CalculateBasicMetrics(analysis.Language, strings.Join(scriptSource, "\n"), p.config.DebtMarkers)
+
+	return analysis, nil
+}
+
+// ParseSFCBlocks splits a component into its top-level script, template
+// and style blocks. The source is scanned tag by tag: comments are
+// skipped, quoted attribute values may contain '>', opening tags may span
+// several lines, and nested <template> tags inside a Vue template are
+// matched by depth. Script and style content is opaque up to its closing
+// tag.
+func ParseSFCBlocks(source string) []SFCBlock {
+	blocks := make([]SFCBlock, 0)
+	lower := strings.ToLower(source)
+
+	for pos := 0; pos < len(source); {
+		lt := strings.IndexByte(source[pos:], '<')
+		if lt < 0 {
+			break
+		}
+		pos += lt
+
+		if strings.HasPrefix(source[pos:], "<!--") {
+			pos = skipComment(source, pos)
+			continue
+		}
+
+		name, tagEnd, ok := scanOpenTag(lower, pos)
+		if !ok {
+			pos++
+			continue
+		}
+		if name != "script" && name != "template" && name != "style" {
+			pos = tagEnd
+			continue
+		}
+
+		block := SFCBlock{
+			Tag:       name,
+			Attrs:     strings.TrimSpace(strings.TrimSuffix(source[pos+1+len(name):tagEnd-1], "/")),
+			StartLine: lineAt(source, pos),
+		}
+		if strings.HasSuffix(source[:tagEnd], "/>") {
+			block.EndLine = lineAt(source, tagEnd-1)
+			blocks = append(blocks, block)
+			pos = tagEnd
+			continue
+		}
+
+		closeStart, closeEnd := findCloseTag(source, lower, name, tagEnd)
+		// Content starts on the line after the opening tag, matching the
+		// line offsets the checks apply
+		block.Content = strings.TrimPrefix(strings.TrimPrefix(source[tagEnd:closeStart], "\r"), "\n")
+		block.EndLine = lineAt(source, closeStart)
+		blocks = append(blocks, block)
+		pos = closeEnd
+	}
+
+	return blocks
+}
+
+// scanOpenTag parses the opening tag at pos in lowercased source,
+// returning the tag name and the offset just past its '>'. Quoted
+// attribute values may contain '>'.
+func scanOpenTag(lower string, pos int) (name string, end int, ok bool) {
+	i := pos + 1
+	for i < len(lower) && (lower[i] >= 'a' && lower[i] <= 'z' || lower[i] >= '0' && lower[i] <= '9' || lower[i] == '-') {
+		i++
+	}
+	if i == pos+1 || i >= len(lower) {
+		return "", 0, false
+	}
+	name = lower[pos+1 : i]
+	if c := lower[i]; c != '>' && c != '/' && c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+		return "", 0, false
+	}
+
+	var quote byte
+	for ; i < len(lower); i++ {
+		switch c := lower[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return name, i + 1, true
+		}
+	}
+	return "", 0, false
+}
+
+// findCloseTag finds the closing tag matching a block opened just before
+// pos and returns the offsets of its start and of just past its end. A
+// block without a closing tag runs to the end of the source.
+func findCloseTag(source, lower, name string, pos int) (start, end int) {
+	depth := 1
+	for pos < len(lower) {
+		lt := strings.IndexByte(lower[pos:], '<')
+		if lt < 0 {
+			break
+		}
+		pos += lt
+
+		switch {
+		case name == "template" && strings.HasPrefix(lower[pos:], "<!--"):
+			pos = skipComment(lower, pos)
+			continue
+		case strings.HasPrefix(lower[pos:], "</"+name):
+			rest := strings.TrimLeft(lower[pos+2+len(name):], " \t\r\n")
+			if strings.HasPrefix(rest, ">") {
+				end := len(lower) - len(rest) + 1
+				if depth--; depth == 0 {
+					return pos, end
+				}
+				pos = end
+				continue
+			}
+		case name == "template":
+			// Only templates nest; script and style content is opaque
+			if tagName, tagEnd, ok := scanOpenTag(lower, pos); ok && tagName == name {
+				if !strings.HasSuffix(lower[:tagEnd], "/>") {
+					depth++
+				}
+				pos = tagEnd
+				continue
+			}
+		}
+		pos++
+	}
+	return len(source), len(source)
+}
+
+// skipComment returns the offset just past the HTML comment at pos
+func skipComment(source string, pos int) int {
+	if end := strings.Index(source[pos+4:], "-->"); end >= 0 {
+		return pos + 4 + end + 3
+	}
+	return len(source)
+}
+
+// lineAt returns the 1-based line number of an offset
+func lineAt(source string, offset int) int {
+	return strings.Count(source[:offset], "\n") + 1
+}
@@ -0,0 +1,57 @@
+package analysis
+
+import "testing"
+
+func TestParseSFCBlocks(t *testing.T) {
+	source := `<!-- <template> in a comment is not a block -->
+<template>
+  <div :title="a > b">
+    <template v-if="ok"><span/></template>
+    <template
+      v-else>
+      <!-- </template> -->
+      <p>no</p>
+    </template >
+    <template #footer />
+  </div>
+</template>
+
+<script
+  setup
+  lang="ts">
+const html = "<template>"
+</script>
+<style scoped>.a { color: red }</style>`
+
+	blocks := ParseSFCBlocks(source)
+	if len(blocks) != 3 {
+		t.Fatalf("got %d blocks, want 3: %+v", len(blocks), blocks)
+	}
+
+	tests := []struct {
+		tag        string
+		label      string
+		start, end int
+	}{
+		{"template", "template", 2, 12},
+		{"script", "script setup", 14, 18},
+		{"style", "style scoped", 19, 19},
+	}
+	for i, tt := range tests {
+		block := blocks[i]
+		if block.Tag != tt.tag || block.Label() != tt.label || block.StartLine != tt.start || block.EndLine != tt.end {
+			t.Errorf("block %d = %s %q lines %d-%d, want %s %q lines %d-%d",
+				i, block.Tag, block.Label(), block.StartLine, block.EndLine, tt.tag, tt.label, tt.start, tt.end)
+		}
+	}
+
+	if got := blocks[1].Lang(); got != "ts" {
+		t.Errorf("script lang = %q, want ts", got)
+	}
+	if got, want := blocks[1].Content, "const html = \"<template>\"\n"; got != want {
+		t.Errorf("script content = %q, want %q", got, want)
+	}
+	if got, want := blocks[2].Content, ".a { color: red }"; got != want {
+		t.Errorf("style content = %q, want %q", got, want)
+	}
+}
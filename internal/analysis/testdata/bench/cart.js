@@ -0,0 +1,46 @@
+// Shopping cart state for the storefront
+
+const TAX_RATE = 0.2;
+
+export class Cart {
+  constructor() {
+    this.items = new Map();
+  }
+
+  add(sku, price, quantity = 1) {
+    if (quantity <= 0) {
+      throw new Error("quantity must be positive");
+    }
+    const existing = this.items.get(sku);
+    if (existing) {
+      existing.quantity += quantity;
+    } else {
+      this.items.set(sku, { sku, price, quantity });
+    }
+  }
+
+  remove(sku) {
+    this.items.delete(sku);
+  }
+
+  subtotal() {
+    let total = 0;
+    for (const item of this.items.values()) {
+      total += item.price * item.quantity;
+    }
+    return total;
+  }
+
+  total() {
+    const subtotal = this.subtotal();
+    return Math.round(subtotal * (1 + TAX_RATE) * 100) / 100;
+  }
+}
+
+export function formatPrice(amount, currency = "EUR") {
+  try {
+    return new Intl.NumberFormat("en", { style: "currency", currency }).format(amount);
+  } catch (err) {
+    return `${amount.toFixed(2)} ${currency}`;
+  }
+}
@@ -0,0 +1,46 @@
+"""HTTP handlers for the orders service."""
+
+import json
+import logging
+
+logger = logging.getLogger(__name__)
+
+
+class OrderHandler:
+    """Serves order requests."""
+
+    def __init__(self, store):
+        self.store = store
+
+    def get(self, request):
+        order_id = request.params.get("id")
+        if not order_id:
+            return 400, {"error": "missing id"}
+        try:
+            order = self.store.get(order_id)
+        except KeyError:
+            return 404, {"error": "not found"}
+        return 200, order.to_dict()
+
+    def create(self, request):
+        try:
+            payload = json.loads(request.body)
+        except ValueError:
+            return 400, {"error": "invalid json"}
+        items = payload.get("items", [])
+        if not items:
+            return 400, {"error": "no items"}
+        for item in items:
+            if item.get("quantity", 0) <= 0:
+                return 400, {"error": "bad quantity"}
+        # FIXME: validate the customer exists
+        order = self.store.save(payload)
+        logger.info("created order %s", order.id)
+        return 201, order.to_dict()
+
+
+def paginate(items, page, size=20):
+    if page < 1:
+        page = 1
+    start = (page - 1) * size
+    return items[start:start + size]
@@ -0,0 +1,119 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when an order does not exist
+var ErrNotFound = errors.New("order not found")
+
+// Order is a customer order
+type Order struct {
+	ID        string
+	Customer  string
+	Items     []Item
+	CreatedAt time.Time
+	Status    string
+}
+
+// Item is one line of an order
+type Item struct {
+	SKU      string
+	Quantity int
+	Price    float64
+}
+
+// Store keeps orders in memory
+type Store struct {
+	orders map[string]*Order
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{orders: make(map[string]*Order)}
+}
+
+// Get returns an order by ID
+func (s *Store) Get(id string) (*Order, error) {
+	order, ok := s.orders[id]
+	if !ok {
+		return nil, fmt.Errorf("get %s: %w", id, ErrNotFound)
+	}
+	return order, nil
+}
+
+// Save validates and stores an order
+func (s *Store) Save(order *Order) error {
+	if order.ID == "" {
+		return errors.New("order needs an ID")
+	}
+	if len(order.Items) == 0 {
+		return errors.New("order needs at least one item")
+	}
+	for _, item := range order.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("item %s: quantity must be positive", item.SKU)
+		}
+		if item.Price < 0 {
+			return fmt.Errorf("item %s: price must not be negative", item.SKU)
+		}
+	}
+	s.orders[order.ID] = order
+	return nil
+}
+
+// Total returns the order's total price, applying volume discounts
+func (o *Order) Total() float64 {
+	total := 0.0
+	for _, item := range o.Items {
+		line := float64(item.Quantity) * item.Price
+		switch {
+		case item.Quantity >= 100:
+			line *= 0.85
+		case item.Quantity >= 50:
+			line *= 0.9
+		case item.Quantity >= 10:
+			line *= 0.95
+		}
+		total += line
+	}
+	return total
+}
+
+// ByCustomer lists a customer's orders, newest first
+func (s *Store) ByCustomer(customer string) []*Order {
+	orders := make([]*Order, 0)
+	for _, order := range s.orders {
+		if strings.EqualFold(order.Customer, customer) {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.After(orders[j].CreatedAt)
+	})
+	return orders
+}
+
+// Summarize describes the store's orders by status
+func (s *Store) Summarize() string {
+	counts := make(map[string]int)
+	for _, order := range s.orders {
+		counts[order.Status]++
+	}
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	var b strings.Builder
+	for _, status := range statuses {
+		// TODO: localize status names
+		fmt.Fprintf(&b, "%s: %d\n", status, counts[status])
+	}
+	return b.String()
+}
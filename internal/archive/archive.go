@@ -0,0 +1,214 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extraction limits guard against decompression bombs: an archive whose
+// entries expand beyond them is rejected rather than filling the disk
+var (
+	// MaxEntrySize is the largest single file extracted, in bytes
+	MaxEntrySize int64 = 256 << 20
+	// MaxTotalSize is the most extracted from one archive, in bytes
+	MaxTotalSize int64 = 1 << 30
+)
+
+// IsSupported reports whether the path has a recognized archive extension
+func IsSupported(path string) bool {
+	return format(path) != ""
+}
+
+// Extract unpacks a .tar, .tar.gz, .tgz or .zip archive into destDir
+func Extract(archivePath, destDir string) error {
+	switch format(archivePath) {
+	case "tar":
+		return extractTarFile(archivePath, destDir, false)
+	case "tar.gz":
+		return extractTarFile(archivePath, destDir, true)
+	case "zip":
+		return extractZip(archivePath, destDir)
+	default:
+		return fmt.Errorf("unsupported archive format: %s", filepath.Base(archivePath))
+	}
+}
+
+// ExtractToTemp unpacks an archive into a new temporary directory and
+// returns its path. The caller is responsible for removing it.
+func ExtractToTemp(archivePath string) (string, error) {
+	dir, err := os.MkdirTemp("", "katich-archive-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	if err := Extract(archivePath, dir); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// ContentRoot returns the directory holding an extracted archive's content.
+// Archives commonly wrap everything in a single top-level directory
+// (e.g. project-1.0/), in which case that directory is returned.
+func ContentRoot(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return dir
+	}
+	return filepath.Join(dir, entries[0].Name())
+}
+
+// format returns the archive format for a path based on its extension
+func format(path string) string {
+	name := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(name, ".tar"):
+		return "tar"
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	}
+	return ""
+}
+
+// extractTarFile extracts a (optionally gzip-compressed) tarball
+func extractTarFile(archivePath, destDir string, gzipped bool) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if gzipped {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip stream: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	tr := tar.NewReader(reader)
+	var written int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target, err := safeJoin(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, &written); err != nil {
+				return err
+			}
+		default:
+			// Skip symlinks, devices and other special entries
+		}
+	}
+}
+
+// extractZip extracts a zip archive
+func extractZip(archivePath, destDir string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer zr.Close()
+
+	var written int64
+	for _, entry := range zr.File {
+		target, err := safeJoin(destDir, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+			continue
+		}
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", entry.Name, err)
+		}
+		err = writeFile(target, rc, &written)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFile copies r into a new file at path, creating parent directories.
+// written tracks the bytes extracted from the archive so far; the copy
+// fails once the entry exceeds MaxEntrySize or the archive MaxTotalSize.
+func writeFile(path string, r io.Reader, written *int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	limit := MaxEntrySize
+	if remaining := MaxTotalSize - *written; remaining < limit {
+		limit = remaining
+	}
+	// Read one byte past the limit to tell "exactly at" from "over"
+	n, err := io.Copy(f, io.LimitReader(r, limit+1))
+	*written += n
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if n > MaxEntrySize {
+		return fmt.Errorf("archive entry %s exceeds the %d byte size limit", filepath.Base(path), MaxEntrySize)
+	}
+	if *written > MaxTotalSize {
+		return fmt.Errorf("archive exceeds the %d byte extraction limit", MaxTotalSize)
+	}
+
+	return nil
+}
+
+// safeJoin joins an archive entry name onto destDir, rejecting entries
+// that would escape it (e.g. "../../etc/passwd")
+func safeJoin(destDir, name string) (string, error) {
+	target := filepath.Join(destDir, name)
+	rel, err := filepath.Rel(destDir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry escapes destination: %s", name)
+	}
+	return target, nil
+}
@@ -0,0 +1,90 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTarGz creates a .tar.gz at path holding the given files
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setLimits lowers the extraction limits for the duration of a test
+func setLimits(t *testing.T, entry, total int64) {
+	t.Helper()
+	oldEntry, oldTotal := MaxEntrySize, MaxTotalSize
+	MaxEntrySize, MaxTotalSize = entry, total
+	t.Cleanup(func() { MaxEntrySize, MaxTotalSize = oldEntry, oldTotal })
+}
+
+func TestExtractSizeLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{"within limits", map[string]string{"a.go": strings.Repeat("x", 10), "b.go": strings.Repeat("x", 10)}, ""},
+		{"entry too large", map[string]string{"a.go": strings.Repeat("x", 11)}, "size limit"},
+		{"total too large", map[string]string{"a.go": strings.Repeat("x", 10), "b.go": strings.Repeat("x", 10), "c.go": "x"}, "extraction limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLimits(t, 10, 20)
+			dir := t.TempDir()
+			archivePath := filepath.Join(dir, "drop.tar.gz")
+			writeTarGz(t, archivePath, tt.files)
+
+			err := Extract(archivePath, filepath.Join(dir, "out"))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Extract: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Extract error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractRejectsEscapingEntries(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "drop.tar.gz")
+	writeTarGz(t, archivePath, map[string]string{"../evil.go": "package evil"})
+
+	if err := Extract(archivePath, filepath.Join(dir, "out")); err == nil {
+		t.Fatal("Extract accepted an entry outside the destination")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.go")); err == nil {
+		t.Fatal("escaping entry was written")
+	}
+}
@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// statsFile records hit and miss counts; it is never evicted
+const statsFile = "stats.json"
+
+// Dir returns the cache directory of a repository
+func Dir(rootPath string) string {
+	return filepath.Join(rootPath, ".katich", "cache")
+}
+
+// Counter tracks lookups for one cache (e.g. "git")
+type Counter struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+// HitRate returns the fraction of lookups served from the cache
+func (c Counter) HitRate() float64 {
+	if c.Hits+c.Misses == 0 {
+		return 0
+	}
+	return float64(c.Hits) / float64(c.Hits+c.Misses)
+}
+
+// Info describes the contents of a cache directory
+type Info struct {
+	TotalBytes int64
+	Files      int
+	ByCache    map[string]int64 // bytes per top-level subdirectory
+	Counters   map[string]Counter
+}
+
+// entry is a cached file considered for eviction
+type entry struct {
+	path     string
+	size     int64
+	lastUsed time.Time
+}
+
+// Touch marks a cache file as used so that eviction keeps it longer
+func Touch(path string) {
+	now := time.Now()
+	os.Chtimes(path, now, now)
+}
+
+// Record counts a hit or miss for the named cache. Failures are ignored,
+// since statistics must never break the operation being cached.
+func Record(dir, name string, hit bool) {
+	if hit {
+		RecordCounts(dir, name, 1, 0)
+	} else {
+		RecordCounts(dir, name, 0, 1)
+	}
+}
+
+// RecordCounts adds several hits and misses for the named cache at once,
+// for caches consulted many times per run
+func RecordCounts(dir, name string, hits, misses int) {
+	if hits == 0 && misses == 0 {
+		return
+	}
+
+	counters := loadCounters(dir)
+	counter := counters[name]
+	counter.Hits += hits
+	counter.Misses += misses
+	counters[name] = counter
+
+	data, err := json.MarshalIndent(counters, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	os.WriteFile(filepath.Join(dir, statsFile), data, 0644)
+}
+
+// loadCounters reads the hit and miss counts, returning empty counts if
+// none were recorded
+func loadCounters(dir string) map[string]Counter {
+	counters := make(map[string]Counter)
+	if data, err := os.ReadFile(filepath.Join(dir, statsFile)); err == nil {
+		json.Unmarshal(data, &counters)
+	}
+	return counters
+}
+
+// Stat reports the size and hit rates of a cache directory
+func Stat(dir string) (*Info, error) {
+	entries, err := listEntries(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{
+		ByCache:  make(map[string]int64),
+		Counters: loadCounters(dir),
+	}
+	for _, e := range entries {
+		info.TotalBytes += e.size
+		info.Files++
+
+		rel, _ := filepath.Rel(dir, e.path)
+		name, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+		info.ByCache[name] += e.size
+	}
+
+	return info, nil
+}
+
+// Evict removes the least recently used files until the cache is at most
+// maxBytes. A maxBytes of 0 or less disables eviction. It returns the
+// number of files and bytes removed.
+func Evict(dir string, maxBytes int64) (int, int64, error) {
+	if maxBytes <= 0 {
+		return 0, 0, nil
+	}
+
+	entries, err := listEntries(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.Before(entries[j].lastUsed)
+	})
+
+	removed, freed := 0, int64(0)
+	for _, e := range entries {
+		if total <= maxBytes {
+			break
+		}
+		if err := os.Remove(e.path); err != nil {
+			return removed, freed, fmt.Errorf("failed to evict %s: %w", e.path, err)
+		}
+		total -= e.size
+		freed += e.size
+		removed++
+	}
+
+	return removed, freed, nil
+}
+
+// Clear removes the cache directory, including its statistics
+func Clear(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// listEntries returns every cached file except the statistics file. A
+// missing cache directory has no entries.
+func listEntries(dir string) ([]entry, error) {
+	entries := make([]entry, 0)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || path == filepath.Join(dir, statsFile) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{path: path, size: info.Size(), lastUsed: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan cache: %w", err)
+	}
+
+	return entries, nil
+}
+
+// FormatBytes renders a byte count for display, e.g. "3.2 MB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,66 @@
+package ci
+
+import (
+	"os"
+
+	"github.com/katichai/katich/internal/git"
+)
+
+// baseRefVars are the environment variables CI systems use for the target
+// branch of a pull or merge request, in lookup order
+var baseRefVars = []struct {
+	system string
+	name   string
+}{
+	{"GitHub Actions", "GITHUB_BASE_REF"},
+	{"GitLab CI", "CI_MERGE_REQUEST_TARGET_BRANCH_NAME"},
+	{"Bitbucket Pipelines", "BITBUCKET_PR_DESTINATION_BRANCH"},
+	{"Azure Pipelines", "SYSTEM_PULLREQUEST_TARGETBRANCH"},
+}
+
+// BaseRef is a resolved base ref and where it came from
+type BaseRef struct {
+	Ref    string
+	Source string // e.g. "GitHub Actions (GITHUB_BASE_REF)" or "default branch"
+}
+
+// DetectBaseBranch returns the target branch advertised by the CI
+// environment, if any
+func DetectBaseBranch() (branch string, source string, ok bool) {
+	for _, v := range baseRefVars {
+		if value := os.Getenv(v.name); value != "" {
+			return trimBranchPrefix(value), v.system + " (" + v.name + ")", true
+		}
+	}
+	return "", "", false
+}
+
+// ResolveBaseRef determines the base ref for a branch review. A CI target
+// branch is preferred as origin/<branch> since CI checkouts rarely have a
+// local copy; otherwise the repository's default branch is used.
+func ResolveBaseRef(repo *git.Repository) (*BaseRef, error) {
+	if branch, source, ok := DetectBaseBranch(); ok {
+		for _, ref := range []string{"origin/" + branch, branch} {
+			if repo.RefExists(ref) {
+				return &BaseRef{Ref: ref, Source: source}, nil
+			}
+		}
+		// Let git report the unknown ref when the diff is computed
+		return &BaseRef{Ref: "origin/" + branch, Source: source}, nil
+	}
+
+	branch, err := repo.GetDefaultBranch()
+	if err != nil {
+		return nil, err
+	}
+	return &BaseRef{Ref: branch, Source: "default branch"}, nil
+}
+
+// trimBranchPrefix strips refs/heads/ as reported by Azure Pipelines
+func trimBranchPrefix(branch string) string {
+	const prefix = "refs/heads/"
+	if len(branch) > len(prefix) && branch[:len(prefix)] == prefix {
+		return branch[len(prefix):]
+	}
+	return branch
+}
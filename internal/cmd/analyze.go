@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/archive"
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/context"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Analyze flags
+	archivePath string
+)
+
+func init() {
+	analyzeCmd.Flags().StringVar(&archivePath, "archive", "", "analyze a .tar.gz, .tgz, .tar or .zip archive instead of a directory")
+}
+
+// analyzeCmd analyzes a directory or archive without requiring Git
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze [path]",
+	Short: "Analyze a directory or archive outside of a Git repository",
+	Long: `Run framework detection and static analysis on a plain directory or an
+archive (for example a vendor code drop) without requiring a Git repository.
+
+Examples:
+  katich analyze ./vendor-drop
+  katich analyze --archive drop.tar.gz`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 && archivePath != "" {
+			return fmt.Errorf("cannot combine a path argument with --archive")
+		}
+		if len(args) > 0 {
+			path = args[0]
+		}
+		return runAnalyze(path)
+	},
+}
+
+func runAnalyze(path string) error {
+	rootPath, cleanup, err := resolveAnalyzeRoot(path)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if archivePath != "" {
+		out.Printf("🔍 Analyzing archive: %s\n", archivePath)
+	} else {
+		out.Printf("🔍 Analyzing directory: %s\n", rootPath)
+	}
+	out.Println()
+
+	if verbose {
+		out.Println("Verbose mode enabled")
+		out.Printf("Root: %s\n", rootPath)
+		out.Println()
+	}
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	detector := context.NewDetector(rootPath)
+	result, err := detector.Detect()
+	if err != nil {
+		return fmt.Errorf("failed to detect frameworks: %w", err)
+	}
+
+	if err := applyCheckFilters(cfg); err != nil {
+		return err
+	}
+	analyzer := analysis.NewAnalyzer(rootPath, cfg.Analysis)
+	endAnalyze := auditLog.Phase("analyze")
+	analysisResult, err := analyzer.AnalyzeRepository()
+	endAnalyze()
+	if err != nil {
+		return fmt.Errorf("failed to analyze code: %w", err)
+	}
+
+	out.Println("📊 Analysis Results:")
+	out.Println()
+
+	printDetectionResult(result)
+	printAnalysisResult(analysisResult)
+
+	if len(result.Patterns) > 0 {
+		out.Println("Architectural patterns:")
+		for _, pattern := range result.Patterns {
+			out.Printf("  • %s\n", pattern)
+		}
+		out.Println()
+	}
+
+	return nil
+}
+
+// resolveAnalyzeRoot returns the directory to analyze, extracting the
+// archive to a temporary directory when --archive is set. The returned
+// cleanup function removes any temporary files.
+func resolveAnalyzeRoot(path string) (string, func(), error) {
+	noop := func() {}
+
+	if archivePath == "" {
+		rootPath, err := filepath.Abs(path)
+		if err != nil {
+			return "", noop, fmt.Errorf("failed to resolve path: %w", err)
+		}
+		info, err := os.Stat(rootPath)
+		if err != nil {
+			return "", noop, fmt.Errorf("failed to access %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			return "", noop, fmt.Errorf("%s is not a directory (use --archive for archives)", path)
+		}
+		return rootPath, noop, nil
+	}
+
+	if !archive.IsSupported(archivePath) {
+		return "", noop, fmt.Errorf("unsupported archive format: %s", archivePath)
+	}
+
+	tempDir, err := archive.ExtractToTemp(archivePath)
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to extract archive: %w", err)
+	}
+
+	return archive.ContentRoot(tempDir), func() { os.RemoveAll(tempDir) }, nil
+}
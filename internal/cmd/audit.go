@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/embeddings"
+	"github.com/katichai/katich/internal/git"
+	"github.com/katichai/katich/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// auditLog records the phases, timings, provider calls and errors of
+	// the current run when log.file is configured, and discards them
+	// otherwise
+	auditLog = logging.Discard()
+
+	runStarted time.Time
+)
+
+// startAuditLog opens the audit log before a command runs. A missing or
+// invalid config leaves logging off; the command reports config errors
+// itself.
+func startAuditLog(cmd *cobra.Command, args []string) {
+	runStarted = time.Now()
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil || cfg.Log.File == "" {
+		return
+	}
+
+	rootPath := "."
+	if repo, err := git.FindRepository(); err == nil {
+		rootPath = repo.RootPath
+	}
+
+	logger, err := logging.Open(rootPath, cfg.Log, cfg.LLM.APIKey, cfg.Embeddings.APIKey)
+	if err != nil {
+		out.Printf("⚠️  Audit log disabled: %v\n", err)
+		return
+	}
+	auditLog = logger
+	auditLog.Info("run started", "command", cmd.CommandPath(), "args", strings.Join(args, " "), "version", Version)
+}
+
+// finishAuditLog records how the run ended and closes the audit log
+func finishAuditLog(err error) {
+	duration := time.Since(runStarted).Milliseconds()
+	if err != nil {
+		auditLog.Error("run failed", "error", err, "duration_ms", duration)
+	} else {
+		auditLog.Info("run finished", "duration_ms", duration)
+	}
+	auditLog.Close()
+}
+
+// loggedProvider records each embedding request in the audit log. Only
+// the size of the embedded text is logged, never the code itself.
+type loggedProvider struct {
+	*embeddings.HybridProvider
+}
+
+// GenerateEmbedding generates an embedding and logs the call
+func (p loggedProvider) GenerateEmbedding(text string) ([]float32, error) {
+	start, before := time.Now(), p.GetActiveProvider()
+	vector, err := p.HybridProvider.GenerateEmbedding(text)
+	p.noteSwitch(before)
+	attrs := []any{
+		"provider", p.GetActiveProvider(),
+		"chars", len(text),
+		"duration_ms", time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		auditLog.Warn("embedding request failed", append(attrs, "error", err)...)
+	} else {
+		auditLog.Debug("embedding request", attrs...)
+	}
+	return vector, err
+}
+
+// GenerateEmbeddings generates a batch of embeddings and logs the call
+func (p loggedProvider) GenerateEmbeddings(texts []string) ([][]float32, error) {
+	start, before := time.Now(), p.GetActiveProvider()
+	vectors, err := p.HybridProvider.GenerateEmbeddings(texts)
+	p.noteSwitch(before)
+	chars := 0
+	for _, text := range texts {
+		chars += len(text)
+	}
+	attrs := []any{
+		"provider", p.GetActiveProvider(),
+		"texts", len(texts),
+		"chars", chars,
+		"duration_ms", time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		auditLog.Warn("embedding batch request failed", append(attrs, "error", err)...)
+	} else {
+		auditLog.Debug("embedding batch request", attrs...)
+	}
+	return vectors, err
+}
+
+// noteSwitch reports when the hybrid provider moved between Ollama and
+// OpenAI during a request, so a build that skipped the other provider's
+// vectors can be told apart in the output and the audit log
+func (p loggedProvider) noteSwitch(before string) {
+	after := p.GetActiveProvider()
+	if after == before {
+		return
+	}
+	auditLog.Info("embedding provider switched", "from", before, "to", after)
+	out.Printf("⚠️  Embedding provider switched from %s to %s\n", before, after)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/katichai/katich/internal/cache"
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command group
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the .katich/cache directory",
+	Long: `Report the size and hit rates of the caches under .katich/cache, or clear
+them. The cache is trimmed to cache.max_size_mb after each context build,
+evicting the least recently used files first.`,
+}
+
+// cacheInfoCmd reports cache size and hit rates
+var cacheInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show cache size and hit rates",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCacheInfo()
+	},
+}
+
+// cacheClearCmd removes all cached data
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached data and statistics",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCacheClear()
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheInfoCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+func runCacheInfo() error {
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	dir := cache.Dir(repo.RootPath)
+	info, err := cache.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	limit := "unlimited"
+	if cfg.Cache.MaxSizeMB > 0 {
+		limit = cache.FormatBytes(cfg.Cache.MaxBytes())
+	}
+
+	out.Println("🗄️  Cache")
+	out.Println()
+	fmt.Fprintf(stdout, "Location: %s\n", dir)
+	fmt.Fprintf(stdout, "Size:     %s in %d file(s) (limit %s)\n", cache.FormatBytes(info.TotalBytes), info.Files, limit)
+
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for name := range info.ByCache {
+		names = append(names, name)
+		seen[name] = true
+	}
+	for name := range info.Counters {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 {
+		fmt.Fprintln(stdout)
+		fmt.Fprintf(stdout, "%-12s  %10s  %6s  %6s  %8s\n", "CACHE", "SIZE", "HITS", "MISSES", "HIT RATE")
+		for _, name := range names {
+			counter := info.Counters[name]
+			fmt.Fprintf(stdout, "%-12s  %10s  %6d  %6d  %7.0f%%\n",
+				name, cache.FormatBytes(info.ByCache[name]), counter.Hits, counter.Misses, counter.HitRate()*100)
+		}
+	}
+
+	return nil
+}
+
+func runCacheClear() error {
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	dir := cache.Dir(repo.RootPath)
+	info, err := cache.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	if err := cache.Clear(dir); err != nil {
+		return err
+	}
+
+	out.Printf("✅ Cleared %s from %d cache file(s)\n", cache.FormatBytes(info.TotalBytes), info.Files)
+	return nil
+}
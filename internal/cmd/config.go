@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configCmd represents the config command group
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect katich configuration",
+	Long:  `Inspect the effective katich configuration, including values resolved from key files, the OS keyring and environment variables.`,
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+}
+
+// configShowCmd displays the effective configuration
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Display the effective configuration",
+	Long:  `Print the effective configuration as YAML. API keys are masked.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigShow()
+	},
+}
+
+func runConfigShow() error {
+	configPath := configFile
+	if configPath == "" {
+		configPath = ".katich/config.yaml"
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	data, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	out.Printf("📄 Configuration (%s)\n", configPath)
+	out.Println()
+	fmt.Fprint(stdout, string(data))
+
+	return nil
+}
@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/cache"
 	"github.com/katichai/katich/internal/config"
 	"github.com/katichai/katich/internal/context"
 	"github.com/katichai/katich/internal/embeddings"
 	"github.com/katichai/katich/internal/git"
+	"github.com/katichai/katich/internal/history"
 	"github.com/spf13/cobra"
 )
 
@@ -23,10 +28,14 @@ The context includes framework detection, AST parsing, embeddings generation,
 and similarity indexing.`,
 }
 
+// contextSchemaVersion is the version of the context.json layout
+const contextSchemaVersion = "1"
+
 var (
 	// Context build flags
 	forceRebuild bool
 	incremental  bool
+	changedOnly  string
 )
 
 func init() {
@@ -34,10 +43,13 @@ func init() {
 	contextCmd.AddCommand(contextBuildCmd)
 	contextCmd.AddCommand(contextShowCmd)
 	contextCmd.AddCommand(contextClearCmd)
+	contextCmd.AddCommand(contextValidateCmd)
+	contextCmd.AddCommand(contextReindexCmd)
 
 	// Flags for context build
 	contextBuildCmd.Flags().BoolVarP(&forceRebuild, "force", "f", false, "force full rebuild (ignore cache)")
 	contextBuildCmd.Flags().BoolVarP(&incremental, "incremental", "i", true, "incremental update (only changed files)")
+	contextBuildCmd.Flags().StringVar(&changedOnly, "changed-only", "", "only analyze and embed files changed in this git range, merging into the existing context")
 }
 
 // contextBuildCmd builds the codebase context
@@ -47,7 +59,10 @@ var contextBuildCmd = &cobra.Command{
 	Long: `Scan the repository, detect frameworks and languages, parse ASTs,
 generate embeddings, and build a FAISS similarity index.
 
-The context is stored in .katich/context.json and .katich/embeddings.index`,
+Builds are incremental: only files whose content changed since the last
+build are analyzed and embedded again. --force rebuilds everything.
+
+The context is stored in .katich/context.json and .katich/embeddings.bin`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runContextBuild()
 	},
@@ -67,15 +82,15 @@ var contextShowCmd = &cobra.Command{
 var contextClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear cached context and embeddings",
-	Long:  `Remove all cached context files, including context.json and embeddings.index.`,
+	Long:  `Remove all cached context files, including context.json, embeddings.bin and cache/.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runContextClear()
 	},
 }
 
 func runContextBuild() error {
-	fmt.Println("🔨 Building codebase context...")
-	fmt.Println()
+	out.Println("🔨 Building codebase context...")
+	out.Println()
 	
 	// Find Git repository
 	repo, err := git.FindRepository()
@@ -84,175 +99,169 @@ func runContextBuild() error {
 	}
 	
 	if verbose {
-		fmt.Println("Verbose mode enabled")
-		fmt.Printf("Repository: %s\n", repo.RootPath)
-		fmt.Printf("Force rebuild: %v\n", forceRebuild)
-		fmt.Printf("Incremental: %v\n", incremental)
-		fmt.Println()
+		out.Println("Verbose mode enabled")
+		out.Printf("Repository: %s\n", repo.RootPath)
+		out.Printf("Force rebuild: %v\n", forceRebuild)
+		out.Printf("Incremental: %v\n", incremental)
+		out.Println()
 	}
 
-	// Create detector
+	// Load config for analysis thresholds and API keys
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Create detector, sharing file reads with analysis and hashing
+	files := context.NewFileCache()
 	detector := context.NewDetector(repo.RootPath)
+	detector.SetFileCache(files)
 	
-	fmt.Println("🔍 Scanning repository...")
+	out.Println("🔍 Scanning repository...")
+	endScan := auditLog.Phase("scan")
 	result, err := detector.Detect()
+	endScan()
 	if err != nil {
 		return fmt.Errorf("failed to detect frameworks: %w", err)
 	}
 
 	// Run static analysis
-	fmt.Println("📊 Analyzing code...")
-	analyzer := analysis.NewAnalyzer(repo.RootPath)
-	analysisResult, err := analyzer.AnalyzeRepository()
+	out.Println("📊 Analyzing code...")
+	if err := applyCheckFilters(cfg); err != nil {
+		return err
+	}
+	analyzer := analysis.NewAnalyzer(repo.RootPath, cfg.Analysis)
+	analyzer.SetFileCache(files)
+
+	var analysisResult *analysis.AnalysisResult
+	var changed *changedBuild
+	settings := analysisSettings(cfg.Analysis)
+	endAnalyze := auditLog.Phase("analyze")
+	if changedOnly != "" {
+		analysisResult, changed, err = analyzeChangedOnly(repo, analyzer, files, changedOnly)
+	} else if incremental && !forceRebuild {
+		analysisResult, changed, err = analyzeIncremental(repo, analyzer, files, settings)
+	}
+	if analysisResult == nil && err == nil {
+		analysisResult, err = analyzer.AnalyzeRepository()
+	}
+	endAnalyze()
 	if err != nil {
 		return fmt.Errorf("failed to analyze code: %w", err)
 	}
 
 	// Display results
-	fmt.Println()
-	fmt.Println("📊 Detection Results:")
-	fmt.Println()
-
-	// Languages
-	if len(result.Languages) > 0 {
-		fmt.Println("Languages detected:")
-		for lang, count := range result.Languages {
-			fmt.Printf("  • %s (%d files)\n", lang, count)
-		}
-		fmt.Println()
-	}
-
-	// Frameworks
-	if len(result.Frameworks) > 0 {
-		fmt.Println("Frameworks detected:")
-		
-		// Group by type
-		byType := make(map[context.FrameworkType][]context.Framework)
-		for _, fw := range result.Frameworks {
-			byType[fw.Type] = append(byType[fw.Type], fw)
-		}
-
-		// Display by type
-		typeOrder := []context.FrameworkType{
-			context.FrameworkTypeBackend,
-			context.FrameworkTypeFrontend,
-			context.FrameworkTypeFullStack,
-			context.FrameworkTypeUI,
-			context.FrameworkTypeBuild,
-		}
-
-		for _, fwType := range typeOrder {
-			if frameworks, ok := byType[fwType]; ok && len(frameworks) > 0 {
-				fmt.Printf("\n  %s:\n", fwType)
-				for _, fw := range frameworks {
-					fmt.Printf("    • %s (%s)\n", fw.Name, fw.Language)
-				}
-			}
-		}
-		fmt.Println()
-	}
+	out.Println()
+	out.Println("📊 Detection Results:")
+	out.Println()
 
-	// Code Metrics
-	fmt.Println("Code Metrics:")
-	fmt.Printf("  • Total Lines of Code: %d\n", analysisResult.TotalMetrics.LinesOfCode)
-	fmt.Printf("  • Total Functions: %d\n", analysisResult.TotalMetrics.FunctionCount)
-	fmt.Printf("  • Total Classes/Structs: %d\n", analysisResult.TotalMetrics.ClassCount)
-	fmt.Printf("  • Average Function Length: %.1f lines\n", analysisResult.TotalMetrics.AvgFunctionLength)
-	fmt.Printf("  • Max Function Length: %d lines\n", analysisResult.TotalMetrics.MaxFunctionLength)
-	fmt.Printf("  • Total Complexity: %d\n", analysisResult.TotalMetrics.CyclomaticComplexity)
-	fmt.Println()
-
-	// Issues Summary
-	if analysisResult.IssuesSummary.TotalIssues > 0 {
-		fmt.Println("Issues Found:")
-		fmt.Printf("  • Total: %d\n", analysisResult.IssuesSummary.TotalIssues)
-		
-		if len(analysisResult.IssuesSummary.BySeverity) > 0 {
-			fmt.Println("  By Severity:")
-			for severity, count := range analysisResult.IssuesSummary.BySeverity {
-				fmt.Printf("    - %s: %d\n", severity, count)
-			}
-		}
-		fmt.Println()
-	}
-
-	// Top Complex Functions
-	if len(analysisResult.TopComplexity) > 0 {
-		fmt.Println("Most Complex Functions:")
-		for i, fn := range analysisResult.TopComplexity {
-			if i >= 5 {
-				break
-			}
-			fmt.Printf("  %d. %s (complexity: %d, %d lines)\n", i+1, fn.Name, fn.Complexity, fn.LOC)
-		}
-		fmt.Println()
-	}
+	printDetectionResult(result)
+	printAnalysisResult(analysisResult)
 
 	// Generate embeddings
-	fmt.Println("🧠 Generating embeddings...")
+	out.Println("🧠 Generating embeddings...")
 	
-	// Load config to get API keys
-	cfg, err := config.Load(GetConfig())
-	if err != nil {
-		fmt.Println("  ⚠️  No config found, using defaults")
-		cfg = config.DefaultConfig()
-	}
-
 	// Create embedding provider (hybrid)
-	provider := embeddings.NewHybridProvider(
-		"http://localhost:11434",
-		"nomic-embed-text",
-		cfg.LLM.APIKey,
-		"text-embedding-3-small",
-	)
+	provider := newEmbeddingProvider(cfg)
 
-	fmt.Printf("  Using provider: %s\n", provider.GetActiveProvider())
+	out.Printf("  Using provider: %s\n", provider.GetActiveProvider())
 
 	// Generate embeddings
 	generator := embeddings.NewGenerator(provider, repo.RootPath)
-	embeddingIndex, err := generator.GenerateForAnalysis(analysisResult)
+	generator.SetFileCache(files)
+	generator.SetOutput(out.Writer())
+	generator.OnProgress(out.progressBar())
+	generator.SetMinFunctionLines(cfg.Embeddings.MinFunctionLines)
+	generator.EnableCache(forceRebuild)
+
+	// Reuse vectors of unchanged functions unless a full rebuild was requested
+	embeddingPath := filepath.Join(repo.RootPath, ".katich", embeddings.IndexFile)
+	if !forceRebuild {
+		if previous, err := embeddings.LoadIndex(embeddings.IndexPath(filepath.Dir(embeddingPath))); err == nil {
+			generator.SetPrevious(previous)
+		}
+	}
+
+	var embeddingIndex *embeddings.EmbeddingIndex
+	endEmbeddings := auditLog.Phase("embeddings")
+	if changed != nil {
+		embeddingIndex, err = generateChangedEmbeddings(generator, changed, embeddingPath)
+		if err != nil && changedOnly == "" {
+			// An index from another provider cannot take the new vectors
+			out.Printf("  ℹ️  Cannot update the embedding index (%v), embedding every function\n", err)
+			embeddingIndex, err = generator.GenerateForAnalysis(analysisResult)
+		}
+	} else {
+		embeddingIndex, err = generator.GenerateForAnalysis(analysisResult)
+	}
+	endEmbeddings()
 	if err != nil {
-		fmt.Printf("  ⚠️  Failed to generate embeddings: %v\n", err)
-		fmt.Println("  Continuing without embeddings...")
+		auditLog.Warn("embedding generation failed", "error", err)
+		out.Printf("  ⚠️  Failed to generate embeddings: %v\n", err)
+		out.Println("  Continuing without embeddings...")
 	} else {
-		fmt.Printf("  ✅ Generated %d embeddings\n", len(embeddingIndex.Embeddings))
-		
+		out.Printf("  ✅ Indexed %d embeddings: %d generated, %d reused from the previous build, %d from the cache\n",
+			len(embeddingIndex.Embeddings), generator.Generated(), generator.Reused(), generator.Cached())
+		if failed := generator.Failed(); failed > 0 {
+			out.Printf("  ⚠️  Failed to embed %d function(s); they are left out of the index\n", failed)
+		}
+		if skipped := generator.Skipped(); skipped > 0 {
+			out.Printf("  ⏭️  Skipped %d function(s) shorter than %d lines\n", skipped, cfg.Embeddings.MinFunctionLines)
+		}
+
 		// Save embedding index
-		embeddingPath := filepath.Join(repo.RootPath, ".katich", "embeddings.json")
 		if err := generator.SaveIndex(embeddingIndex, embeddingPath); err != nil {
-			fmt.Printf("  ⚠️  Failed to save embeddings: %v\n", err)
+			out.Printf("  ⚠️  Failed to save embeddings: %v\n", err)
 		} else {
-			fmt.Printf("  💾 Saved to %s\n", embeddingPath)
+			out.Printf("  💾 Saved to %s\n", embeddingPath)
 		}
 	}
-	fmt.Println()
+	out.Println()
 
 	// Patterns
 	if len(result.Patterns) > 0 {
-		fmt.Println("Architectural patterns:")
+		out.Println("Architectural patterns:")
 		for _, pattern := range result.Patterns {
-			fmt.Printf("  • %s\n", pattern)
+			out.Printf("  • %s\n", pattern)
 		}
-		fmt.Println()
+		out.Println()
 	}
 
 	// Important files
 	if len(result.Files) > 0 {
-		fmt.Println("Configuration files found:")
+		out.Println("Configuration files found:")
 		for file := range result.Files {
-			fmt.Printf("  • %s\n", file)
+			out.Printf("  • %s\n", file)
 		}
-		fmt.Println()
+		out.Println()
+	}
+
+	// Record the commit the context was built from
+	commitHash := ""
+	if commit, err := repo.GetLatestCommit(); err == nil {
+		commitHash = commit.Hash
+	}
+
+	// Hash the analyzed files so the next build re-analyzes only changes
+	var fileHashes map[string]string
+	if changed != nil {
+		fileHashes = changed.hashes
+	} else if paths, err := analyzer.SourceFiles(); err == nil {
+		fileHashes = hashFiles(files, repo.RootPath, paths)
 	}
 
 	// Create combined context
 	combinedContext := map[string]interface{}{
-		"detection": result,
-		"analysis":  analysisResult,
+		"version":     contextSchemaVersion,
+		"commit":      commitHash,
+		"detection":   result,
+		"analysis":    analysisResult,
+		"file_hashes": fileHashes,
+		"settings":    settings,
 	}
 
 	// Save context
-	fmt.Println("💾 Saving context...")
+	out.Println("💾 Saving context...")
 	contextPath := filepath.Join(repo.RootPath, ".katich", "context.json")
 	
 	// Ensure directory exists
@@ -271,18 +280,32 @@ func runContextBuild() error {
 		return fmt.Errorf("failed to write context file: %w", err)
 	}
 
-	fmt.Printf("✅ Context saved to %s\n", contextPath)
-	fmt.Println()
-	fmt.Println("Next steps:")
-	fmt.Println("  • Run 'katich context show' to view the context")
-	fmt.Println("  • Run 'katich review latest' to review code with context")
+	out.Printf("✅ Context saved to %s\n", contextPath)
+	out.Println()
+
+	// Track debt markers across builds
+	if err := recordBuildHistory(repo, commitHash, analysisResult, cfg); err != nil {
+		out.Printf("⚠️  Failed to update history: %v\n", err)
+		out.Println()
+	}
+
+	// Keep the cache within its configured size
+	if removed, freed, err := cache.Evict(cache.Dir(repo.RootPath), cfg.Cache.MaxBytes()); err != nil {
+		out.Printf("⚠️  Failed to trim cache: %v\n", err)
+	} else if removed > 0 {
+		out.Printf("🧹 Evicted %d cache file(s) (%s)\n", removed, cache.FormatBytes(freed))
+		out.Println()
+	}
+	out.Println("Next steps:")
+	out.Println("  • Run 'katich context show' to view the context")
+	out.Println("  • Run 'katich review latest' to review code with context")
 
 	return nil
 }
 
 func runContextShow() error {
-	fmt.Println("📊 Codebase Context")
-	fmt.Println()
+	out.Println("📊 Codebase Context")
+	out.Println()
 
 	// Find Git repository
 	repo, err := git.FindRepository()
@@ -294,28 +317,38 @@ func runContextShow() error {
 	contextPath := filepath.Join(repo.RootPath, ".katich", "context.json")
 	data, err := os.ReadFile(contextPath)
 	if err != nil {
-		fmt.Println("⚠️  No context found. Run 'katich context build' first.")
+		out.Println("⚠️  No context found. Run 'katich context build' first.")
 		return nil
 	}
 
 	// Parse context
-	var result context.DetectionResult
-	if err := json.Unmarshal(data, &result); err != nil {
+	var stored struct {
+		Detection context.DetectionResult  `json:"detection"`
+		Analysis  *analysis.AnalysisResult `json:"analysis"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
 		return fmt.Errorf("failed to parse context: %w", err)
 	}
+	result := &stored.Detection
 
 	// Display languages
 	if len(result.Languages) > 0 {
-		fmt.Println("Languages:")
-		for lang, count := range result.Languages {
-			fmt.Printf("  • %s (%d files)\n", lang, count)
+		out.Println("Languages:")
+		printLanguages(result)
+		out.Println()
+
+		// Set expectations for languages without a dedicated parser
+		for _, lang := range analysis.BasicLanguages(result.Languages) {
+			out.Printf("  ℹ️  %s: %s\n", lang, analysis.CapabilitiesFor(lang).Describe())
+		}
+		if len(analysis.BasicLanguages(result.Languages)) > 0 {
+			out.Println()
 		}
-		fmt.Println()
 	}
 
 	// Display frameworks
 	if len(result.Frameworks) > 0 {
-		fmt.Println("Frameworks:")
+		out.Println("Frameworks:")
 		
 		// Group by type
 		byType := make(map[context.FrameworkType][]context.Framework)
@@ -329,46 +362,94 @@ func runContextShow() error {
 			context.FrameworkTypeFrontend,
 			context.FrameworkTypeFullStack,
 			context.FrameworkTypeUI,
+			context.FrameworkTypeORM,
+			context.FrameworkTypeTesting,
 			context.FrameworkTypeBuild,
 		}
 
 		for _, fwType := range typeOrder {
 			if frameworks, ok := byType[fwType]; ok && len(frameworks) > 0 {
-				fmt.Printf("\n  %s:\n", fwType)
+				out.Printf("\n  %s:\n", fwType)
 				for _, fw := range frameworks {
-					fmt.Printf("    • %s (%s)\n", fw.Name, fw.Language)
+					out.Printf("    • %s (%s)\n", fw.Name, fw.Language)
 				}
 			}
 		}
-		fmt.Println()
+		out.Println()
 	}
 
 	// Display patterns
 	if len(result.Patterns) > 0 {
-		fmt.Println("Architectural Patterns:")
+		out.Println("Architectural Patterns:")
 		for _, pattern := range result.Patterns {
-			fmt.Printf("  • %s\n", pattern)
+			out.Printf("  • %s\n", pattern)
 		}
-		fmt.Println()
+		out.Println()
 	}
 
 	// Display files
 	if len(result.Files) > 0 {
-		fmt.Println("Configuration Files:")
+		out.Println("Configuration Files:")
 		for file := range result.Files {
-			fmt.Printf("  • %s\n", file)
+			out.Printf("  • %s\n", file)
 		}
-		fmt.Println()
+		out.Println()
 	}
 
-	fmt.Printf("Context file: %s\n", contextPath)
+	if stored.Analysis != nil {
+		printMaintainability(stored.Analysis)
+	}
+
+	out.Printf("Context file: %s\n", contextPath)
 
 	return nil
 }
 
+// lowestMaintainability is how many of the least maintainable files
+// context show lists
+const lowestMaintainability = 5
+
+// printMaintainability prints the repository's maintainability index and
+// the files scoring lowest, which are the first to decay
+func printMaintainability(analysisResult *analysis.AnalysisResult) {
+	if analysisResult.TotalMetrics.MaintainabilityIndex <= 0 {
+		return
+	}
+
+	out.Println("Maintainability:")
+	out.Printf("  • Index: %.1f/100 (weighted by lines of code)\n", analysisResult.TotalMetrics.MaintainabilityIndex)
+	if analysisResult.TestMetrics.MaintainabilityIndex > 0 {
+		out.Printf("  • Production: %.1f, tests: %.1f\n",
+			analysisResult.ProductionMetrics.MaintainabilityIndex, analysisResult.TestMetrics.MaintainabilityIndex)
+	}
+
+	paths := make([]string, 0, len(analysisResult.Files))
+	for path, fileAnalysis := range analysisResult.Files {
+		if fileAnalysis.Metrics.HalsteadVolume > 0 {
+			paths = append(paths, path)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		a, b := analysisResult.Files[paths[i]].Metrics, analysisResult.Files[paths[j]].Metrics
+		if a.MaintainabilityIndex != b.MaintainabilityIndex {
+			return a.MaintainabilityIndex < b.MaintainabilityIndex
+		}
+		return paths[i] < paths[j]
+	})
+	if len(paths) > lowestMaintainability {
+		paths = paths[:lowestMaintainability]
+	}
+
+	out.Println("  Lowest:")
+	for _, path := range paths {
+		out.Printf("    %5.1f  %s\n", analysisResult.Files[path].Metrics.MaintainabilityIndex, path)
+	}
+	out.Println()
+}
+
 func runContextClear() error {
-	fmt.Println("🗑️  Clearing cached context...")
-	fmt.Println()
+	out.Println("🗑️  Clearing cached context...")
+	out.Println()
 
 	// Find Git repository
 	repo, err := git.FindRepository()
@@ -384,25 +465,310 @@ func runContextClear() error {
 		return fmt.Errorf("failed to remove context.json: %w", err)
 	}
 
-	// Remove embeddings.index if it exists
-	embeddingsPath := filepath.Join(katichDir, "embeddings.index")
-	if err := os.Remove(embeddingsPath); err != nil && !os.IsNotExist(err) {
-		// Not critical, just warn
-		fmt.Printf("⚠️  Could not remove embeddings.index: %v\n", err)
+	// Remove the embedding index, including the legacy JSON and
+	// embeddings.index names
+	for _, name := range []string{embeddings.IndexFile, embeddings.LegacyIndexFile, "embeddings.index"} {
+		if err := os.Remove(filepath.Join(katichDir, name)); err != nil && !os.IsNotExist(err) {
+			// Not critical, just warn
+			out.Printf("⚠️  Could not remove %s: %v\n", name, err)
+		}
 	}
 
-	// Remove cache directory if it exists
-	cachePath := filepath.Join(katichDir, "cache")
-	if err := os.RemoveAll(cachePath); err != nil && !os.IsNotExist(err) {
-		fmt.Printf("⚠️  Could not remove cache directory: %v\n", err)
+	// Remove cache directory, including hit statistics
+	if err := cache.Clear(cache.Dir(repo.RootPath)); err != nil {
+		out.Printf("⚠️  Could not remove cache directory: %v\n", err)
 	}
 
-	fmt.Println("✅ Context cleared successfully")
-	fmt.Println()
-	fmt.Println("Removed:")
-	fmt.Println("  • context.json")
-	fmt.Println("  • embeddings.index (if present)")
-	fmt.Println("  • cache/ (if present)")
+	out.Println("✅ Context cleared successfully")
+	out.Println()
+	out.Println("Removed:")
+	out.Println("  • context.json")
+	out.Println("  • embeddings.bin (if present)")
+	out.Println("  • cache/ (if present)")
 
 	return nil
 }
+
+// recordBuildHistory appends this build's metrics to the history file and
+// reports how the number of debt markers changed since the previous build
+func recordBuildHistory(repo *git.Repository, commitHash string, analysisResult *analysis.AnalysisResult, cfg *config.Config) error {
+	historyPath := history.DefaultPath(repo.RootPath)
+	buildHistory, err := history.Load(historyPath)
+	if err != nil {
+		return err
+	}
+
+	entry := history.Entry{
+		Timestamp:   time.Now(),
+		Commit:      commitHash,
+		Files:       len(analysisResult.Files),
+		LinesOfCode: analysisResult.TotalMetrics.LinesOfCode,
+		Issues:      analysisResult.IssuesSummary.TotalIssues,
+		DebtMarkers: analysisResult.TotalMetrics.DebtMarkers,
+	}
+
+	if previous := buildHistory.Latest(); previous != nil {
+		delta := entry.DebtMarkers - previous.DebtMarkers
+		out.Printf("📈 %s since last build (%d total)\n", formatDebtDelta(delta), entry.DebtMarkers)
+		if debtGrowthExceeded(delta, cfg.Analysis.MaxDebtGrowth) {
+			out.Printf("⚠️  Technical debt grew by more than max_debt_growth (%d)\n", cfg.Analysis.MaxDebtGrowth)
+		}
+		out.Println()
+	}
+
+	buildHistory.Add(entry)
+	return buildHistory.Save(historyPath)
+}
+
+// legacyEmbeddingModels are model names earlier versions wrote to
+// embeddings.model without sending them to any provider. They select the
+// provider's default model, which is what those versions used.
+var legacyEmbeddingModels = map[string]bool{
+	"jina-code-v2":     true,
+	"bge-code":         true,
+	"nomic-embed":      true,
+	"snowflake-arctic": true,
+}
+
+// embeddingModel returns the configured embedding model, or "" for the
+// provider's default
+func embeddingModel(cfg *config.Config) string {
+	if legacyEmbeddingModels[cfg.Embeddings.Model] {
+		return ""
+	}
+	return cfg.Embeddings.Model
+}
+
+// newEmbeddingProvider creates the embedding provider selected by the
+// embeddings config, with its requests recorded in the audit log. With
+// provider local, Ollama at base_url is used and OpenAI's default model is
+// the fallback when Ollama is down; with api, only OpenAI is used.
+func newEmbeddingProvider(cfg *config.Config) loggedProvider {
+	if cfg.Embeddings.Provider == "api" {
+		return loggedProvider{embeddings.NewAPIProvider(openAIEmbeddingsKey(cfg), embeddingModel(cfg))}
+	}
+	return loggedProvider{embeddings.NewHybridProvider(
+		cfg.Embeddings.BaseURL,
+		embeddingModel(cfg),
+		openAIEmbeddingsKey(cfg),
+		"",
+	)}
+}
+
+// openAIEmbeddingsKey returns the key for the OpenAI embeddings fallback:
+// embeddings.api_key (or its file, keyring or OPENAI_API_KEY), else the LLM
+// key when the LLM provider is OpenAI. Any other LLM key belongs to another
+// service and is never sent to OpenAI. Empty disables the fallback.
+func openAIEmbeddingsKey(cfg *config.Config) string {
+	if cfg.Embeddings.APIKey != "" {
+		return cfg.Embeddings.APIKey
+	}
+	if cfg.LLM.Provider == "openai" {
+		return cfg.LLM.APIKey
+	}
+	return ""
+}
+
+// printLanguages lists the detected languages by lines of code, with their
+// file counts. Contexts built before lines were counted list files only.
+func printLanguages(result *context.DetectionResult) {
+	languages := make([]context.Language, 0, len(result.Languages))
+	for lang := range result.Languages {
+		languages = append(languages, lang)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		a, b := languages[i], languages[j]
+		if result.LanguageLines[a] != result.LanguageLines[b] {
+			return result.LanguageLines[a] > result.LanguageLines[b]
+		}
+		if result.Languages[a] != result.Languages[b] {
+			return result.Languages[a] > result.Languages[b]
+		}
+		return a < b
+	})
+
+	for _, lang := range languages {
+		if lines, ok := result.LanguageLines[lang]; ok {
+			out.Printf("  • %s (%d lines in %d file(s))\n", lang, lines, result.Languages[lang])
+		} else {
+			out.Printf("  • %s (%d file(s))\n", lang, result.Languages[lang])
+		}
+	}
+}
+
+// printDetectionResult prints detected languages and frameworks
+func printDetectionResult(result *context.DetectionResult) {
+	// Languages
+	if len(result.Languages) > 0 {
+		out.Println("Languages detected:")
+		printLanguages(result)
+		out.Println()
+
+		// Set expectations for languages without a dedicated parser
+		for _, lang := range analysis.BasicLanguages(result.Languages) {
+			out.Printf("  ℹ️  %s: %s\n", lang, analysis.CapabilitiesFor(lang).Describe())
+		}
+		if len(analysis.BasicLanguages(result.Languages)) > 0 {
+			out.Println()
+		}
+	}
+
+	// Frameworks
+	if len(result.Frameworks) > 0 {
+		out.Println("Frameworks detected:")
+		
+		// Group by type
+		byType := make(map[context.FrameworkType][]context.Framework)
+		for _, fw := range result.Frameworks {
+			byType[fw.Type] = append(byType[fw.Type], fw)
+		}
+
+		// Display by type
+		typeOrder := []context.FrameworkType{
+			context.FrameworkTypeBackend,
+			context.FrameworkTypeFrontend,
+			context.FrameworkTypeFullStack,
+			context.FrameworkTypeUI,
+			context.FrameworkTypeORM,
+			context.FrameworkTypeTesting,
+			context.FrameworkTypeBuild,
+		}
+
+		for _, fwType := range typeOrder {
+			if frameworks, ok := byType[fwType]; ok && len(frameworks) > 0 {
+				out.Printf("\n  %s:\n", fwType)
+				for _, fw := range frameworks {
+					out.Printf("    • %s (%s)\n", fw.Name, fw.Language)
+				}
+			}
+		}
+		out.Println()
+	}
+
+	// Modules, listed only for monorepos
+	if len(result.Modules) > 1 {
+		out.Println("Modules detected:")
+		for _, module := range result.Modules {
+			details := make([]string, 0)
+			languages := make([]string, 0, len(module.Languages))
+			for lang := range module.Languages {
+				languages = append(languages, string(lang))
+			}
+			sort.Strings(languages)
+			details = append(details, languages...)
+			for _, fw := range module.Frameworks {
+				details = append(details, fw.Name)
+			}
+			out.Printf("  • %s (%s)", module.Path, strings.Join(module.Manifests, ", "))
+			if len(details) > 0 {
+				out.Printf(": %s", strings.Join(details, ", "))
+			}
+			out.Println()
+		}
+		out.Println()
+	}
+}
+
+// printAnalysisResult prints code metrics, issues and the most complex functions
+func printAnalysisResult(analysisResult *analysis.AnalysisResult) {
+	// Code Metrics
+	out.Println("Code Metrics:")
+	out.Printf("  • Total Lines of Code: %d\n", analysisResult.TotalMetrics.LinesOfCode)
+	out.Printf("  • Total Functions: %d\n", analysisResult.TotalMetrics.FunctionCount)
+	out.Printf("  • Total Classes/Structs: %d\n", analysisResult.TotalMetrics.ClassCount)
+	out.Printf("  • Average Function Length: %.1f lines\n", analysisResult.TotalMetrics.AvgFunctionLength)
+	out.Printf("  • Max Function Length: %d lines\n", analysisResult.TotalMetrics.MaxFunctionLength)
+	out.Printf("  • Total Complexity: %d\n", analysisResult.TotalMetrics.CyclomaticComplexity)
+	if test := analysisResult.TestMetrics; test.LinesOfCode > 0 {
+		production := analysisResult.ProductionMetrics
+		out.Printf("  • Production: %d lines, %d function(s), complexity %d, average function %.1f lines\n",
+			production.LinesOfCode, production.FunctionCount, production.CyclomaticComplexity, production.AvgFunctionLength)
+		out.Printf("  • Tests: %d lines, %d function(s), complexity %d, average function %.1f lines\n",
+			test.LinesOfCode, test.FunctionCount, test.CyclomaticComplexity, test.AvgFunctionLength)
+	}
+	out.Println()
+
+	// Issues Summary
+	if analysisResult.IssuesSummary.TotalIssues > 0 {
+		out.Println("Issues Found:")
+		out.Printf("  • Total: %d\n", analysisResult.IssuesSummary.TotalIssues)
+		
+		if len(analysisResult.IssuesSummary.BySeverity) > 0 {
+			out.Println("  By Severity:")
+			for severity, count := range analysisResult.IssuesSummary.BySeverity {
+				out.Printf("    - %s: %d\n", severity, count)
+			}
+		}
+		out.Println()
+	}
+
+	// Files with the most issues
+	if len(analysisResult.WorstFiles) > 0 {
+		out.Println("Files With Most Issues:")
+		for i, file := range analysisResult.WorstFiles {
+			if i >= 5 {
+				break
+			}
+			out.Printf("  %d. %s (%d issues, score %d)\n", i+1, file.File, file.Issues, file.Score)
+		}
+		out.Println()
+	}
+
+	// Top Complex Functions
+	if len(analysisResult.TopComplexity) > 0 {
+		out.Println("Most Complex Functions:")
+		for i, fn := range analysisResult.TopComplexity {
+			if i >= 5 {
+				break
+			}
+			if fn.CognitiveComplexity > 0 {
+				out.Printf("  %d. %s (complexity: %d, cognitive: %d, %d lines)\n", i+1, fn.Name, fn.Complexity, fn.CognitiveComplexity, fn.LOC)
+			} else {
+				out.Printf("  %d. %s (complexity: %d, %d lines)\n", i+1, fn.Name, fn.Complexity, fn.LOC)
+			}
+		}
+		out.Println()
+	}
+
+	// Duplicate type definitions
+	if len(analysisResult.DuplicateTypes) > 0 {
+		out.Println("Duplicate Type Definitions (consider consolidating):")
+		for _, dup := range analysisResult.DuplicateTypes {
+			names := make([]string, 0, len(dup.Locations))
+			for _, loc := range dup.Locations {
+				names = append(names, fmt.Sprintf("%s (%s:%d)", loc.Name, loc.File, loc.Line))
+			}
+			out.Printf("  • %d fields: %s\n", dup.Fields, strings.Join(names, ", "))
+		}
+		out.Println()
+	}
+
+	if len(analysisResult.DuplicateData) > 0 {
+		out.Println("Duplicate Data Literals (consider consolidating):")
+		for _, dup := range analysisResult.DuplicateData {
+			locations := make([]string, 0, len(dup.Locations))
+			for _, loc := range dup.Locations {
+				location := fmt.Sprintf("%s:%d", loc.File, loc.Line)
+				if loc.Name != "" {
+					location = fmt.Sprintf("%s (%s)", loc.Name, location)
+				}
+				locations = append(locations, location)
+			}
+			out.Printf("  • %s, %d elements: %s\n", dup.Type, dup.Elements, strings.Join(locations, ", "))
+		}
+		out.Println()
+	}
+
+	if len(analysisResult.DuplicateCode) > 0 {
+		out.Println("Duplicate Code Blocks (consider extracting):")
+		for i, dup := range analysisResult.DuplicateCode {
+			if i >= 10 {
+				out.Printf("  … and %d more\n", len(analysisResult.DuplicateCode)-i)
+				break
+			}
+			out.Printf("  • %d lines, %.0f%% identical: %s:%d-%d, %s:%d-%d\n", dup.Lines, dup.Similarity*100,
+				dup.File1, dup.StartLine1, dup.EndLine1, dup.File2, dup.StartLine2, dup.EndLine2)
+		}
+		out.Println()
+	}
+}
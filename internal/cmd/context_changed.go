@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/context"
+
+	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/embeddings"
+	"github.com/katichai/katich/internal/git"
+)
+
+// changedBuild is the part of the repository re-analyzed by an incremental
+// build or `context build --changed-only`
+type changedBuild struct {
+	files    map[string]*analysis.FileAnalysis // re-analyzed files by path
+	replaced []string                          // paths whose previous data is discarded
+	hashes   map[string]string                 // content hashes of every analyzed file, saved for the next build
+}
+
+// previousBuild is the part of context.json a later build starts from
+type previousBuild struct {
+	Version    string                   `json:"version"`
+	Analysis   *analysis.AnalysisResult `json:"analysis"`
+	FileHashes map[string]string        `json:"file_hashes"`
+	Settings   string                   `json:"settings"`
+}
+
+// analyzeIncremental re-analyzes the directories holding source files
+// whose content differs from the hashes saved by the last build, or that
+// were removed since, and merges them into its analysis. It returns a nil result when the last build cannot be updated,
+// e.g. it was made with other analysis settings, and every file has to be
+// analyzed.
+func analyzeIncremental(repo *git.Repository, analyzer *analysis.Analyzer, files *context.FileCache, settings string) (*analysis.AnalysisResult, *changedBuild, error) {
+	stored, err := loadPreviousBuild(repo)
+	if err != nil || stored.FileHashes == nil {
+		return nil, nil, nil
+	}
+	if stored.Settings != settings {
+		out.Println("  Analysis settings changed since the last build, analyzing every file")
+		return nil, nil, nil
+	}
+	if _, err := os.Stat(embeddings.IndexPath(filepath.Join(repo.RootPath, ".katich"))); err != nil {
+		return nil, nil, nil
+	}
+
+	paths, err := analyzer.SourceFiles()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list source files: %w", err)
+	}
+	current := hashFiles(files, repo.RootPath, paths)
+
+	build := &changedBuild{replaced: make([]string, 0), hashes: current}
+	modified := make([]string, 0)
+	for path, hash := range current {
+		if stored.FileHashes[path] != hash {
+			modified = append(modified, path)
+			build.replaced = append(build.replaced, path)
+		}
+	}
+	removed := 0
+	for path := range stored.FileHashes {
+		if _, ok := current[path]; !ok {
+			build.replaced = append(build.replaced, path)
+			removed++
+		}
+	}
+	changedCount := len(modified)
+
+	// Checks such as missing_test look at the other files of a package, so
+	// a change anywhere in a directory re-analyzes all of it
+	dirs := make(map[string]bool)
+	for _, path := range build.replaced {
+		dirs[filepath.Dir(path)] = true
+	}
+	for path, hash := range current {
+		if dirs[filepath.Dir(path)] && stored.FileHashes[path] == hash {
+			modified = append(modified, path)
+			build.replaced = append(build.replaced, path)
+		}
+	}
+
+	build.files, err = analyzer.AnalyzeChangedFiles(modified)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to analyze changed files: %w", err)
+	}
+
+	out.Printf("  %d file(s) changed and %d removed since the last build, %d re-analyzed (--force analyzes every file)\n", changedCount, removed, len(build.files))
+	return analyzer.MergeFiles(stored.Analysis, build.files, build.replaced), build, nil
+}
+
+// analyzeChangedOnly re-analyzes the files changed in rangeSpec and merges
+// them into the analysis stored in context.json
+func analyzeChangedOnly(repo *git.Repository, analyzer *analysis.Analyzer, files *context.FileCache, rangeSpec string) (*analysis.AnalysisResult, *changedBuild, error) {
+	stored, err := loadPreviousBuild(repo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diff, err := repo.GetDiffRange(rangeSpec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get diff for %s: %w", rangeSpec, err)
+	}
+
+	build := &changedBuild{replaced: make([]string, 0, len(diff.Files))}
+	present := make([]string, 0, len(diff.Files))
+	for _, file := range diff.Files {
+		build.replaced = append(build.replaced, file.Path)
+		if file.OldPath != "" {
+			build.replaced = append(build.replaced, file.OldPath)
+		}
+		if file.Status != "D" && !file.IsBinary {
+			present = append(present, file.Path)
+		}
+	}
+
+	build.files, err = analyzer.AnalyzeChangedFiles(present)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to analyze changed files: %w", err)
+	}
+
+	// Files outside the range keep the hashes they were analyzed with
+	build.hashes = make(map[string]string, len(stored.FileHashes))
+	for path, hash := range stored.FileHashes {
+		build.hashes[path] = hash
+	}
+	for _, path := range build.replaced {
+		delete(build.hashes, path)
+	}
+	for path, hash := range hashFiles(files, repo.RootPath, present) {
+		if _, ok := build.files[path]; ok {
+			build.hashes[path] = hash
+		}
+	}
+
+	out.Printf("  %d file(s) changed in %s, %d re-analyzed\n", len(diff.Files), rangeSpec, len(build.files))
+	return analyzer.MergeFiles(stored.Analysis, build.files, build.replaced), build, nil
+}
+
+// loadPreviousBuild reads the analysis saved by the last context build
+func loadPreviousBuild(repo *git.Repository) (*previousBuild, error) {
+	data, err := os.ReadFile(filepath.Join(repo.RootPath, ".katich", "context.json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no context found to update: run 'katich context build' without --changed-only first")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context file: %w", err)
+	}
+
+	var stored previousBuild
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse context file: %w", err)
+	}
+	if stored.Version != contextSchemaVersion || stored.Analysis == nil || stored.Analysis.Files == nil {
+		return nil, fmt.Errorf("stored context is outdated: run 'katich context build --force'")
+	}
+
+	return &stored, nil
+}
+
+// hashFiles returns the content hash of each readable file, by path
+// relative to root
+func hashFiles(files *context.FileCache, root string, paths []string) map[string]string {
+	hashes := make(map[string]string, len(paths))
+	for _, path := range paths {
+		content, err := files.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			continue
+		}
+		hashes[path] = fmt.Sprintf("%x", sha256.Sum256(content))
+	}
+	return hashes
+}
+
+// analysisSettings fingerprints the settings that shape an analysis, so an
+// incremental build does not keep results computed under other thresholds
+func analysisSettings(cfg config.AnalysisConfig) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// generateChangedEmbeddings embeds only the re-analyzed files and merges
+// them into the saved index
+func generateChangedEmbeddings(generator *embeddings.Generator, build *changedBuild, indexPath string) (*embeddings.EmbeddingIndex, error) {
+	base, err := embeddings.LoadIndex(embeddings.IndexPath(filepath.Dir(indexPath)))
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := generator.GenerateForAnalysis(&analysis.AnalysisResult{Files: build.files})
+	if err != nil {
+		return nil, err
+	}
+
+	return embeddings.MergeIndex(base, update, build.replaced)
+}
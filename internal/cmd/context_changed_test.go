@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/context"
+	"github.com/katichai/katich/internal/embeddings"
+	"github.com/katichai/katich/internal/git"
+)
+
+// saveBuild analyzes the repository at root and stores it as the last
+// context build, with an empty embedding index beside it
+func saveBuild(t *testing.T, root string, cfg config.AnalysisConfig) {
+	t.Helper()
+	analyzer := analysis.NewAnalyzer(root, cfg)
+	result, err := analyzer.AnalyzeRepository()
+	if err != nil {
+		t.Fatalf("AnalyzeRepository: %v", err)
+	}
+	if !hasMissingTest(result.Files["foo.go"]) {
+		t.Fatal("foo.go has no missing_test issue to start from")
+	}
+	paths, err := analyzer.SourceFiles()
+	if err != nil {
+		t.Fatalf("SourceFiles: %v", err)
+	}
+
+	data, err := json.Marshal(previousBuild{
+		Version:    contextSchemaVersion,
+		Analysis:   result,
+		FileHashes: hashFiles(context.NewFileCache(), root, paths),
+		Settings:   analysisSettings(cfg),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	katichDir := filepath.Join(root, ".katich")
+	if err := os.MkdirAll(katichDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(katichDir, "context.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(katichDir, embeddings.IndexFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Adding a test file leaves the tested file's content unchanged, but its
+// missing_test issue has to go
+func TestAnalyzeIncrementalReanalyzesPackage(t *testing.T) {
+	out = &printer{w: io.Discard}
+	t.Cleanup(func() { out = &printer{w: os.Stdout} })
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "foo.go"), []byte("package foo\n\nfunc Foo() int {\n\treturn 1\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig().Analysis
+	cfg.EnabledChecks = []string{string(analysis.IssueTypeMissingTest)}
+	saveBuild(t, root, cfg)
+
+	test := "package foo\n\nimport \"testing\"\n\nfunc TestFoo(t *testing.T) {\n\tFoo()\n}\n"
+	if err := os.WriteFile(filepath.Join(root, "foo_test.go"), []byte(test), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &git.Repository{RootPath: root}
+	result, _, err := analyzeIncremental(repo, analysis.NewAnalyzer(root, cfg), context.NewFileCache(), analysisSettings(cfg))
+	if err != nil {
+		t.Fatalf("analyzeIncremental: %v", err)
+	}
+	if result == nil {
+		t.Fatal("analyzeIncremental fell back to a full build")
+	}
+
+	foo, ok := result.Files["foo.go"]
+	if !ok {
+		t.Fatalf("foo.go missing from the merged analysis: %v", result.Files)
+	}
+	if hasMissingTest(foo) {
+		t.Error("stale missing_test issue kept for foo.go")
+	}
+}
+
+func hasMissingTest(fileAnalysis *analysis.FileAnalysis) bool {
+	if fileAnalysis == nil {
+		return false
+	}
+	for _, issue := range fileAnalysis.Issues {
+		if issue.Type == analysis.IssueTypeMissingTest {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/katichai/katich/internal/embeddings"
+	"github.com/katichai/katich/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Context reindex flags
+	exportJSON string
+)
+
+func init() {
+	contextReindexCmd.Flags().StringVar(&exportJSON, "export-json", "", "also write the index as JSON to this file, for other tools")
+}
+
+// contextReindexCmd rebuilds the similarity index from stored vectors
+var contextReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the similarity index from existing embeddings",
+	Long: `Rewrite .katich/embeddings.bin in the current index format using the
+vectors it already holds, without calling the embedding provider. A JSON
+index left by an earlier version is converted to the binary format.
+
+Entries with malformed vectors or for deleted files are dropped. Use this
+after upgrading katich or when 'katich context validate' reports an
+outdated index; run 'katich context build --force' to regenerate vectors.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runContextReindex()
+	},
+}
+
+func runContextReindex() error {
+	out.Println("🗂️  Rebuilding similarity index...")
+	out.Println()
+
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	katichDir := filepath.Join(repo.RootPath, ".katich")
+	sourcePath := embeddings.IndexPath(katichDir)
+	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
+		return fmt.Errorf("no embedding index found: run 'katich context build' first")
+	}
+
+	index, err := embeddings.LoadIndex(sourcePath)
+	if err != nil {
+		return fmt.Errorf("embedding index is unreadable, run 'katich context build --force': %w", err)
+	}
+
+	rebuilt, stats := embeddings.RebuildIndex(index, func(filePath string) bool {
+		_, err := os.Stat(filepath.Join(repo.RootPath, filePath))
+		return err == nil
+	})
+
+	indexPath := filepath.Join(katichDir, embeddings.IndexFile)
+	if err := embeddings.SaveIndexBinary(rebuilt, indexPath); err != nil {
+		return fmt.Errorf("failed to save embeddings: %w", err)
+	}
+	if exportJSON != "" {
+		if err := embeddings.WriteIndex(rebuilt, exportJSON); err != nil {
+			return fmt.Errorf("failed to export embeddings: %w", err)
+		}
+	}
+
+	out.Printf("  ✅ Reindexed %d embedding(s) (%s, dimension %d, version %s)\n",
+		stats.Kept, rebuilt.Provider, rebuilt.Dimension, rebuilt.Version)
+	if stats.Malformed > 0 {
+		out.Printf("  ⚠️  Dropped %d malformed vector(s)\n", stats.Malformed)
+	}
+	if stats.Missing > 0 {
+		out.Printf("  🗑️  Dropped %d embedding(s) of deleted files\n", stats.Missing)
+	}
+	if stats.Rehashed > 0 {
+		out.Printf("  🔑 Added content hashes to %d embedding(s)\n", stats.Rehashed)
+	}
+	out.Printf("  💾 Saved to %s\n", indexPath)
+	if exportJSON != "" {
+		out.Printf("  📤 Exported JSON to %s\n", exportJSON)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/embeddings"
+	"github.com/katichai/katich/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// contextValidateCmd checks the cached context for staleness
+var contextValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check that the cached context and embeddings are consistent",
+	Long: `Validate .katich/context.json and .katich/embeddings.bin against the
+repository and configuration. Checks that the context was built from HEAD,
+that schema versions are current, that every embedded file still exists and
+that the index dimension matches the active embedding provider.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runContextValidate()
+	},
+}
+
+// storedContext is the subset of context.json needed for validation
+type storedContext struct {
+	Version  string `json:"version"`
+	Commit   string `json:"commit"`
+	Analysis struct {
+		Files map[string]json.RawMessage `json:"files"`
+	} `json:"analysis"`
+}
+
+func runContextValidate() error {
+	out.Println("🩺 Validating codebase context...")
+	out.Println()
+
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	katichDir := filepath.Join(repo.RootPath, ".katich")
+	problems := validateContextFile(repo, filepath.Join(katichDir, "context.json"))
+	problems = append(problems, validateEmbeddingIndex(repo, cfg, embeddings.IndexPath(katichDir))...)
+
+	if len(problems) == 0 {
+		out.Println("✅ Context is consistent with the repository")
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintf(stdout, "⚠️  %s\n", problem)
+	}
+	out.Println()
+
+	return fmt.Errorf("context validation found %d problem(s)", len(problems))
+}
+
+// validateContextFile checks the schema version and build commit of context.json
+func validateContextFile(repo *git.Repository, contextPath string) []string {
+	data, err := os.ReadFile(contextPath)
+	if err != nil {
+		return []string{"no context found: run `katich context build`"}
+	}
+
+	var stored storedContext
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return []string{fmt.Sprintf("context.json is unreadable (%v): run `katich context build --force`", err)}
+	}
+
+	problems := make([]string, 0)
+
+	if stored.Version != contextSchemaVersion {
+		problems = append(problems, fmt.Sprintf("context.json schema version %q is not current (%s): run `katich context build --force`",
+			stored.Version, contextSchemaVersion))
+	}
+
+	if head, err := repo.GetLatestCommit(); err == nil && stored.Commit != head.Hash {
+		built := stored.Commit
+		if built == "" {
+			built = "unknown"
+		} else if len(built) > 7 {
+			built = built[:7]
+		}
+		problems = append(problems, fmt.Sprintf("context was built at commit %s but HEAD is %s: run `katich context build --incremental`",
+			built, head.ShortHash))
+	}
+
+	missing := 0
+	for path := range stored.Analysis.Files {
+		if _, err := os.Stat(filepath.Join(repo.RootPath, path)); os.IsNotExist(err) {
+			missing++
+		}
+	}
+	if missing > 0 {
+		problems = append(problems, fmt.Sprintf("%d analyzed file(s) no longer exist: run `katich context build --incremental`", missing))
+	}
+
+	return problems
+}
+
+// validateEmbeddingIndex checks the index version, its vectors and the
+// files they were generated from
+func validateEmbeddingIndex(repo *git.Repository, cfg *config.Config, indexPath string) []string {
+	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+		return []string{"no embedding index found: run `katich context build`"}
+	}
+
+	index, err := embeddings.LoadIndex(indexPath)
+	if err != nil {
+		return []string{fmt.Sprintf("embedding index is unreadable (%v): run `katich context build --force`", err)}
+	}
+
+	problems := make([]string, 0)
+
+	if index.Version != embeddings.IndexVersion {
+		problems = append(problems, fmt.Sprintf("embedding index version %q is not current (%s): run `katich context reindex`",
+			index.Version, embeddings.IndexVersion))
+	}
+
+	provider := newEmbeddingProvider(cfg)
+	if provider.GetName() != "None" && provider.GetDimension() != index.Dimension {
+		problems = append(problems, fmt.Sprintf("embedding index has dimension %d (%s) but the active provider %s uses %d: run `katich context build --force`",
+			index.Dimension, index.Provider, provider.GetName(), provider.GetDimension()))
+	}
+
+	missing := make(map[string]bool)
+	malformed := 0
+	for _, emb := range index.Embeddings {
+		if len(emb.Embedding) != index.Dimension {
+			malformed++
+		}
+		if _, err := os.Stat(filepath.Join(repo.RootPath, emb.FilePath)); os.IsNotExist(err) {
+			missing[emb.FilePath] = true
+		}
+	}
+	if malformed > 0 {
+		problems = append(problems, fmt.Sprintf("%d embedding(s) do not match the index dimension %d: run `katich context reindex`",
+			malformed, index.Dimension))
+	}
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("embeddings reference %d file(s) that no longer exist: run `katich context build --incremental`", len(missing)))
+	}
+
+	return problems
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/embeddings"
+	"github.com/katichai/katich/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Duplicates flags
+	duplicatesThreshold float64
+	duplicatesMinLines  int
+	duplicatesSearch    string
+	duplicatesProbes    int
+)
+
+func init() {
+	duplicatesCmd.Flags().Float64Var(&duplicatesThreshold, "threshold", 0, "minimum similarity (0-1) of duplicates (default: analysis.similarity_threshold)")
+	duplicatesCmd.Flags().IntVar(&duplicatesMinLines, "min-lines", 3, "ignore functions shorter than this many lines")
+	duplicatesCmd.Flags().StringVar(&duplicatesSearch, "search", "auto", "neighbor search: exact, approximate, or auto (approximate from 10000 functions)")
+	duplicatesCmd.Flags().IntVar(&duplicatesProbes, "probes", 0, "index lists searched per function in approximate search; more raise recall and cost (0 for the default)")
+}
+
+// duplicatesCmd lists clusters of similar functions from the embedding index
+var duplicatesCmd = &cobra.Command{
+	Use:   "duplicates",
+	Short: "Find near-identical functions across files",
+	Long: `Compare every function in the embedding index with the others and list
+clusters of near-identical functions in different files, to find code
+that was copied rather than shared.
+
+Requires an index built by 'katich context build'.
+
+Examples:
+  katich duplicates
+  katich duplicates --threshold 0.95 --min-lines 10
+  katich duplicates --search exact`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDuplicates()
+	},
+}
+
+func runDuplicates() error {
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	threshold := cfg.Analysis.SimilarityThreshold
+	if duplicatesThreshold != 0 {
+		threshold = duplicatesThreshold
+	}
+	if threshold <= 0 || threshold > 1 {
+		return fmt.Errorf("invalid --threshold %.2f: must be between 0 and 1", threshold)
+	}
+
+	mode, err := embeddings.ParseSearchMode(duplicatesSearch)
+	if err != nil {
+		return err
+	}
+	if duplicatesProbes < 0 {
+		return fmt.Errorf("invalid --probes %d: must not be negative", duplicatesProbes)
+	}
+
+	index, err := embeddings.LoadIndex(embeddings.IndexPath(filepath.Join(repo.RootPath, ".katich")))
+	if err != nil {
+		return fmt.Errorf("no embedding index found, run 'katich context build' first: %w", err)
+	}
+
+	out.Printf("🔁 Comparing %d functions (similarity ≥ %.3f)...\n", len(index.Embeddings), threshold)
+	out.Println()
+
+	// Stored vectors are compared with each other, so no provider is needed
+	detector := embeddings.NewDuplicateDetector(index, nil, float32(threshold))
+	detector.SetSearchMode(mode, duplicatesProbes)
+	if verbose && detector.Approximate() {
+		out.Printf("Approximate search recall: %.0f%% of the top 10 (sampled; raise --probes or use --search exact for more)\n", detector.SampleRecall(50, 10)*100)
+		out.Println()
+	}
+	clusters := detector.FindClusters(duplicatesMinLines)
+	if len(clusters) == 0 {
+		out.Println("✅ No duplicate functions found")
+		return nil
+	}
+
+	for i, cluster := range clusters {
+		fmt.Fprintf(stdout, "Cluster %d: %d functions, similarity ≥ %.3f (%s)\n",
+			i+1, len(cluster.Functions), cluster.Similarity, embeddings.GetSimilarityLevel(cluster.Similarity))
+		for _, fn := range cluster.Functions {
+			fmt.Fprintf(stdout, "  • %s (%s:%d-%d)\n", fn.FuncName, fn.FilePath, fn.StartLine, fn.EndLine)
+		}
+		fmt.Fprintln(stdout)
+	}
+
+	out.Printf("%d cluster(s) of duplicate functions\n", len(clusters))
+	return nil
+}
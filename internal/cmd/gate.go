@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/review"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Warning gate flags, shared by review and metrics
+	strictMode  bool
+	maxWarnings int
+)
+
+// validateFailOn checks --fail-on before the review runs. It only applies
+// to --ci runs, so setting it without --ci is an error rather than a
+// silently ignored gate.
+func validateFailOn() error {
+	if failOn == "" {
+		return nil
+	}
+	if !ciMode {
+		return fmt.Errorf("--fail-on only applies with --ci")
+	}
+	switch failOn {
+	case "info", "warning", "error":
+		return nil
+	}
+	return fmt.Errorf("invalid --fail-on severity %q (use info, warning or error)", failOn)
+}
+
+// addWarningGateFlags registers --strict and --max-warnings on a command
+// and its subcommands
+func addWarningGateFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.BoolVar(&strictMode, "strict", false, "treat warnings as errors: fail if any warning or error is found")
+	flags.IntVar(&maxWarnings, "max-warnings", -1, "fail if more than this many warnings are found (-1 disables)")
+}
+
+// enforceWarningLimit fails the run when --strict or --max-warnings is
+// exceeded. counts must come from issues that already passed --only/--skip.
+func enforceWarningLimit(counts map[analysis.Severity]int) error {
+	warnings := counts[analysis.SeverityWarning]
+	errors := counts[analysis.SeverityError]
+
+	if strictMode && warnings+errors > 0 {
+		return fmt.Errorf("strict mode: found %d warning(s) and %d error(s)", warnings, errors)
+	}
+	if maxWarnings >= 0 && warnings > maxWarnings {
+		return fmt.Errorf("found %d warning(s), more than the %d allowed by --max-warnings", warnings, maxWarnings)
+	}
+	return nil
+}
+
+// enforceFailOn fails a --ci run when any finding reaches the --fail-on
+// severity, or analysis.fail_on when the flag is not set
+func enforceFailOn(result *review.Result, cfg *config.Config) error {
+	if !ciMode {
+		return nil
+	}
+
+	threshold := failOn
+	if threshold == "" {
+		threshold = cfg.Analysis.FailOn
+	}
+
+	failing := result.CountAtOrAbove(analysis.Severity(threshold))
+	if failing == 0 {
+		return nil
+	}
+	counts := result.CountBySeverity()
+	return fmt.Errorf("CI check failed: %d issue(s) at or above %s (%d error, %d warning, %d info)",
+		failing, threshold, counts[analysis.SeverityError], counts[analysis.SeverityWarning], counts[analysis.SeverityInfo])
+}
+
+// enforceCriticalPaths fails a --ci run when a file under a critical path
+// has a finding at or above its fail_on severity, whatever the overall score
+func enforceCriticalPaths(result *review.Result) error {
+	if !ciMode {
+		return nil
+	}
+
+	failures := result.CriticalFailures()
+	if len(failures) == 0 {
+		return nil
+	}
+
+	out.Println("🚨 Issues in critical paths:")
+	for _, failure := range failures {
+		out.Printf("  %s\n", failure)
+	}
+	return fmt.Errorf("found %d issue(s) in critical paths", len(failures))
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies pre-commit hooks written by katich
+const hookMarker = "# Installed by katich init"
+
+// preCommitHook reviews staged and unstaged changes before each commit
+const preCommitHook = `#!/bin/sh
+` + hookMarker + `
+exec katich review staged --summary
+`
+
+var (
+	// assumeYes answers every init prompt with yes
+	assumeYes bool
+
+	// Config scaffolding flags
+	initLLMProvider        string
+	initEmbeddingsProvider string
+	initForce              bool
+)
+
+// llmKeyEnvVars are the environment variables Load reads an LLM API key from
+var llmKeyEnvVars = map[string][]string{
+	"openai":    {"KATICH_LLM_API_KEY", "OPENAI_API_KEY"},
+	"anthropic": {"KATICH_LLM_API_KEY", "ANTHROPIC_API_KEY"},
+}
+
+func init() {
+	initCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "answer yes to all prompts (non-interactive)")
+	initCmd.Flags().StringVar(&initLLMProvider, "llm-provider", "", "LLM provider for the new config: openai, anthropic or local")
+	initCmd.Flags().StringVar(&initEmbeddingsProvider, "embeddings-provider", "", "embeddings provider for the new config: local or api")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite an existing config file")
+}
+
+// initCmd sets up katich in the current repository
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up katich in the current repository",
+	Long: `Run diagnostics, create .katich/config.yaml, build the initial
+context and optionally install a git pre-commit hook.
+
+The config starts from the defaults, with the LLM and embeddings
+providers asked for or taken from --llm-provider and --embeddings-provider,
+and is validated before it is written. An existing config is kept unless
+--force is given.
+
+Steps that are already done are skipped, so init is safe to re-run.
+
+Examples:
+  katich init
+  katich init --yes --llm-provider anthropic
+  katich init --force --llm-provider local --embeddings-provider local`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInit()
+	},
+}
+
+func runInit() error {
+	if err := runDoctor(); err != nil {
+		return err
+	}
+	out.Println()
+
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+
+	// Configuration
+	configPath := configFile
+	if configPath == "" {
+		configPath = filepath.Join(repo.RootPath, ".katich", "config.yaml")
+	}
+	if _, err := os.Stat(configPath); err == nil && !initForce {
+		out.Printf("✅ Config already exists at %s (use --force to overwrite it)\n", configPath)
+	} else if confirm(reader, fmt.Sprintf("Create %s?", configPath), true) {
+		if err := writeInitialConfig(reader, configPath); err != nil {
+			return err
+		}
+	}
+	out.Println()
+
+	// Context
+	contextPath := filepath.Join(repo.RootPath, ".katich", "context.json")
+	question := "Build the codebase context now?"
+	if _, err := os.Stat(contextPath); err == nil {
+		question = "Context already exists. Rebuild it now?"
+	}
+	if confirm(reader, question, true) {
+		out.Println()
+		if err := runContextBuild(); err != nil {
+			return err
+		}
+	}
+	out.Println()
+
+	// Pre-commit hook
+	if confirm(reader, "Install a git pre-commit hook that reviews your changes?", false) {
+		if err := installPreCommitHook(repo); err != nil {
+			return err
+		}
+	}
+
+	out.Println()
+	out.Println("🎉 katich is ready. Run 'katich review latest' to review your last commit.")
+
+	return nil
+}
+
+// writeInitialConfig writes a config based on the defaults with the chosen
+// providers. A missing LLM API key is only a warning, since keys belong in
+// environment variables, key files or the keyring rather than the config.
+func writeInitialConfig(reader *bufio.Reader, configPath string) error {
+	cfg := config.DefaultConfig()
+
+	cfg.LLM.Provider = initLLMProvider
+	if cfg.LLM.Provider == "" {
+		cfg.LLM.Provider = choose(reader, "LLM provider", []string{"openai", "anthropic", "local"}, "openai")
+	}
+	cfg.Embeddings.Provider = initEmbeddingsProvider
+	if cfg.Embeddings.Provider == "" {
+		cfg.Embeddings.Provider = choose(reader, "Embeddings provider", []string{"local", "api"}, "local")
+	}
+
+	// The key is checked last, so a missing one means the rest is valid
+	err := cfg.Validate()
+	missingKey := errors.Is(err, config.ErrMissingAPIKey)
+	if err != nil && !missingKey {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	if err := cfg.Save(configPath); err != nil {
+		return fmt.Errorf("failed to create config: %w", err)
+	}
+	out.Printf("✅ Config written to %s\n", configPath)
+
+	if missingKey && !envKeySet(cfg.LLM.Provider) {
+		envVars := llmKeyEnvVars[cfg.LLM.Provider]
+		out.Printf("⚠️  No %s API key found; set %s or llm.api_key_file before running reviews\n",
+			cfg.LLM.Provider, envVars[len(envVars)-1])
+	}
+	return nil
+}
+
+// envKeySet reports whether an API key for the LLM provider is set in the
+// environment
+func envKeySet(provider string) bool {
+	for _, name := range llmKeyEnvVars[provider] {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// installPreCommitHook writes the katich pre-commit hook unless a different
+// hook is already installed
+func installPreCommitHook(repo *git.Repository) error {
+	hooksDir, err := repo.GetHooksDir()
+	if err != nil {
+		return err
+	}
+	hookPath := filepath.Join(hooksDir, "pre-commit")
+
+	if existing, err := os.ReadFile(hookPath); err == nil {
+		if strings.Contains(string(existing), hookMarker) {
+			out.Printf("✅ Pre-commit hook already installed at %s\n", hookPath)
+			return nil
+		}
+		out.Printf("⚠️  A pre-commit hook already exists at %s; leaving it unchanged\n", hookPath)
+		out.Println("   Add 'katich review staged --summary' to it to run katich on commit")
+		return nil
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(hookPath, []byte(preCommitHook), 0755); err != nil {
+		return fmt.Errorf("failed to write pre-commit hook: %w", err)
+	}
+
+	out.Printf("✅ Pre-commit hook installed at %s\n", hookPath)
+	return nil
+}
+
+// confirm asks a yes/no question on stdin. An empty answer selects the
+// default; --yes answers every question with yes.
+func confirm(reader *bufio.Reader, question string, defaultYes bool) bool {
+	if assumeYes {
+		return true
+	}
+
+	choices := "[y/N]"
+	if defaultYes {
+		choices = "[Y/n]"
+	}
+	fmt.Fprintf(stdout, "%s %s ", question, choices)
+
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(stdout)
+		return defaultYes
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "":
+		return defaultYes
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// choose asks for one of options on stdin. An empty answer or --yes selects
+// the default, and an unknown answer asks again.
+func choose(reader *bufio.Reader, question string, options []string, defaultOption string) string {
+	if assumeYes {
+		return defaultOption
+	}
+
+	for {
+		fmt.Fprintf(stdout, "%s (%s) [%s]: ", question, strings.Join(options, ", "), defaultOption)
+
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			fmt.Fprintln(stdout)
+			return defaultOption
+		}
+
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer == "" {
+			return defaultOption
+		}
+		for _, option := range options {
+			if answer == option {
+				return option
+			}
+		}
+		fmt.Fprintf(stdout, "Please answer one of: %s\n", strings.Join(options, ", "))
+	}
+}
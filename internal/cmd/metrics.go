@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Metrics flags
+	minComplexity int
+	minLength     int
+	metricsLang   string
+	metricsPath   string
+)
+
+func init() {
+	metricsCmd.Flags().IntVar(&minComplexity, "min-complexity", 0, "only list functions with at least this cyclomatic complexity")
+	metricsCmd.Flags().IntVar(&minLength, "min-length", 0, "only list functions with at least this many lines")
+	metricsCmd.Flags().StringVar(&metricsLang, "language", "", "only include files in this language (e.g. Go, Python)")
+	metricsCmd.Flags().StringVar(&metricsPath, "path", "", "only include files under this path or matching this glob")
+	addWarningGateFlags(metricsCmd)
+}
+
+// metricsCmd lists functions matching complexity and length thresholds
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "List functions above a complexity or length threshold",
+	Long: `List every function in the repository at or above the given complexity
+or length, sorted descending, to find refactoring candidates.
+
+Examples:
+  katich metrics --min-complexity 15
+  katich metrics --min-length 80 --language Go --path internal/`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMetrics()
+	},
+}
+
+// functionLocation is a function together with the file it is declared in
+type functionLocation struct {
+	file string
+	fn   analysis.FunctionInfo
+}
+
+func runMetrics() error {
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	out.Println("📊 Analyzing functions...")
+	out.Println()
+
+	if err := applyCheckFilters(cfg); err != nil {
+		return err
+	}
+	analyzer := analysis.NewAnalyzer(repo.RootPath, cfg.Analysis)
+	analysisResult, err := analyzer.AnalyzeRepository()
+	if err != nil {
+		return fmt.Errorf("failed to analyze code: %w", err)
+	}
+
+	matches := make([]functionLocation, 0)
+	severities := make(map[analysis.Severity]int)
+	for path, fileAnalysis := range analysisResult.Files {
+		if !matchesMetricsFilters(path, fileAnalysis.Language) {
+			continue
+		}
+		for _, issue := range fileAnalysis.Issues {
+			severities[issue.Severity]++
+		}
+		for _, fn := range fileAnalysis.Functions {
+			if fn.Complexity >= minComplexity && fn.LOC >= minLength {
+				matches = append(matches, functionLocation{file: path, fn: fn})
+			}
+		}
+	}
+
+	// Sort by the metric being filtered on, complexity by default
+	byLength := minLength > 0 && minComplexity == 0
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i].fn, matches[j].fn
+		if byLength && a.LOC != b.LOC {
+			return a.LOC > b.LOC
+		}
+		if a.Complexity != b.Complexity {
+			return a.Complexity > b.Complexity
+		}
+		if a.LOC != b.LOC {
+			return a.LOC > b.LOC
+		}
+		return matches[i].file < matches[j].file
+	})
+
+	if len(matches) == 0 {
+		out.Println("✅ No functions match the given thresholds")
+		return enforceWarningLimit(severities)
+	}
+
+	fmt.Fprintf(stdout, "%-10s  %-5s  %s\n", "COMPLEXITY", "LINES", "FUNCTION")
+	for _, match := range matches {
+		name := match.fn.Name
+		if match.fn.Receiver != "" {
+			name = match.fn.Receiver + "." + name
+		}
+		fmt.Fprintf(stdout, "%-10d  %-5d  %s (%s:%d)\n", match.fn.Complexity, match.fn.LOC, name, match.file, match.fn.StartLine)
+	}
+
+	out.Println()
+	out.Printf("%d function(s) matched\n", len(matches))
+
+	return enforceWarningLimit(severities)
+}
+
+// matchesMetricsFilters applies the --language and --path filters to a file
+func matchesMetricsFilters(path, language string) bool {
+	if metricsLang != "" && !strings.EqualFold(language, metricsLang) {
+		return false
+	}
+	if metricsPath == "" {
+		return true
+	}
+
+	filter := filepath.Clean(metricsPath)
+	if path == filter || strings.HasPrefix(path, filter+string(filepath.Separator)) {
+		return true
+	}
+	matched, _ := filepath.Match(filter, path)
+	return matched
+}
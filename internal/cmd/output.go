@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// progressWidth is the number of cells in a progress bar
+const progressWidth = 30
+
+// emojiPattern matches an emoji with its variation selector and the spaces
+// that separate it from the text after it
+var emojiPattern = regexp.MustCompile(`[\x{1F300}-\x{1FAFF}\x{2600}-\x{27BF}\x{2139}\x{2B50}]\x{FE0F}?( *)`)
+
+// plainMarkers are the ASCII markers that replace status emoji in plain
+// output; other emoji are dropped
+var plainMarkers = map[string]string{
+	"✅": "[OK]",
+	"⚠": "[WARN]",
+	"❌": "[ERROR]",
+	"ℹ": "[INFO]",
+	"🚨": "[ALERT]",
+}
+
+// plainSymbols replaces the non-ASCII symbols of lists and diagrams
+var plainSymbols = strings.NewReplacer("•", "-", "→", "->", "←", "<-", "≥", ">=", "█", "#", "░", "-")
+
+// plainText replaces emoji and symbols in s with ASCII
+func plainText(s string) string {
+	s = emojiPattern.ReplaceAllStringFunc(s, func(match string) string {
+		emoji := strings.TrimRight(strings.TrimRight(match, " "), "\uFE0F")
+		marker, ok := plainMarkers[emoji]
+		if !ok {
+			return ""
+		}
+		if strings.HasSuffix(match, " ") {
+			marker += " "
+		}
+		return marker
+	})
+	return plainSymbols.Replace(s)
+}
+
+// plainWriter writes through plainText. Each Write is converted on its own,
+// which is safe since fmt writes a whole formatted string at once.
+type plainWriter struct {
+	w io.Writer
+}
+
+func (p plainWriter) Write(b []byte) (int, error) {
+	if _, err := io.WriteString(p.w, plainText(string(b))); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// plainOutput reports whether emoji should be replaced with ASCII: with
+// --no-emoji, or when NO_COLOR or CI is set, as in CI logs
+func plainOutput() bool {
+	if noEmoji || os.Getenv("NO_COLOR") != "" {
+		return true
+	}
+	ci := os.Getenv("CI")
+	return ci != "" && ci != "false" && ci != "0"
+}
+
+// outputTo returns f, wrapped to print plain text in plain output mode
+func outputTo(f *os.File) io.Writer {
+	if plainOutput() {
+		return plainWriter{f}
+	}
+	return f
+}
+
+// stdout receives explicit command output such as tables and doctor
+// checks, converted to plain text like out in plain output mode
+var stdout io.Writer = os.Stdout
+
+// configureOutput applies the plain output mode to stdout and out
+func configureOutput() {
+	stdout = outputTo(os.Stdout)
+	out.w = outputTo(os.Stdout)
+}
+
+// printer writes decorative command output such as progress lines, headings
+// and summaries. It is silenced by the global --quiet flag so that only
+// explicit output (e.g. JSON written to stdout) and errors remain.
+type printer struct {
+	w     io.Writer
+	muted bool // set by modes such as review --summary
+}
+
+// out is the printer used by all commands for non-essential output
+var out = &printer{w: os.Stdout}
+
+// Println prints a line unless output is silenced
+func (p *printer) Println(a ...interface{}) {
+	if !p.enabled() {
+		return
+	}
+	fmt.Fprintln(p.w, a...)
+}
+
+// Printf prints formatted output unless output is silenced
+func (p *printer) Printf(format string, a ...interface{}) {
+	if !p.enabled() {
+		return
+	}
+	fmt.Fprintf(p.w, format, a...)
+}
+
+// Writer returns the destination for decorative output, which is
+// io.Discard when output is silenced
+func (p *printer) Writer() io.Writer {
+	if !p.enabled() {
+		return io.Discard
+	}
+	return p.w
+}
+
+// enabled reports whether decorative output should be printed
+func (p *printer) enabled() bool {
+	return !quiet && !p.muted
+}
+
+// progressBar returns a callback that redraws a progress bar in place, or
+// nil when output is silenced or not a terminal, where carriage returns
+// would only clutter logs
+func (p *printer) progressBar() func(done, total int) {
+	if !p.enabled() || !isTerminal(p.w) {
+		return nil
+	}
+
+	last := -1
+	return func(done, total int) {
+		if total == 0 {
+			return
+		}
+		percent := done * 100 / total
+		if percent == last {
+			return
+		}
+		last = percent
+
+		filled := progressWidth * done / total
+		fmt.Fprintf(p.w, "\r  [%s%s] %3d%% %d/%d", strings.Repeat("█", filled), strings.Repeat("░", progressWidth-filled), percent, done, total)
+		if done == total {
+			fmt.Fprintln(p.w)
+		}
+	}
+}
+
+// isTerminal reports whether w writes to a terminal, looking through the
+// plain output wrapper to the file it writes to
+func isTerminal(w io.Writer) bool {
+	if plain, ok := w.(plainWriter); ok {
+		w = plain.w
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
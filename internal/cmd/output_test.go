@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestIsTerminalUnwrapsPlainWriter(t *testing.T) {
+	// The null device is a character device, as a terminal is
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skipf("no %s: %v", os.DevNull, err)
+	}
+	defer f.Close()
+
+	if !isTerminal(plainWriter{f}) {
+		t.Error("plain output to a character device is not treated as a terminal")
+	}
+	if isTerminal(plainWriter{&bytes.Buffer{}}) {
+		t.Error("plain output to a buffer is treated as a terminal")
+	}
+}
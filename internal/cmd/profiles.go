@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/katichai/katich/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// profilesCmd represents the profiles command group
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Inspect built-in configuration profiles",
+	Long: `Profiles are named presets of thresholds and checks. Select one with
+'profile: <name>' in .katich/config.yaml; values set explicitly in the
+file override the profile.`,
+}
+
+func init() {
+	profilesCmd.AddCommand(profilesListCmd)
+}
+
+// profilesListCmd lists the built-in profiles
+var profilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProfilesList()
+	},
+}
+
+func runProfilesList() error {
+	active := ""
+	if cfg, err := config.Load(GetConfig()); err == nil {
+		active = cfg.Profile
+	}
+
+	out.Println("🎛️  Profiles:")
+	for _, profile := range config.Profiles() {
+		marker := " "
+		if profile.Name == active {
+			marker = "*"
+		}
+		out.Printf("  %s %-10s %s\n", marker, profile.Name, profile.Description)
+	}
+	return nil
+}
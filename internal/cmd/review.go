@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/ci"
+	"github.com/katichai/katich/internal/config"
 	"github.com/katichai/katich/internal/git"
+	"github.com/katichai/katich/internal/review"
 	"github.com/spf13/cobra"
 )
 
@@ -16,13 +20,26 @@ var reviewCmd = &cobra.Command{
 	Short: "Review code changes using AI-assisted analysis",
 	Long: `Analyze git diffs, detect AI-generated code, find duplicates,
 and provide architecture-aware code reviews.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return prepareReviewOutput()
+	},
 }
 
 var (
 	// Review flags
 	ciMode       bool
+	failOn       string
 	outputFormat string
 	outputFile   string
+	summaryOnly  bool
+	serveReport  bool
+	serveAddr    string
+	dryRunOut    string
+
+	ignoreWhitespace bool
+	allLines         bool
+	baseRef          string
+	diffFiles        []string
 )
 
 func init() {
@@ -30,11 +47,32 @@ func init() {
 	reviewCmd.AddCommand(reviewLatestCmd)
 	reviewCmd.AddCommand(reviewDiffCmd)
 	reviewCmd.AddCommand(reviewFileCmd)
+	reviewCmd.AddCommand(reviewFuncCmd)
+	reviewCmd.AddCommand(reviewBranchCmd)
+	reviewCmd.AddCommand(reviewStagedCmd)
+	reviewCmd.AddCommand(reviewWorkingCmd)
+	reviewCmd.AddCommand(reviewReflogCmd)
+	reviewCmd.AddCommand(reviewCompareCmd)
+	reviewCmd.AddCommand(reviewPRCmd)
+
+	// Flags for review diff
+	reviewDiffCmd.Flags().StringSliceVar(&diffFiles, "file", nil, "only review these files or directories within the range")
+
+	// Flags for review branch
+	reviewBranchCmd.Flags().StringVar(&baseRef, "base", "", "base ref to compare against (default: CI target branch or the default branch)")
 
 	// Global review flags
-	reviewCmd.PersistentFlags().BoolVar(&ciMode, "ci", false, "CI mode (exit with error code on issues)")
-	reviewCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "terminal", "output format (terminal, json, markdown, html)")
-	reviewCmd.PersistentFlags().StringVar(&outputFile, "output-file", "", "write output to file")
+	reviewCmd.PersistentFlags().BoolVar(&ciMode, "ci", false, "CI mode (exit with error code on issues at or above --fail-on)")
+	reviewCmd.PersistentFlags().StringVar(&failOn, "fail-on", "", "severity that fails a --ci review: info, warning or error (default from analysis.fail_on, error)")
+	reviewCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "terminal", "output formats, comma-separated (terminal, codeclimate, html, json, markdown, sarif)")
+	reviewCmd.PersistentFlags().StringVar(&outputFile, "output-file", "", "write output to file (comma-separated, matching --output)")
+	reviewCmd.PersistentFlags().BoolVar(&summaryOnly, "summary", false, "print only a one-line summary of the review")
+	reviewCmd.PersistentFlags().BoolVar(&serveReport, "serve", false, "serve the HTML report over HTTP until interrupted")
+	reviewCmd.PersistentFlags().StringVar(&serveAddr, "addr", "localhost:8765", "address for --serve")
+	reviewCmd.PersistentFlags().StringVar(&dryRunOut, "dry-run-out", "", "write the PR/MR comments that would be posted, with their diff anchors, to this JSON file")
+	reviewCmd.PersistentFlags().BoolVarP(&ignoreWhitespace, "ignore-whitespace", "w", false, "ignore whitespace-only changes in diffs")
+	reviewCmd.PersistentFlags().BoolVar(&allLines, "all-lines", false, "report issues anywhere in changed files, not only on the changed lines")
+	addWarningGateFlags(reviewCmd)
 }
 
 // reviewLatestCmd reviews the latest commit
@@ -56,13 +94,28 @@ var reviewDiffCmd = &cobra.Command{
 Examples:
   katich review diff HEAD~3..HEAD
   katich review diff main..feature-branch
-  katich review diff abc123..def456`,
+  katich review diff abc123..def456
+  katich review diff main..HEAD --file internal/git/diff.go`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runReviewDiff(args[0])
 	},
 }
 
+// reviewBranchCmd reviews the current branch against its base
+var reviewBranchCmd = &cobra.Command{
+	Use:   "branch",
+	Short: "Review the current branch against its base",
+	Long: `Analyze all changes on the current branch since it diverged from its base.
+
+The base is taken from --base, then from the CI target branch
+(GITHUB_BASE_REF, CI_MERGE_REQUEST_TARGET_BRANCH_NAME, ...), and finally
+from the repository's default branch.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReviewBranch()
+	},
+}
+
 // reviewFileCmd reviews a specific file
 var reviewFileCmd = &cobra.Command{
 	Use:   "file <path>",
@@ -75,34 +128,38 @@ var reviewFileCmd = &cobra.Command{
 }
 
 func runReviewLatest() error {
-	fmt.Println("🔍 Reviewing latest commit...")
-	fmt.Println()
+	out.Println("🔍 Reviewing latest commit...")
+	out.Println()
 	
 	// Find Git repository
 	repo, err := git.FindRepository()
 	if err != nil {
 		return fmt.Errorf("failed to find Git repository: %w", err)
 	}
+	repo.IgnoreWhitespace = ignoreWhitespace
 	
 	if verbose {
-		fmt.Println("Verbose mode enabled")
-		fmt.Printf("Repository: %s\n", repo.RootPath)
-		fmt.Printf("CI mode: %v\n", ciMode)
-		fmt.Printf("Output format: %s\n", outputFormat)
-		fmt.Println()
+		out.Println("Verbose mode enabled")
+		out.Printf("Repository: %s\n", repo.RootPath)
+		out.Printf("CI mode: %v\n", ciMode)
+		out.Printf("Output format: %s\n", outputFormat)
+		out.Println()
+	}
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
 	}
 
 	// Check if context exists
 	contextPath := filepath.Join(repo.RootPath, ".katich", "context.json")
-	hasContext := false
 	if _, err := os.Stat(contextPath); err == nil {
-		hasContext = true
 		if verbose {
-			fmt.Println("✅ Context found, using for enhanced analysis")
+			out.Println("✅ Context found, using for enhanced analysis")
 		}
 	} else {
-		fmt.Println("⚠️  No context found. Run 'katich context build' for better analysis.")
-		fmt.Println()
+		out.Println("⚠️  No context found. Run 'katich context build' for better analysis.")
+		out.Println()
 	}
 
 	// Get latest commit
@@ -118,128 +175,379 @@ func runReviewLatest() error {
 	}
 
 	// Display commit info
-	fmt.Printf("📝 Commit: %s\n", commit.ShortHash)
-	fmt.Printf("👤 Author: %s <%s>\n", commit.Author, commit.Email)
-	fmt.Printf("📅 Date: %s\n", commit.Date.Format("2006-01-02 15:04:05"))
-	fmt.Printf("💬 Message: %s\n", commit.Message)
-	fmt.Println()
-
-	// Display diff summary
-	fmt.Println("📊 Changes:")
-	for _, file := range diff.Files {
-		status := "M"
-		if file.Status != "" {
-			status = file.Status
+	out.Printf("📝 Commit: %s\n", commit.ShortHash)
+	out.Printf("👤 Author: %s <%s>\n", commit.Author, commit.Email)
+	out.Printf("📅 Date: %s\n", commit.Date.Format("2006-01-02 15:04:05"))
+	out.Printf("💬 Message: %s\n", commit.Message)
+	if commit.Body != "" {
+		for _, line := range strings.Split(commit.Body, "\n") {
+			out.Println(strings.TrimRight("   "+line, " "))
 		}
-		fmt.Printf("  [%s] %s (+%d -%d)\n", status, file.Path, file.Additions, file.Deletions)
 	}
-	fmt.Println()
+	out.Println()
 
-	// Analyze changed files
-	if hasContext {
-		fmt.Println("🔬 Analyzing changed files...")
-		changedFiles := make([]string, 0)
-		for _, file := range diff.Files {
-			changedFiles = append(changedFiles, file.Path)
-		}
-
-		analyzer := analysis.NewAnalyzer(repo.RootPath)
-		fileAnalyses, err := analyzer.AnalyzeChangedFiles(changedFiles)
-		if err != nil {
-			fmt.Printf("⚠️  Analysis error: %v\n", err)
-		} else if len(fileAnalyses) > 0 {
-			// Display analysis results
-			totalIssues := 0
-			for filePath, fileAnalysis := range fileAnalyses {
-				if len(fileAnalysis.Issues) > 0 {
-					fmt.Printf("\n📄 %s:\n", filePath)
-					for _, issue := range fileAnalysis.Issues {
-						totalIssues++
-						severity := "ℹ️"
-						if issue.Severity == analysis.SeverityWarning {
-							severity = "⚠️"
-						} else if issue.Severity == analysis.SeverityError {
-							severity = "❌"
-						}
-						fmt.Printf("  %s Line %d: %s\n", severity, issue.Line, issue.Message)
-						if issue.Suggestion != "" {
-							fmt.Printf("     💡 %s\n", issue.Suggestion)
-						}
-					}
-				}
-			}
+	// Display diff summary
+	printDiffChanges(diff, true)
 
-			if totalIssues == 0 {
-				fmt.Println("✅ No issues found in changed files!")
-			} else {
-				fmt.Printf("\n⚠️  Found %d issue(s) in changed files\n", totalIssues)
-			}
-		}
-		fmt.Println()
+	// Analyze changed files
+	out.Println("🔬 Analyzing changed files...")
+	result, err := analyzeDiff(repo, cfg, diff, "HEAD^", "HEAD")
+	if err != nil {
+		out.Printf("⚠️  Analysis error: %v\n", err)
 	}
+	result.Commit = review.NewCommitInfo(commit)
+	printSymbolChanges(result)
+	printReviewFindings(result)
+	out.Println()
 
 	// AI-powered review placeholder
-	fmt.Println("🤖 AI-Powered Review:")
-	fmt.Println("  ⚠️  LLM-based review not yet implemented")
-	fmt.Println()
-	fmt.Println("  Next enhancements:")
-	fmt.Println("    • Generate embeddings for new code")
-	fmt.Println("    • Search for similar code patterns")
-	fmt.Println("    • Detect AI-generated boilerplate")
-	fmt.Println("    • Run LLM classifier")
-	fmt.Println("    • Synthesize comprehensive review")
+	out.Println("🤖 AI-Powered Review:")
+	out.Println("  ⚠️  LLM-based review not yet implemented")
+	out.Println()
+	out.Println("  Next enhancements:")
+	out.Println("    • Generate embeddings for new code")
+	out.Println("    • Search for similar code patterns")
+	out.Println("    • Detect AI-generated boilerplate")
+	out.Println("    • Run LLM classifier")
+	out.Println("    • Synthesize comprehensive review")
+	out.Println()
 
-	return nil
+	printReviewSummary(result)
+	return writeReviewOutput(result, cfg)
 }
 
 func runReviewDiff(diffRange string) error {
-	fmt.Printf("🔍 Reviewing diff range: %s\n", diffRange)
-	fmt.Println()
+	out.Printf("🔍 Reviewing diff range: %s\n", diffRange)
+	out.Println()
 	
 	// Find Git repository
 	repo, err := git.FindRepository()
 	if err != nil {
 		return fmt.Errorf("failed to find Git repository: %w", err)
 	}
+	repo.IgnoreWhitespace = ignoreWhitespace
 	
 	if verbose {
-		fmt.Println("Verbose mode enabled")
-		fmt.Printf("Repository: %s\n", repo.RootPath)
-		fmt.Printf("CI mode: %v\n", ciMode)
-		fmt.Printf("Output format: %s\n", outputFormat)
-		fmt.Println()
+		out.Println("Verbose mode enabled")
+		out.Printf("Repository: %s\n", repo.RootPath)
+		out.Printf("CI mode: %v\n", ciMode)
+		out.Printf("Output format: %s\n", outputFormat)
+		out.Println()
 	}
 
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Limit the diff to --file paths, given relative to the working directory
+	for _, path := range diffFiles {
+		relPath, err := repoRelativePath(repo, path)
+		if err != nil {
+			return err
+		}
+		repo.Pathspec = append(repo.Pathspec, relPath)
+	}
+
+	result, err := reviewRange(repo, cfg, diffRange)
+	if err != nil {
+		return err
+	}
+
+	// TODO: Implement actual review logic
+	out.Println("⚠️  AI-powered review not yet implemented")
+	out.Println()
+
+	printReviewSummary(result)
+	return writeReviewOutput(result, cfg)
+}
+
+// reviewRange analyzes the files changed in a diff range and prints the
+// changes and findings
+func reviewRange(repo *git.Repository, cfg *config.Config, diffRange string) (*review.Result, error) {
 	// Get diff for range
 	diff, err := repo.GetDiffRange(diffRange)
 	if err != nil {
-		return fmt.Errorf("failed to get diff: %w", err)
+		return nil, fmt.Errorf("failed to get diff: %w", err)
 	}
+	warnUntouchedPaths(diff, repo.Pathspec, diffRange)
 
 	// Display diff summary
-	fmt.Println("📊 Changes:")
+	printDiffChanges(diff, false)
+
+	// Analyze changed files
+	out.Println("🔬 Analyzing changed files...")
+	baseRef, headRef, err := repo.ResolveRange(diffRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve range: %w", err)
+	}
+	result, err := analyzeDiff(repo, cfg, diff, baseRef, headRef)
+	if err != nil {
+		out.Printf("⚠️  Analysis error: %v\n", err)
+	}
+	result.Range = diffRange
+	printSymbolChanges(result)
+	printReviewFindings(result)
+	out.Println()
+
+	return result, nil
+}
+
+// repoRelativePath converts a path relative to the working directory into
+// one relative to the repository root, as git pathspecs expect
+func repoRelativePath(repo *git.Repository, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+	relPath, err := repo.GetRelativePath(absPath)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("%s is outside the repository", path)
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
+// warnUntouchedPaths warns about pathspecs that match no file in the diff
+func warnUntouchedPaths(diff *git.Diff, pathspec []string, diffRange string) {
+	for _, path := range pathspec {
+		touched := false
+		for _, file := range diff.Files {
+			if file.Path == path || strings.HasPrefix(file.Path, strings.TrimSuffix(path, "/")+"/") {
+				touched = true
+				break
+			}
+		}
+		if !touched {
+			out.Printf("⚠️  %s was not changed in %s\n", path, diffRange)
+			out.Println()
+		}
+	}
+}
+
+func runReviewBranch() error {
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	base := baseRef
+	if base == "" {
+		resolved, err := ci.ResolveBaseRef(repo)
+		if err != nil {
+			return err
+		}
+		base = resolved.Ref
+		out.Printf("🌿 Base: %s (from %s)\n", base, resolved.Source)
+	}
+
+	return runReviewDiff(base + "...HEAD")
+}
+
+// analyzeDiff runs static analysis on the files changed in a diff and
+// summarizes the symbols changed between baseRef and headRef. An empty
+// headRef compares against the working tree and git.IndexRef against the
+// staging area.
+func analyzeDiff(repo *git.Repository, cfg *config.Config, diff *git.Diff, baseRef, headRef string) (*review.Result, error) {
+	result := review.NewResult()
+
+	changedFiles := make([]string, 0, len(diff.Files))
 	for _, file := range diff.Files {
-		fmt.Printf("  %s (+%d -%d)\n", file.Path, file.Additions, file.Deletions)
+		if !file.FormattingOnly && !file.IsBinary {
+			changedFiles = append(changedFiles, file.Path)
+		}
 	}
-	fmt.Println()
 
-	// TODO: Implement actual review logic
-	fmt.Println("⚠️  AI-powered review not yet implemented")
+	if err := applyCheckFilters(cfg); err != nil {
+		return result, err
+	}
+	analyzer := analysis.NewAnalyzer(repo.RootPath, cfg.Analysis)
+	endAnalyze := auditLog.Phase("analyze")
+	defer endAnalyze()
+	// Analyze each file as it is at headRef, keeping the content so the
+	// findings are fingerprinted from the same source
+	sources := make(map[string][]byte, len(changedFiles))
+	fileAnalyses := analyzer.AnalyzeChangedSources(changedFiles, func(path string) ([]byte, error) {
+		content, err := revisionContent(repo, path, headRef)
+		if err == nil {
+			sources[path] = content
+		}
+		return content, err
+	})
+	result.Duplicates = analyzer.DetectChangedDuplicates(fileAnalyses)
 
-	return nil
+	hidden := 0
+	for _, file := range diff.Files {
+		// Binary files have no lines to count or review
+		if file.IsBinary {
+			continue
+		}
+		fileResult := review.NewFileResult(file, fileAnalyses[file.Path], sources[file.Path])
+		if !allLines {
+			hidden += fileResult.FilterChangedLines()
+		}
+		if critical := cfg.Analysis.CriticalPath(file.Path); critical != nil {
+			fileResult.Weight = critical.Weight
+			fileResult.FailOn = analysis.Severity(critical.FailOn)
+		}
+		result.Files = append(result.Files, fileResult)
+		if !file.FormattingOnly {
+			result.Symbols = append(result.Symbols, changedSymbols(repo, analyzer, file, baseRef, headRef)...)
+		}
+	}
+	if hidden > 0 {
+		out.Printf("ℹ️  %d issue(s) outside the changed lines not shown (use --all-lines to include them)\n", hidden)
+	}
+
+	return result, nil
 }
 
-func runReviewFile(filePath string) error {
-	fmt.Printf("🔍 Reviewing file: %s\n", filePath)
-	
-	if verbose {
-		fmt.Println("Verbose mode enabled")
-		fmt.Printf("CI mode: %v\n", ciMode)
-		fmt.Printf("Output format: %s\n", outputFormat)
+// changedSymbols parses both sides of a changed file and diffs their symbols
+func changedSymbols(repo *git.Repository, analyzer *analysis.Analyzer, file *git.DiffFile, baseRef, headRef string) []review.SymbolChange {
+	oldPath := file.Path
+	if file.OldPath != "" {
+		oldPath = file.OldPath
+	}
+
+	var oldAnalysis, newAnalysis *analysis.FileAnalysis
+	oldSource, newSource := "", ""
+
+	if file.Status != "A" {
+		if content, err := repo.GetFileContent(baseRef, oldPath); err == nil {
+			oldSource = content
+			oldAnalysis, _ = analyzer.AnalyzeSource(oldPath, []byte(content))
+		}
 	}
 
-	// TODO: Implement review logic
-	fmt.Println("⚠️  Review not yet implemented")
+	if file.Status != "D" {
+		if content, err := revisionContent(repo, file.Path, headRef); err == nil {
+			newSource = string(content)
+			newAnalysis, _ = analyzer.AnalyzeSource(file.Path, content)
+		}
+	}
+
+	return review.DiffSymbols(file.Path, oldAnalysis, newAnalysis, oldSource, newSource)
+}
+
+// revisionContent reads a file at ref, or from the working tree when ref
+// is empty
+func revisionContent(repo *git.Repository, path, ref string) ([]byte, error) {
+	if ref == "" {
+		return os.ReadFile(filepath.Join(repo.RootPath, path))
+	}
+	content, err := repo.GetFileContent(ref, path)
+	return []byte(content), err
+}
+
+// printDiffChanges lists substantive changes, then files that were only
+// reformatted so reviewers can skip them
+func printDiffChanges(diff *git.Diff, showStatus bool) {
+	formatting := make([]*git.DiffFile, 0)
+	binary := make([]*git.DiffFile, 0)
 
-	return nil
+	out.Println("📊 Changes:")
+	for _, file := range diff.Files {
+		if file.FormattingOnly {
+			formatting = append(formatting, file)
+			continue
+		}
+		if file.IsBinary {
+			binary = append(binary, file)
+			continue
+		}
+		if showStatus {
+			status := "M"
+			if file.Status != "" {
+				status = file.Status
+			}
+			out.Printf("  [%s] %s (+%d -%d)\n", status, displayPath(file), file.Additions, file.Deletions)
+		} else {
+			out.Printf("  %s (+%d -%d)\n", displayPath(file), file.Additions, file.Deletions)
+		}
+	}
+	out.Println()
+
+	if len(formatting) > 0 {
+		out.Printf("🎨 %d file(s) formatting-only (not analyzed):\n", len(formatting))
+		for _, file := range formatting {
+			out.Printf("  %s\n", file.Path)
+		}
+		out.Println()
+	}
+
+	if len(binary) > 0 {
+		out.Printf("📦 %d binary file(s) (not analyzed):\n", len(binary))
+		for _, file := range binary {
+			out.Printf("  %s\n", displayPath(file))
+		}
+		out.Println()
+	}
+}
+
+// printSymbolChanges prints the added, modified and removed symbols per file
+func printSymbolChanges(result *review.Result) {
+	if len(result.Symbols) == 0 {
+		return
+	}
+
+	markers := map[string]string{
+		review.SymbolAdded:    "+",
+		review.SymbolModified: "~",
+		review.SymbolRemoved:  "-",
+	}
+
+	out.Println("🧩 Changed Symbols:")
+	currentFile := ""
+	for _, sym := range result.Symbols {
+		if sym.File != currentFile {
+			currentFile = sym.File
+			out.Printf("  %s\n", currentFile)
+		}
+		out.Printf("    %s %s %s\n", markers[sym.Change], sym.Kind, sym.Name)
+	}
+	out.Println()
+}
+
+// printReviewFindings prints per-file findings for terminal output
+func printReviewFindings(result *review.Result) {
+	for _, file := range result.Files {
+		if len(file.Findings) == 0 {
+			continue
+		}
+
+		out.Printf("\n📄 %s:\n", file.Path)
+		for _, finding := range file.Findings {
+			severity := "ℹ️"
+			if finding.Severity == analysis.SeverityWarning {
+				severity = "⚠️"
+			} else if finding.Severity == analysis.SeverityError {
+				severity = "❌"
+			}
+			out.Printf("  %s Line %d: %s\n", severity, finding.Line, finding.Message)
+			if finding.Suggestion != "" {
+				out.Printf("     💡 %s\n", finding.Suggestion)
+			}
+		}
+	}
+}
+
+// printReviewSummary prints the one-line review outcome shared by all
+// review commands. It is the only output in --summary mode and is
+// omitted in --quiet mode.
+func printReviewSummary(result *review.Result) {
+	if quiet {
+		return
+	}
+	fmt.Fprintln(out.w, result.StatusLine())
+}
+
+// displayPath returns a file's path for listings, showing where a renamed
+// file came from
+func displayPath(file *git.DiffFile) string {
+	if file.OldPath != "" {
+		return file.OldPath + " → " + file.Path
+	}
+	return file.Path
 }
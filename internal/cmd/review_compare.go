@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/katichai/katich/internal/review"
+	"github.com/spf13/cobra"
+)
+
+// reviewCompareCmd compares the findings of two saved reviews
+var reviewCompareCmd = &cobra.Command{
+	Use:   "compare <old.json> <new.json>",
+	Short: "Compare the findings of two saved reviews",
+	Long: `Compare two reviews saved with --output json (or --output codeclimate)
+and report which findings were resolved, which persist and which are new.
+Findings are matched by fingerprint, so an issue whose code only moved is
+not reported as resolved and reintroduced.
+
+Use it after a fix-up commit to check whether the review comments were
+addressed.
+
+Examples:
+  katich review latest --output json --output-file before.json
+  katich review latest --output json --output-file after.json
+  katich review compare before.json after.json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReviewCompare(args[0], args[1])
+	},
+}
+
+func runReviewCompare(oldPath, newPath string) error {
+	oldResult, err := review.LoadResult(oldPath)
+	if err != nil {
+		return err
+	}
+	newResult, err := review.LoadResult(newPath)
+	if err != nil {
+		return err
+	}
+
+	comparison := review.Compare(oldResult, newResult)
+
+	out.Printf("🔁 Comparing %s → %s\n", oldPath, newPath)
+	out.Println()
+	printComparedFindings("✅ Resolved", comparison.Resolved)
+	printComparedFindings("⏳ Persisting", comparison.Persisting)
+	printComparedFindings("🆕 Introduced", comparison.Introduced)
+
+	out.Printf("📊 %d resolved, %d persisting, %d introduced\n",
+		len(comparison.Resolved), len(comparison.Persisting), len(comparison.Introduced))
+	return nil
+}
+
+// printComparedFindings lists one group of a comparison
+func printComparedFindings(title string, findings []review.ComparedFinding) {
+	if len(findings) == 0 {
+		return
+	}
+	out.Printf("%s (%d):\n", title, len(findings))
+	for _, finding := range findings {
+		out.Printf("  %s:%d [%s] %s\n", finding.Path, finding.Line, finding.Severity, finding.Message)
+	}
+	out.Println()
+}
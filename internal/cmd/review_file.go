@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/embeddings"
+	"github.com/katichai/katich/internal/git"
+	"github.com/katichai/katich/internal/llm"
+	"github.com/katichai/katich/internal/review"
+)
+
+const (
+	// maxRelatedQueries bounds how many of a file's functions are looked up
+	// in the similarity index
+	maxRelatedQueries = 20
+
+	// maxRelatedCode is the number of similar functions shown to the LLM
+	maxRelatedCode = 5
+)
+
+func runReviewFile(filePath string) error {
+	out.Printf("🔍 Reviewing file: %s\n", filePath)
+	out.Println()
+
+	if verbose {
+		out.Println("Verbose mode enabled")
+		out.Printf("CI mode: %v\n", ciMode)
+		out.Printf("Output format: %s\n", outputFormat)
+		out.Println()
+	}
+
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	relPath, err := repo.GetRelativePath(absPath)
+	if err != nil {
+		return err
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := applyCheckFilters(cfg); err != nil {
+		return err
+	}
+	analyzer := analysis.NewAnalyzer(repo.RootPath, cfg.Analysis)
+	endAnalyze := auditLog.Phase("analyze")
+	fileAnalysis, err := analyzer.AnalyzeSource(relPath, content)
+	endAnalyze()
+	if err != nil {
+		return fmt.Errorf("failed to analyze %s: %w", filePath, err)
+	}
+
+	fileResult := review.NewFileResult(&git.DiffFile{Path: relPath}, fileAnalysis, content)
+
+	related := findRelatedCode(repo, cfg, relPath, fileAnalysis, content)
+	if len(related) > 0 {
+		out.Println("🔗 Similar code elsewhere:")
+		for _, code := range related {
+			out.Printf("  %s:%d-%d %s (%.0f%% similar)\n", code.Path, code.StartLine, code.EndLine, code.Name, code.Similarity*100)
+		}
+		out.Println()
+	}
+
+	if analysis.CheckSelected(cfg.Analysis, analysis.IssueTypeAIReview) {
+		out.Println("🤖 AI-Powered Review:")
+		findings, err := reviewWithLLM(cfg, relPath, fileAnalysis.Language, content, 1, strings.Count(string(content), "\n")+1, fileResult.Findings, related)
+		if err != nil {
+			auditLog.Warn("llm review failed", "error", err)
+			out.Printf("  ⚠️  LLM review skipped: %v\n", err)
+		} else {
+			out.Printf("  ✅ %d finding(s) from the LLM\n", len(findings))
+			fileResult.Findings = append(fileResult.Findings, findings...)
+		}
+		out.Println()
+	}
+
+	result := review.NewResult()
+	result.Files = append(result.Files, fileResult)
+
+	printReviewFindings(result)
+	out.Println()
+
+	printReviewSummary(result)
+	return writeReviewOutput(result, cfg)
+}
+
+// findRelatedCode embeds the file's functions and looks up similar
+// functions in other files in the embedding index. Without an index or a
+// provider matching it the review goes on without related code.
+func findRelatedCode(repo *git.Repository, cfg *config.Config, relPath string, fileAnalysis *analysis.FileAnalysis, content []byte) []llm.RelatedCode {
+	index, err := embeddings.LoadIndex(embeddings.IndexPath(filepath.Join(repo.RootPath, ".katich")))
+	if err != nil {
+		out.Println("ℹ️  No embedding index found. Run 'katich context build' to include similar code in the review.")
+		out.Println()
+		return nil
+	}
+
+	provider := newEmbeddingProvider(cfg)
+	if provider.GetName() == "None" || provider.GetDimension() != index.Dimension {
+		out.Printf("⚠️  Embedding index was built by %s; the active provider %s cannot search it\n", index.Provider, provider.GetName())
+		out.Println()
+		return nil
+	}
+
+	sourceLines := strings.Split(string(content), "\n")
+	texts := make([]string, 0)
+	for _, fn := range fileAnalysis.Functions {
+		if fn.EndLine-fn.StartLine+1 < cfg.Embeddings.MinFunctionLines {
+			continue
+		}
+		texts = append(texts, embeddings.FunctionSnippet(fn, fileAnalysis.Language, sourceLines))
+		if len(texts) == maxRelatedQueries {
+			break
+		}
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	endSearch := auditLog.Phase("similarity search")
+	defer endSearch()
+	vectors, err := provider.GenerateEmbeddings(texts)
+	if err != nil {
+		out.Printf("⚠️  Could not embed %s: %v\n", relPath, err)
+		out.Println()
+		return nil
+	}
+
+	search := embeddings.NewSimilaritySearch(index)
+	threshold := float32(cfg.Analysis.SimilarityThreshold)
+	best := make(map[string]embeddings.SimilarityResult)
+	for _, vector := range vectors {
+		for _, match := range search.Search(vector, maxRelatedCode) {
+			if match.FilePath == relPath || match.Similarity < threshold {
+				continue
+			}
+			if previous, ok := best[match.ID]; !ok || match.Similarity > previous.Similarity {
+				best[match.ID] = match
+			}
+		}
+	}
+
+	matches := make([]embeddings.SimilarityResult, 0, len(best))
+	for _, match := range best {
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Similarity > matches[j].Similarity
+	})
+	if len(matches) > maxRelatedCode {
+		matches = matches[:maxRelatedCode]
+	}
+
+	related := make([]llm.RelatedCode, 0, len(matches))
+	for _, match := range matches {
+		related = append(related, llm.RelatedCode{
+			Path:       match.FilePath,
+			Name:       match.FuncName,
+			StartLine:  match.StartLine,
+			EndLine:    match.EndLine,
+			Similarity: match.Similarity,
+			Code:       match.Code,
+		})
+	}
+	return related
+}
+
+// reviewWithLLM sends the source of a file from firstLine to lastLine with
+// its static findings and related code to the configured LLM and converts
+// the reply into findings on the file's own line numbers
+func reviewWithLLM(cfg *config.Config, relPath, language string, content []byte, firstLine, lastLine int, static []review.Finding, related []llm.RelatedCode) ([]review.Finding, error) {
+	provider, err := llm.NewProvider(cfg.LLM)
+	if err != nil {
+		return nil, err
+	}
+
+	fileLines := strings.SplitAfter(string(content), "\n")
+	lastLine = min(lastLine, len(fileLines))
+	source := strings.Join(fileLines[firstLine-1:lastLine], "")
+	offset := firstLine - 1
+
+	// The prompt numbers the source from 1
+	staticFindings := make([]string, 0, len(static))
+	for _, finding := range static {
+		staticFindings = append(staticFindings, fmt.Sprintf("line %d: %s", finding.Line-offset, finding.Message))
+	}
+	prompt := llm.BuildFileReviewPrompt(relPath, language, source, staticFindings, related, cfg.Review.MaxFileBytes)
+
+	endReview := auditLog.Phase("llm review")
+	start := time.Now()
+	reply, err := provider.Complete(llm.ReviewSystemPrompt, prompt, true)
+	endReview()
+	attrs := []any{
+		"provider", provider.GetName(),
+		"model", provider.GetModel(),
+		"prompt_chars", len(prompt),
+		"duration_ms", time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		return nil, err
+	}
+	auditLog.Debug("llm request", attrs...)
+
+	replies, err := llm.ParseReviewFindings(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := lastLine - offset
+	fingerprints := review.NewFingerprinter(relPath, content)
+	findings := make([]review.Finding, 0, len(replies))
+	for _, reported := range replies {
+		line := reported.Line + offset
+		if reported.Line <= 0 || reported.Line > lines {
+			line = 0
+		}
+		issue := analysis.Issue{
+			Type:       analysis.IssueTypeAIReview,
+			Severity:   analysis.Severity(reported.Severity),
+			Line:       line,
+			Message:    reported.Message,
+			Suggestion: reported.Suggestion,
+		}
+		findings = append(findings, review.Finding{
+			Issue:       issue,
+			Fingerprint: fingerprints.Fingerprint(issue),
+		})
+	}
+	return findings, nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/git"
+	"github.com/katichai/katich/internal/review"
+	"github.com/spf13/cobra"
+)
+
+// reviewFuncCmd reviews a single function
+var reviewFuncCmd = &cobra.Command{
+	Use:   "func <file>:<function>",
+	Short: "Review a single function",
+	Long: `Analyze one function in isolation. Methods can be given as Type.Method.
+
+Examples:
+  katich review func internal/git/diff.go:getDiffFiles
+  katich review func internal/git/repo.go:Repository.GetCurrentBranch`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReviewFunc(args[0])
+	},
+}
+
+func runReviewFunc(target string) error {
+	idx := strings.LastIndex(target, ":")
+	if idx <= 0 || idx == len(target)-1 {
+		return fmt.Errorf("invalid target %q: expected <file>:<function>", target)
+	}
+	filePath, funcName := target[:idx], target[idx+1:]
+
+	out.Printf("🔍 Reviewing function: %s in %s\n", funcName, filePath)
+	out.Println()
+
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	relPath, err := repo.GetRelativePath(absPath)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := applyCheckFilters(cfg); err != nil {
+		return err
+	}
+	analyzer := analysis.NewAnalyzer(repo.RootPath, cfg.Analysis)
+	fileAnalysis, err := analyzer.AnalyzeSource(relPath, content)
+	if err != nil {
+		return fmt.Errorf("failed to analyze %s: %w", filePath, err)
+	}
+
+	fn, err := findFunction(fileAnalysis, funcName)
+	if err != nil {
+		return fmt.Errorf("%w in %s", err, filePath)
+	}
+
+	out.Printf("📍 %s:%d-%d (%d lines, complexity %d)\n", relPath, fn.StartLine, fn.EndLine, fn.LOC, fn.Complexity)
+	out.Println()
+
+	// Keep only the findings that fall inside the function
+	fileResult := review.NewFileResult(&git.DiffFile{Path: relPath}, fileAnalysis, content)
+	findings := make([]review.Finding, 0, len(fileResult.Findings))
+	for _, finding := range fileResult.Findings {
+		if finding.Line >= fn.StartLine && finding.Line <= fn.EndLine {
+			findings = append(findings, finding)
+		}
+	}
+	fileResult.Findings = findings
+
+	if analysis.CheckSelected(cfg.Analysis, analysis.IssueTypeAIReview) {
+		out.Println("🤖 AI-Powered Review:")
+		findings, err := reviewWithLLM(cfg, relPath, fileAnalysis.Language, content, fn.StartLine, fn.EndLine, fileResult.Findings, nil)
+		if err != nil {
+			auditLog.Warn("llm review failed", "error", err)
+			out.Printf("  ⚠️  LLM review skipped: %v\n", err)
+		} else {
+			out.Printf("  ✅ %d finding(s) from the LLM\n", len(findings))
+			fileResult.Findings = append(fileResult.Findings, findings...)
+		}
+		out.Println()
+	}
+
+	result := review.NewResult()
+	result.Files = append(result.Files, fileResult)
+
+	printReviewFindings(result)
+	out.Println()
+
+	printReviewSummary(result)
+	return writeReviewOutput(result, cfg)
+}
+
+// findFunction looks up a function by name, or a method by Type.Method
+func findFunction(fileAnalysis *analysis.FileAnalysis, name string) (*analysis.FunctionInfo, error) {
+	receiver, method := "", name
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		receiver, method = name[:idx], name[idx+1:]
+	}
+
+	var matches []*analysis.FunctionInfo
+	for i := range fileAnalysis.Functions {
+		fn := &fileAnalysis.Functions[i]
+		if fn.Name != method || (receiver != "" && fn.Receiver != receiver) {
+			continue
+		}
+		matches = append(matches, fn)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("function %q not found", name)
+	case 1:
+		return matches[0], nil
+	}
+
+	candidates := make([]string, 0, len(matches))
+	for _, fn := range matches {
+		if fn.Receiver != "" {
+			candidates = append(candidates, fn.Receiver+"."+fn.Name)
+		} else {
+			candidates = append(candidates, fn.Name)
+		}
+	}
+	return nil, fmt.Errorf("function %q is ambiguous (%s); use Type.Method", name, strings.Join(candidates, ", "))
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+func TestReviewWithLLMOffsetsFunctionLines(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		prompt = request.Messages[len(request.Messages)-1].Content
+
+		reply := `{"findings": [{"line": 2, "severity": "warning", "message": "shadowed x"}, {"line": 9, "message": "outside the function"}]}`
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := config.DefaultConfig()
+	cfg.LLM = config.LLMConfig{Provider: "local", Model: "test", BaseURL: server.URL}
+
+	// f spans lines 5-7
+	content := []byte("package p\n\nvar x int\n\nfunc f() {\n\tx := 1\n}\n")
+	findings, err := reviewWithLLM(cfg, "p.go", "Go", content, 5, 7, nil, nil)
+	if err != nil {
+		t.Fatalf("reviewWithLLM: %v", err)
+	}
+
+	if strings.Contains(prompt, "package p") || !strings.Contains(prompt, "x := 1") {
+		t.Errorf("prompt does not hold only the function:\n%s", prompt)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2", len(findings))
+	}
+	if findings[0].Line != 6 {
+		t.Errorf("finding on line 2 of the function mapped to line %d, want 6", findings[0].Line)
+	}
+	if findings[1].Line != 0 {
+		t.Errorf("finding past the function kept line %d, want 0", findings[1].Line)
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/review"
+)
+
+// outputTarget pairs a machine-readable output format with its destination.
+// An empty path means stdout.
+type outputTarget struct {
+	format string
+	path   string
+}
+
+// reviewOutputs holds the machine-readable outputs requested for a review
+var reviewOutputs []outputTarget
+
+// prepareReviewOutput checks --fail-on and parses --output and --output-file
+// into output targets.
+// Decorative output is moved to stderr when a machine-readable format is
+// written to stdout so the two never interleave.
+func prepareReviewOutput() error {
+	if err := validateFailOn(); err != nil {
+		return err
+	}
+
+	targets, err := parseOutputTargets(outputFormat, outputFile)
+	if err != nil {
+		return err
+	}
+	reviewOutputs = targets
+	out.muted = summaryOnly
+
+	for _, target := range targets {
+		if target.path == "" {
+			out.w = outputTo(os.Stderr)
+		}
+	}
+	return nil
+}
+
+// parseOutputTargets matches comma-separated formats with comma-separated
+// files. Files pair positionally with all formats (use "-" for terminal or
+// stdout) or, when fewer are given, with the machine-readable formats only.
+func parseOutputTargets(formatList, fileList string) ([]outputTarget, error) {
+	formats := splitList(formatList)
+	if len(formats) == 0 {
+		formats = []string{"terminal"}
+	}
+
+	machine := make([]string, 0, len(formats))
+	for _, format := range formats {
+		if format == "terminal" {
+			continue
+		}
+		if _, err := review.GetWriter(format); err != nil {
+			return nil, err
+		}
+		machine = append(machine, format)
+	}
+
+	files := splitList(fileList)
+	paths := make([]string, len(machine))
+	switch {
+	case len(files) == 0:
+	case len(files) == len(formats):
+		i := 0
+		for j, format := range formats {
+			if format == "terminal" {
+				continue
+			}
+			paths[i] = files[j]
+			i++
+		}
+	case len(files) == len(machine):
+		copy(paths, files)
+	default:
+		return nil, fmt.Errorf("--output-file lists %d target(s) but --output lists %d format(s)", len(files), len(formats))
+	}
+
+	targets := make([]outputTarget, 0, len(machine))
+	toStdout := 0
+	for i, format := range machine {
+		path := paths[i]
+		if path == "-" {
+			path = ""
+		}
+		if path == "" {
+			toStdout++
+		}
+		targets = append(targets, outputTarget{format: format, path: path})
+	}
+	if toStdout > 1 {
+		return nil, fmt.Errorf("only one output format can be written to stdout; use --output-file for the others")
+	}
+
+	return targets, nil
+}
+
+// writeReviewOutput writes the result in every requested machine-readable
+// format, then serves the HTML report when --serve is set. Terminal output
+// is printed as the review runs, so nothing is written for it here.
+func writeReviewOutput(result *review.Result, cfg *config.Config) error {
+	for _, target := range reviewOutputs {
+		if err := writeOutputTarget(target, result); err != nil {
+			return err
+		}
+	}
+
+	if dryRunOut != "" {
+		if err := writeCommentPayload(dryRunOut, result); err != nil {
+			return err
+		}
+	}
+
+	if serveReport {
+		if err := serveReviewReport(result); err != nil {
+			return err
+		}
+	}
+	if err := enforceCriticalPaths(result); err != nil {
+		return err
+	}
+	if err := enforceFailOn(result, cfg); err != nil {
+		return err
+	}
+	return enforceWarningLimit(result.CountBySeverity())
+}
+
+// writeOutputTarget renders the result to a single destination
+func writeOutputTarget(target outputTarget, result *review.Result) error {
+	writer, err := review.GetWriter(target.format)
+	if err != nil {
+		return err
+	}
+
+	if target.path == "" {
+		return writer(os.Stdout, result)
+	}
+
+	f, err := os.Create(target.path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer f.Close()
+
+	if err := writer(f, result); err != nil {
+		return fmt.Errorf("failed to write %s output: %w", target.format, err)
+	}
+
+	out.Printf("📄 %s report written to %s\n", target.format, target.path)
+	return nil
+}
+
+// writeCommentPayload writes the comments a PR/MR poster would send without
+// posting anything
+func writeCommentPayload(path string, result *review.Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create payload file: %w", err)
+	}
+	defer f.Close()
+
+	if err := review.WriteCommentPayload(f, result); err != nil {
+		return fmt.Errorf("failed to write comment payload: %w", err)
+	}
+
+	out.Printf("📄 Comment payload written to %s (nothing was posted)\n", path)
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/git"
+	"github.com/katichai/katich/internal/github"
+	"github.com/katichai/katich/internal/review"
+	"github.com/spf13/cobra"
+)
+
+// prDryRun prints the review instead of posting it
+var prDryRun bool
+
+// reviewPRCmd reviews a GitHub pull request and posts the findings on it
+var reviewPRCmd = &cobra.Command{
+	Use:   "pr <number>",
+	Short: "Review a GitHub pull request and comment on it",
+	Long: `Analyze the changes of a GitHub pull request and post the findings as a
+review: a summary comment plus inline comments on the changed lines.
+
+The repository is taken from the origin remote and the token from
+GITHUB_TOKEN; GITHUB_API_URL selects a GitHub Enterprise server. The review
+is only posted when the pull request head is checked out; --dry-run works
+from any checkout. Commits missing from a shallow checkout are fetched from
+origin.
+
+Examples:
+  katich review pr 42 --dry-run
+  GITHUB_TOKEN=... katich review pr 42`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		number, err := strconv.Atoi(args[0])
+		if err != nil || number <= 0 {
+			return fmt.Errorf("invalid pull request number: %s", args[0])
+		}
+		return runReviewPR(number)
+	},
+}
+
+func init() {
+	reviewPRCmd.Flags().BoolVar(&prDryRun, "dry-run", false, "print the review that would be posted without posting it")
+}
+
+func runReviewPR(number int) error {
+	out.Printf("🔍 Reviewing pull request #%d\n", number)
+	out.Println()
+
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+	repo.IgnoreWhitespace = ignoreWhitespace
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	remoteURL, err := repo.GetRemoteURL("")
+	if err != nil {
+		return err
+	}
+	apiURL := os.Getenv("GITHUB_API_URL")
+	owner, name, err := github.ParseRemoteURL(remoteURL, github.WebHost(apiURL))
+	if err != nil {
+		return err
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" && !prDryRun {
+		return fmt.Errorf("GITHUB_TOKEN is required to post a review (use --dry-run to preview it)")
+	}
+	client := github.NewClient(token, apiURL, owner, name)
+
+	pr, err := client.GetPullRequest(number)
+	if err != nil {
+		return err
+	}
+	out.Printf("📝 %s\n", pr.Title)
+	out.Printf("🌿 %s ← %s\n", pr.Base.Ref, pr.Head.Ref)
+	out.Println()
+
+	// CI checkouts are often shallow or lack the base branch
+	if !repo.RefExists(pr.Base.SHA) || !repo.RefExists(pr.Head.SHA) {
+		if err := repo.Fetch("origin", pr.Base.SHA, fmt.Sprintf("pull/%d/head", number)); err != nil {
+			return err
+		}
+	}
+	// Parts of the analysis, such as test discovery and repository context,
+	// read the working tree, so only a checkout of the head is posted
+	head, err := repo.GetCommit("HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.Hash != pr.Head.SHA {
+		if !prDryRun {
+			return fmt.Errorf("HEAD is %s, not the pull request head %s; check out the head or use --dry-run", head.Hash[:7], pr.Head.SHA[:7])
+		}
+		out.Println("⚠️  HEAD is not the pull request head; parts of the review reflect the checkout")
+		out.Println()
+	}
+
+	result, err := reviewRange(repo, cfg, pr.Base.SHA+"..."+pr.Head.SHA)
+	if err != nil {
+		return err
+	}
+	result.Range = pr.Base.Ref + "..." + pr.Head.Ref
+
+	payload := review.PlanComments(result)
+	comments := make([]github.ReviewComment, 0, len(payload.Comments))
+	for _, comment := range payload.Comments {
+		comments = append(comments, github.ReviewComment{
+			Path: comment.Path,
+			Line: comment.Line,
+			Side: comment.Side,
+			Body: comment.Body,
+		})
+	}
+
+	if prDryRun {
+		out.Printf("📝 Dry run: review for %s with %d inline comment(s) (nothing was posted)\n", pr.URL, len(comments))
+		out.Println()
+		out.Println(payload.ReviewBody())
+		for _, comment := range comments {
+			out.Printf("💬 %s:%d\n%s\n\n", comment.Path, comment.Line, comment.Body)
+		}
+	} else {
+		if err := client.CreateReview(number, pr.Head.SHA, payload.ReviewBody(), comments); err != nil {
+			return err
+		}
+		out.Printf("💬 Posted review with %d inline comment(s) to %s\n", len(comments), pr.URL)
+		out.Println()
+	}
+
+	printReviewSummary(result)
+	return writeReviewOutput(result, cfg)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/katichai/katich/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// reviewReflogCmd reviews what changed between two reflog states
+var reviewReflogCmd = &cobra.Command{
+	Use:   "reflog [range]",
+	Short: "Review changes since an earlier reflog state",
+	Long: `Analyze what actually changed between two reflog entries, e.g. after
+an interactive rebase or an amend. This catches changes that were dropped
+or mangled while resolving conflicts.
+
+A single ref is compared against HEAD. Without arguments, the state before
+the last HEAD movement is used (HEAD@{1}..HEAD).
+
+Examples:
+  katich review reflog
+  katich review reflog HEAD@{1}..HEAD
+  katich review reflog main@{2}`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		diffRange := "HEAD@{1}..HEAD"
+		if len(args) == 1 {
+			diffRange = args[0]
+		}
+		return runReviewReflog(diffRange)
+	},
+}
+
+func runReviewReflog(diffRange string) error {
+	if !strings.Contains(diffRange, "..") {
+		diffRange += "..HEAD"
+	}
+
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+	if err := repo.ValidateRange(diffRange); err != nil {
+		return err
+	}
+
+	return runReviewDiff(diffRange)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// reviewStagedCmd reviews changes staged for the next commit
+var reviewStagedCmd = &cobra.Command{
+	Use:   "staged",
+	Short: "Review changes staged for commit",
+	Long: `Analyze the changes in the staging area, like 'git diff --cached'.
+Useful in a pre-commit hook, before anything is committed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReviewWorktree(true)
+	},
+}
+
+// reviewWorkingCmd reviews unstaged changes in the working tree
+var reviewWorkingCmd = &cobra.Command{
+	Use:   "working",
+	Short: "Review unstaged changes in the working tree",
+	Long:  `Analyze the changes in the working tree that are not staged yet, like 'git diff'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReviewWorktree(false)
+	},
+}
+
+// runReviewWorktree reviews staged changes against HEAD, or unstaged
+// changes against the staging area
+func runReviewWorktree(staged bool) error {
+	label, baseRef, headRef := "working tree", git.IndexRef, ""
+	if staged {
+		label, baseRef, headRef = "staged changes", "HEAD", git.IndexRef
+	}
+
+	out.Printf("🔍 Reviewing %s...\n", label)
+	out.Println()
+
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+	repo.IgnoreWhitespace = ignoreWhitespace
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var diff *git.Diff
+	if staged {
+		diff, err = repo.GetStagedDiff()
+	} else {
+		diff, err = repo.GetWorkingTreeDiff()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	if len(diff.Files) == 0 {
+		out.Printf("✅ No %s to review\n", label)
+		out.Println()
+	} else {
+		printDiffChanges(diff, true)
+	}
+
+	out.Println("🔬 Analyzing changed files...")
+	result, err := analyzeDiff(repo, cfg, diff, baseRef, headRef)
+	if err != nil {
+		out.Printf("⚠️  Analysis error: %v\n", err)
+	}
+	result.Range = label
+	printSymbolChanges(result)
+	printReviewFindings(result)
+	out.Println()
+
+	printReviewSummary(result)
+	return writeReviewOutput(result, cfg)
+}
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
+	"github.com/katichai/katich/internal/analysis"
 	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/embeddings"
 	"github.com/katichai/katich/internal/git"
 	"github.com/spf13/cobra"
 )
@@ -16,7 +20,11 @@ var (
 
 	// Global flags
 	verbose    bool
+	quiet      bool
+	noEmoji    bool
 	configFile string
+	onlyChecks []string
+	skipChecks []string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -32,24 +40,60 @@ embeddings, and framework detection, then performs intelligent code reviews
 on git diffs.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		configureOutput()
+		startAuditLog(cmd, args)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	finishAuditLog(err)
+	return err
 }
 
 func init() {
+	// Run the root pre-run hook that opens the audit log as well as the
+	// hooks of command groups such as review
+	cobra.EnableTraverseRunHooks = true
+
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress all non-error output")
+	rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "print ASCII markers such as [OK] instead of emoji (also set by NO_COLOR or CI)")
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "config file (default is .katich/config.yaml)")
+	rootCmd.PersistentFlags().StringSliceVar(&onlyChecks, "only", nil, "run only these checks, e.g. complexity,duplication")
+	rootCmd.PersistentFlags().StringSliceVar(&skipChecks, "skip", nil, "skip these checks, e.g. naming")
 
 	// Add subcommands
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(contextCmd)
 	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(analyzeCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(trendCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(metricsCmd)
+	rootCmd.AddCommand(duplicatesCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(profilesCmd)
+}
+
+// applyCheckFilters merges --only and --skip into the analysis config and
+// validates every selected check name against the catalog
+func applyCheckFilters(cfg *config.Config) error {
+	if len(onlyChecks) > 0 {
+		cfg.Analysis.OnlyChecks = onlyChecks
+	}
+	cfg.Analysis.SkipChecks = append(cfg.Analysis.SkipChecks, skipChecks...)
+
+	if err := analysis.ValidateCheckNames(cfg.Analysis.OnlyChecks); err != nil {
+		return err
+	}
+	return analysis.ValidateCheckNames(cfg.Analysis.SkipChecks)
 }
 
 // GetVerbose returns the verbose flag value
@@ -57,6 +101,11 @@ func GetVerbose() bool {
 	return verbose
 }
 
+// GetQuiet returns the quiet flag value
+func GetQuiet() bool {
+	return quiet
+}
+
 // GetConfig returns the config file path
 func GetConfig() string {
 	return configFile
@@ -68,9 +117,9 @@ var versionCmd = &cobra.Command{
 	Short: "Display version information",
 	Long:  `Display the version, git commit, and build date of katich.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("katich version %s\n", Version)
-		fmt.Printf("Git commit: %s\n", GitCommit)
-		fmt.Printf("Build date: %s\n", BuildDate)
+		fmt.Fprintf(stdout, "katich version %s\n", Version)
+		fmt.Fprintf(stdout, "Git commit: %s\n", GitCommit)
+		fmt.Fprintf(stdout, "Build date: %s\n", BuildDate)
 	},
 }
 
@@ -86,8 +135,8 @@ This includes checking for Git, required Go packages, LLM API keys, and more.`,
 }
 
 func runDoctor() error {
-	fmt.Println("🔍 Running system diagnostics...")
-	fmt.Println()
+	out.Println("🔍 Running system diagnostics...")
+	out.Println()
 
 	checks := make([]struct {
 		name   string
@@ -137,7 +186,9 @@ func runDoctor() error {
 	
 	cfg, err := config.Load(configPath)
 	configStatus := "⚠️  Not found (optional)"
-	if err == nil {
+	if err != nil {
+		configStatus = fmt.Sprintf("❌ %v", err)
+	} else if _, statErr := os.Stat(configPath); statErr == nil {
 		configStatus = "✅ Found"
 	}
 	checks = append(checks, struct {
@@ -155,23 +206,106 @@ func runDoctor() error {
 		status string
 	}{"LLM API key", llmStatus})
 
-	// Check embedding model
-	embeddingStatus := "⚠️  Not configured"
-	if cfg != nil && cfg.Embeddings.Model != "" {
-		embeddingStatus = fmt.Sprintf("✅ Configured (%s)", cfg.Embeddings.Model)
+	// Probe the embedding provider context builds would use, with the
+	// endpoint and model from the embeddings config
+	embeddingCfg := cfg
+	if embeddingCfg == nil {
+		embeddingCfg = config.DefaultConfig()
 	}
+	provider := newEmbeddingProvider(embeddingCfg)
+	providerStatus, ollamaStatus := embeddingProviderStatus(provider)
+	checks = append(checks, struct {
+		name   string
+		status string
+	}{"Embedding provider", providerStatus})
+	checks = append(checks, struct {
+		name   string
+		status string
+	}{"Ollama", ollamaStatus})
+
+	// Check the embedding index and that the active provider can extend it
+	indexStatus := "⚠️  Not in a Git repository"
+	dimensionStatus := "⚠️  No embedding index"
+	if repo != nil {
+		indexStatus, dimensionStatus = embeddingIndexStatus(repo, provider)
+	}
+	checks = append(checks, struct {
+		name   string
+		status string
+	}{"Embedding index", indexStatus})
+	checks = append(checks, struct {
+		name   string
+		status string
+	}{"Embedding dimension", dimensionStatus})
+
+	// Language support
 	checks = append(checks, struct {
 		name   string
 		status string
-	}{"Embedding model", embeddingStatus})
+	}{"Language support", "ℹ️  " + analysis.SupportSummary()})
 
 	// Print all checks
 	for _, check := range checks {
-		fmt.Printf("%-30s %s\n", check.name+":", check.status)
+		fmt.Fprintf(stdout, "%-30s %s\n", check.name+":", check.status)
 	}
 
-	fmt.Println()
-	fmt.Println("💡 Tip: Create a .katich/config.yaml file to configure LLM and embedding settings")
+	out.Println()
+	out.Println("💡 Tip: Run 'katich init' to create .katich/config.yaml and build the initial context")
 	
 	return nil
 }
+
+// embeddingProviderStatus describes the provider a context build would use
+// and whether Ollama answered at the configured address
+func embeddingProviderStatus(provider loggedProvider) (active, ollama string) {
+	switch provider.GetName() {
+	case "None":
+		active = "❌ None available (start Ollama or set an OpenAI key for embeddings)"
+	default:
+		active = fmt.Sprintf("✅ %s, model %s", provider.GetActiveProvider(), provider.GetModel())
+	}
+
+	local := provider.Ollama()
+	switch {
+	case local == nil:
+		ollama = "ℹ️  Not used (embeddings.provider is api)"
+	case provider.GetName() == local.GetName():
+		ollama = fmt.Sprintf("✅ Reachable at %s", local.GetURL())
+	default:
+		ollama = fmt.Sprintf("⚠️  Unreachable at %s, so model %s is not used", local.GetURL(), local.GetModel())
+	}
+	return active, ollama
+}
+
+// embeddingIndexStatus describes the repository's embedding index and
+// whether its dimension matches the embedding provider that would be used
+func embeddingIndexStatus(repo *git.Repository, provider loggedProvider) (index, dimension string) {
+	indexPath := embeddings.IndexPath(filepath.Join(repo.RootPath, ".katich"))
+	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+		return "⚠️  Not found (run 'katich context build')", "⚠️  No embedding index"
+	}
+
+	stored, err := embeddings.LoadIndex(indexPath)
+	if err != nil {
+		return fmt.Sprintf("❌ Unreadable: %v", err), "⚠️  No embedding index"
+	}
+	builtBy := stored.Provider
+	if stored.Model != "" {
+		builtBy += " " + stored.Model
+	}
+	index = fmt.Sprintf("✅ %d vectors (%s, dimension %d)", len(stored.Embeddings), builtBy, stored.Dimension)
+
+	switch {
+	case provider.GetName() == "None":
+		dimension = "⚠️  No embedding provider available"
+	case provider.GetDimension() != stored.Dimension:
+		dimension = fmt.Sprintf("❌ Index has %d but %s uses %d (run 'katich context build --force')",
+			stored.Dimension, provider.GetName(), provider.GetDimension())
+	case stored.Model != "" && stored.Model != provider.GetModel():
+		dimension = fmt.Sprintf("❌ Index was built with %s but %s is configured (run 'katich context build --force')",
+			stored.Model, provider.GetModel())
+	default:
+		dimension = fmt.Sprintf("✅ %d matches %s", stored.Dimension, provider.GetName())
+	}
+	return index, dimension
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/katichai/katich/internal/config"
+	"github.com/katichai/katich/internal/git"
+	"github.com/katichai/katich/internal/history"
+	"github.com/spf13/cobra"
+)
+
+// trendLimit is the number of builds shown by the trend command
+var trendLimit int
+
+func init() {
+	trendCmd.Flags().IntVarP(&trendLimit, "limit", "n", 10, "number of recent builds to show")
+}
+
+// trendCmd shows how repository metrics change across context builds
+var trendCmd = &cobra.Command{
+	Use:   "trend",
+	Short: "Show metric trends across context builds",
+	Long: `Show how lines of code, issues and debt marker comments changed across
+recent 'katich context build' runs, as recorded in .katich/history.json.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTrend()
+	},
+}
+
+func runTrend() error {
+	repo, err := git.FindRepository()
+	if err != nil {
+		return fmt.Errorf("failed to find Git repository: %w", err)
+	}
+
+	cfg, err := config.Load(GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	buildHistory, err := history.Load(history.DefaultPath(repo.RootPath))
+	if err != nil {
+		return err
+	}
+
+	if len(buildHistory.Entries) == 0 {
+		out.Println("⚠️  No build history found. Run 'katich context build' first.")
+		return nil
+	}
+
+	entries := buildHistory.Entries
+	start := 0
+	if trendLimit > 0 && len(entries) > trendLimit {
+		start = len(entries) - trendLimit
+	}
+
+	out.Println("📈 Build Trends")
+	out.Println()
+	fmt.Fprintf(stdout, "%-17s  %-8s  %6s  %8s  %6s  %6s  %s\n", "BUILD", "COMMIT", "FILES", "LOC", "ISSUES", "TODOS", "CHANGE")
+
+	for i := start; i < len(entries); i++ {
+		entry := entries[i]
+		change := ""
+		if i > 0 {
+			delta := entry.DebtMarkers - entries[i-1].DebtMarkers
+			change = formatDebtDelta(delta)
+			if debtGrowthExceeded(delta, cfg.Analysis.MaxDebtGrowth) {
+				change += " ⚠️"
+			}
+		}
+
+		commit := entry.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+
+		fmt.Fprintf(stdout, "%-17s  %-8s  %6d  %8d  %6d  %6d  %s\n",
+			entry.Timestamp.Format("2006-01-02 15:04"), commit,
+			entry.Files, entry.LinesOfCode, entry.Issues, entry.DebtMarkers, change)
+	}
+
+	if len(entries) > 1 {
+		first, last := entries[start], entries[len(entries)-1]
+		out.Println()
+		out.Printf("Over the last %d build(s): %s\n", len(entries)-start, formatDebtDelta(last.DebtMarkers-first.DebtMarkers))
+	}
+
+	return nil
+}
+
+// formatDebtDelta describes a change in debt markers, e.g. "+12 debt markers"
+func formatDebtDelta(delta int) string {
+	if delta == 0 {
+		return "no change in debt markers"
+	}
+	return fmt.Sprintf("%+d debt markers", delta)
+}
+
+// debtGrowthExceeded reports whether debt markers grew faster than allowed.
+// A limit of 0 disables the check.
+func debtGrowthExceeded(delta, limit int) bool {
+	return limit > 0 && delta > limit
+}
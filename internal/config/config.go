@@ -1,40 +1,130 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// ErrMissingAPIKey is returned by Validate when the LLM provider needs an
+// API key and none is configured
+var ErrMissingAPIKey = errors.New("LLM API key is required")
+
 // Config represents the application configuration
 type Config struct {
+	Profile    string           `yaml:"profile,omitempty"` // built-in preset applied before the rest of the file
 	LLM        LLMConfig        `yaml:"llm"`
 	Embeddings EmbeddingsConfig `yaml:"embeddings"`
 	Analysis   AnalysisConfig   `yaml:"analysis"`
+	Review     ReviewConfig     `yaml:"review"`
+	Cache      CacheConfig      `yaml:"cache"`
+	Log        LogConfig        `yaml:"log"`
 }
 
 // LLMConfig contains LLM provider settings
 type LLMConfig struct {
-	Provider string `yaml:"provider"` // openai, anthropic, local
-	APIKey   string `yaml:"api_key"`
-	Model    string `yaml:"model"`
-	BaseURL  string `yaml:"base_url,omitempty"` // for local LLMs
+	Provider      string `yaml:"provider"` // openai, anthropic, local
+	APIKey        string `yaml:"api_key"`
+	APIKeyFile    string `yaml:"api_key_file,omitempty"`    // file containing the API key
+	APIKeyKeyring string `yaml:"api_key_keyring,omitempty"` // OS keyring account holding the API key
+	Model         string `yaml:"model"`
+	BaseURL       string `yaml:"base_url,omitempty"` // for local LLMs
+
+	keyResolved bool // APIKey came from a file, keyring or env var
 }
 
 // EmbeddingsConfig contains embedding model settings
 type EmbeddingsConfig struct {
-	Model    string `yaml:"model"`     // jina-code-v2, bge-code, nomic-embed, snowflake-arctic
-	Provider string `yaml:"provider"`  // local, api
-	APIKey   string `yaml:"api_key,omitempty"`
+	Model            string `yaml:"model,omitempty"`    // Ollama model for local, OpenAI model for api; empty uses the provider's default
+	Provider         string `yaml:"provider"`           // local (Ollama, falling back to OpenAI), api (OpenAI only)
+	BaseURL          string `yaml:"base_url,omitempty"` // Ollama server, default http://localhost:11434
+	APIKey           string `yaml:"api_key,omitempty"`
+	APIKeyFile       string `yaml:"api_key_file,omitempty"`    // file containing the API key
+	APIKeyKeyring    string `yaml:"api_key_keyring,omitempty"` // OS keyring account holding the API key
+	MinFunctionLines int    `yaml:"min_function_lines"`        // shorter functions are not embedded, 0 embeds all
+
+	keyResolved bool // APIKey came from a file, keyring or env var
 }
 
 // AnalysisConfig contains code analysis thresholds
 type AnalysisConfig struct {
-	MaxFunctionLength   int     `yaml:"max_function_length"`
-	ComplexityThreshold int     `yaml:"complexity_threshold"`
-	SimilarityThreshold float64 `yaml:"similarity_threshold"`
+	MaxFunctionLength   int      `yaml:"max_function_length"`
+	ComplexityThreshold int      `yaml:"complexity_threshold"`
+	SimilarityThreshold float64  `yaml:"similarity_threshold"`
+	MaxReturns          int      `yaml:"max_returns"` // 0 disables the check
+	EnabledChecks       []string `yaml:"enabled_checks,omitempty"` // opt-in checks, e.g. error_wrapping, or "all"
+	MaxDebtGrowth       int      `yaml:"max_debt_growth"` // new debt_markers comments allowed per build, 0 disables
+	MinDuplicateLines   int      `yaml:"min_duplicate_lines"` // smallest duplicated code block reported, 0 disables
+	OnlyChecks          []string `yaml:"only_checks,omitempty"` // report only these checks
+	SkipChecks          []string `yaml:"skip_checks,omitempty"` // never report these checks
+	HostAllowlist       []string `yaml:"host_allowlist"` // hosts never reported as hardcoded, including subdomains
+	CriticalPaths       []CriticalPath `yaml:"critical_paths,omitempty"` // paths whose issues weigh more in the quality score
+	FailOn              string   `yaml:"fail_on"` // in CI mode, fail the review on any issue of this severity or worse (info, warning, error)
+	DebtMarkers         []string `yaml:"debt_markers"` // comment markers reported as tech debt, e.g. TODO; empty disables the check
+	DebtMarkerSeverity  string   `yaml:"debt_marker_severity"` // severity of tech debt markers (info, warning, error)
+}
+
+// CriticalPath marks a directory, file or glob as more important than the
+// rest of the repository
+type CriticalPath struct {
+	Path   string  `yaml:"path"`
+	Weight float64 `yaml:"weight"`            // multiplies the score penalty of each issue, 0 means 1
+	FailOn string  `yaml:"fail_on,omitempty"` // in CI mode, fail the review on any issue of this severity or worse (info, warning, error)
+}
+
+// Matches reports whether a repository-relative path falls under the
+// critical path, either inside it or matching it as a glob
+func (p CriticalPath) Matches(path string) bool {
+	prefix := filepath.ToSlash(filepath.Clean(p.Path))
+	if path == prefix || strings.HasPrefix(path, prefix+"/") {
+		return true
+	}
+	matched, _ := filepath.Match(prefix, path)
+	return matched
+}
+
+// CriticalPath returns the first critical path that matches a file, or nil
+func (c AnalysisConfig) CriticalPath(path string) *CriticalPath {
+	for i := range c.CriticalPaths {
+		if c.CriticalPaths[i].Matches(path) {
+			return &c.CriticalPaths[i]
+		}
+	}
+	return nil
+}
+
+// ReviewConfig controls how much source the LLM sees for each changed file
+type ReviewConfig struct {
+	ContextLines    int  `yaml:"context_lines"`     // lines around each hunk, 0 sends only the hunk
+	IncludeFullFile bool `yaml:"include_full_file"` // send whole files up to max_file_bytes
+	MaxFileBytes    int  `yaml:"max_file_bytes"`    // larger files fall back to hunks with context
+}
+
+// CacheConfig bounds the size of .katich/cache
+type CacheConfig struct {
+	MaxSizeMB int `yaml:"max_size_mb"` // least recently used entries are evicted beyond this, 0 disables
+}
+
+// MaxBytes returns the cache size limit in bytes
+func (c CacheConfig) MaxBytes() int64 {
+	return int64(c.MaxSizeMB) * 1024 * 1024
+}
+
+// LogConfig controls the structured audit log written to .katich/logs
+type LogConfig struct {
+	File       string `yaml:"file,omitempty"` // log file, relative to .katich/logs; empty disables logging
+	Level      string `yaml:"level"`          // debug, info, warn or error
+	MaxSizeMB  int    `yaml:"max_size_mb"`    // the file is rotated at the start of a run beyond this, 0 disables
+	MaxBackups int    `yaml:"max_backups"`    // rotated files kept next to the log
+}
+
+// MaxBytes returns the rotation threshold in bytes
+func (c LogConfig) MaxBytes() int64 {
+	return int64(c.MaxSizeMB) * 1024 * 1024
 }
 
 // DefaultConfig returns a configuration with sensible defaults
@@ -45,13 +135,31 @@ func DefaultConfig() *Config {
 			Model:    "gpt-4",
 		},
 		Embeddings: EmbeddingsConfig{
-			Model:    "jina-code-v2",
-			Provider: "local",
+			Provider:         "local",
+			MinFunctionLines: 3,
 		},
 		Analysis: AnalysisConfig{
 			MaxFunctionLength:   50,
 			ComplexityThreshold: 10,
 			SimilarityThreshold: 0.85,
+			MaxDebtGrowth:       10,
+			MinDuplicateLines:   6,
+			FailOn:              "error",
+			DebtMarkers:         []string{"TODO", "FIXME", "HACK", "XXX"},
+			DebtMarkerSeverity:  "info",
+			HostAllowlist:       []string{"localhost", "127.0.0.1", "0.0.0.0", "::1", "example.com", "example.org", "example.net", "www.w3.org"},
+		},
+		Review: ReviewConfig{
+			ContextLines: 10,
+			MaxFileBytes: 32 * 1024,
+		},
+		Cache: CacheConfig{
+			MaxSizeMB: 256,
+		},
+		Log: LogConfig{
+			Level:      "info",
+			MaxSizeMB:  10,
+			MaxBackups: 3,
 		},
 	}
 }
@@ -81,6 +189,30 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// Apply the profile under the file's explicit values by parsing again
+	if config.Profile != "" {
+		profile, err := GetProfile(config.Profile)
+		if err != nil {
+			return nil, err
+		}
+		config = DefaultConfig()
+		profile.Apply(config)
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+
+	// Reject invalid settings now rather than when they are first used.
+	// API keys are checked by the commands that need them.
+	if err := config.validateSettings(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	// Resolve keys stored outside the config file
+	if err := config.resolveSecrets(); err != nil {
+		return nil, err
+	}
+
 	// Override with environment variables if set
 	config.overrideFromEnv()
 
@@ -91,12 +223,19 @@ func Load(path string) (*Config, error) {
 func (c *Config) overrideFromEnv() {
 	if apiKey := os.Getenv("KATICH_LLM_API_KEY"); apiKey != "" {
 		c.LLM.APIKey = apiKey
+		c.LLM.keyResolved = true
 	}
 	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" && c.LLM.Provider == "openai" {
 		c.LLM.APIKey = apiKey
+		c.LLM.keyResolved = true
 	}
 	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" && c.LLM.Provider == "anthropic" {
 		c.LLM.APIKey = apiKey
+		c.LLM.keyResolved = true
+	}
+	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" && c.Embeddings.APIKey == "" {
+		c.Embeddings.APIKey = apiKey
+		c.Embeddings.keyResolved = true
 	}
 }
 
@@ -113,8 +252,17 @@ func (c *Config) Save(path string) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	// Never persist keys that were resolved from a file, keyring or env var
+	persisted := *c
+	if persisted.LLM.keyResolved {
+		persisted.LLM.APIKey = ""
+	}
+	if persisted.Embeddings.keyResolved {
+		persisted.Embeddings.APIKey = ""
+	}
+
 	// Marshal to YAML
-	data, err := yaml.Marshal(c)
+	data, err := yaml.Marshal(&persisted)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
@@ -127,30 +275,111 @@ func (c *Config) Save(path string) error {
 	return nil
 }
 
-// Validate checks if the configuration is valid
+// Validate checks if the configuration is valid, including that the LLM
+// provider has an API key
 func (c *Config) Validate() error {
-	// Check LLM configuration
-	if c.LLM.Provider == "" {
-		return fmt.Errorf("LLM provider is required")
+	if err := c.validateSettings(); err != nil {
+		return err
 	}
 	if c.LLM.Provider != "local" && c.LLM.APIKey == "" {
-		return fmt.Errorf("LLM API key is required for provider: %s", c.LLM.Provider)
+		return fmt.Errorf("%w for provider: %s", ErrMissingAPIKey, c.LLM.Provider)
+	}
+	return nil
+}
+
+// validateSettings checks every setting except the API keys, which may be
+// resolved later or not needed by the command at hand
+func (c *Config) validateSettings() error {
+	// Check LLM configuration
+	switch c.LLM.Provider {
+	case "":
+		return fmt.Errorf("llm.provider is required")
+	case "openai", "anthropic", "local":
+	default:
+		return fmt.Errorf("llm.provider must be openai, anthropic or local, got %q", c.LLM.Provider)
 	}
 
 	// Check embeddings configuration
-	if c.Embeddings.Model == "" {
-		return fmt.Errorf("embeddings model is required")
+	switch c.Embeddings.Provider {
+	case "local", "api":
+	default:
+		return fmt.Errorf("embeddings.provider must be local or api, got %q", c.Embeddings.Provider)
+	}
+	if c.Embeddings.MinFunctionLines < 0 {
+		return fmt.Errorf("embeddings.min_function_lines must not be negative, got %d", c.Embeddings.MinFunctionLines)
 	}
 
 	// Check analysis thresholds
 	if c.Analysis.MaxFunctionLength <= 0 {
-		return fmt.Errorf("max_function_length must be positive")
+		return fmt.Errorf("analysis.max_function_length must be positive, got %d", c.Analysis.MaxFunctionLength)
 	}
 	if c.Analysis.ComplexityThreshold <= 0 {
-		return fmt.Errorf("complexity_threshold must be positive")
+		return fmt.Errorf("analysis.complexity_threshold must be positive, got %d", c.Analysis.ComplexityThreshold)
 	}
 	if c.Analysis.SimilarityThreshold < 0 || c.Analysis.SimilarityThreshold > 1 {
-		return fmt.Errorf("similarity_threshold must be between 0 and 1")
+		return fmt.Errorf("analysis.similarity_threshold must be between 0 and 1, got %g", c.Analysis.SimilarityThreshold)
+	}
+	if c.Analysis.MaxReturns < 0 {
+		return fmt.Errorf("analysis.max_returns must not be negative, got %d", c.Analysis.MaxReturns)
+	}
+	if c.Analysis.MaxDebtGrowth < 0 {
+		return fmt.Errorf("analysis.max_debt_growth must not be negative, got %d", c.Analysis.MaxDebtGrowth)
+	}
+	if c.Analysis.MinDuplicateLines < 0 {
+		return fmt.Errorf("analysis.min_duplicate_lines must not be negative, got %d", c.Analysis.MinDuplicateLines)
+	}
+
+	switch c.Analysis.FailOn {
+	case "info", "warning", "error":
+	default:
+		return fmt.Errorf("analysis.fail_on must be info, warning or error, got %q", c.Analysis.FailOn)
+	}
+	switch c.Analysis.DebtMarkerSeverity {
+	case "info", "warning", "error":
+	default:
+		return fmt.Errorf("analysis.debt_marker_severity must be info, warning or error, got %q", c.Analysis.DebtMarkerSeverity)
+	}
+	for _, marker := range c.Analysis.DebtMarkers {
+		if strings.TrimSpace(marker) == "" {
+			return fmt.Errorf("analysis.debt_markers entries must not be empty")
+		}
+	}
+
+	for _, critical := range c.Analysis.CriticalPaths {
+		if critical.Path == "" {
+			return fmt.Errorf("analysis.critical_paths entries need a path")
+		}
+		if critical.Weight < 0 {
+			return fmt.Errorf("analysis.critical_paths %s: weight must not be negative", critical.Path)
+		}
+		switch critical.FailOn {
+		case "", "info", "warning", "error":
+		default:
+			return fmt.Errorf("analysis.critical_paths %s: fail_on must be info, warning or error", critical.Path)
+		}
+	}
+
+	// Check review prompt settings
+	if c.Review.ContextLines < 0 {
+		return fmt.Errorf("review.context_lines must not be negative, got %d", c.Review.ContextLines)
+	}
+	if c.Review.MaxFileBytes < 0 {
+		return fmt.Errorf("review.max_file_bytes must not be negative, got %d", c.Review.MaxFileBytes)
+	}
+
+	// Check cache settings
+	if c.Cache.MaxSizeMB < 0 {
+		return fmt.Errorf("cache.max_size_mb must not be negative, got %d", c.Cache.MaxSizeMB)
+	}
+
+	// Check audit log settings
+	switch strings.ToLower(c.Log.Level) {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		return fmt.Errorf("log.level must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	if c.Log.MaxSizeMB < 0 || c.Log.MaxBackups < 0 {
+		return fmt.Errorf("log.max_size_mb and log.max_backups must not be negative")
 	}
 
 	return nil
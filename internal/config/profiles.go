@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile is a named preset of analysis settings. A profile is applied on
+// top of the defaults, so anything set explicitly in the config file still
+// wins.
+type Profile struct {
+	Name        string
+	Description string
+	Apply       func(c *Config)
+}
+
+// AllChecks in enabled_checks turns on every opt-in check, including ones
+// added in later versions
+const AllChecks = "all"
+
+// profiles are the built-in presets selectable with `profile:`
+var profiles = map[string]Profile{
+	"strict": {
+		Name:        "strict",
+		Description: "Low thresholds and every opt-in check enabled",
+		Apply: func(c *Config) {
+			c.Analysis.MaxFunctionLength = 30
+			c.Analysis.ComplexityThreshold = 7
+			c.Analysis.MaxReturns = 4
+			c.Analysis.MaxDebtGrowth = 1
+			c.Analysis.EnabledChecks = []string{AllChecks}
+		},
+	},
+	"legacy": {
+		Name:        "legacy",
+		Description: "Lenient thresholds for older codebases; style nits and debt growth are not reported",
+		Apply: func(c *Config) {
+			c.Analysis.MaxFunctionLength = 100
+			c.Analysis.ComplexityThreshold = 20
+			c.Analysis.MaxDebtGrowth = 0
+			c.Analysis.SkipChecks = []string{"style_violation", "parameter_struct", "simplification"}
+		},
+	},
+	"security": {
+		Name:        "security",
+		Description: "Only hardcoded secrets and hosts, ignored errors, unwrapped errors and panics",
+		Apply: func(c *Config) {
+			c.Analysis.EnabledChecks = []string{"error_wrapping", "ignored_error", "panic"}
+			c.Analysis.OnlyChecks = []string{"hardcoded_secret", "hardcoded_host", "empty_error_handling", "error_wrapping", "ignored_error", "panic"}
+		},
+	},
+}
+
+// GetProfile returns a built-in profile by name
+func GetProfile(name string) (Profile, error) {
+	profile, ok := profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for _, p := range Profiles() {
+			names = append(names, p.Name)
+		}
+		return Profile{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	return profile, nil
+}
+
+// Profiles returns the built-in profiles sorted by name
+func Profiles() []Profile {
+	list := make([]Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		list = append(list, profile)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// keyringService is the service name katich secrets are stored under
+const keyringService = "katich"
+
+// Keyring retrieves secrets from an operating system credential store
+type Keyring interface {
+	Get(service, account string) (string, error)
+}
+
+// DefaultKeyring is used to resolve api_key_keyring entries. It shells out
+// to `security` on macOS and `secret-tool` (libsecret) on Linux.
+var DefaultKeyring Keyring = systemKeyring{}
+
+type systemKeyring struct{}
+
+// Get looks up a secret in the system keyring
+func (systemKeyring) Get(service, account string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return "", fmt.Errorf("keyring is not supported on %s", runtime.GOOS)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("keyring lookup failed for %s/%s: %w", service, account, err)
+	}
+
+	secret := strings.TrimSpace(string(output))
+	if secret == "" {
+		return "", fmt.Errorf("keyring entry %s/%s is empty", service, account)
+	}
+
+	return secret, nil
+}
+
+// resolveSecrets loads API keys referenced by api_key_file or
+// api_key_keyring into memory. Resolved keys are never written by Save.
+func (c *Config) resolveSecrets() error {
+	key, ok, err := resolveSecret(c.LLM.APIKeyFile, c.LLM.APIKeyKeyring)
+	if err != nil {
+		return fmt.Errorf("failed to resolve LLM API key: %w", err)
+	}
+	if ok {
+		c.LLM.APIKey = key
+		c.LLM.keyResolved = true
+	}
+
+	key, ok, err = resolveSecret(c.Embeddings.APIKeyFile, c.Embeddings.APIKeyKeyring)
+	if err != nil {
+		return fmt.Errorf("failed to resolve embeddings API key: %w", err)
+	}
+	if ok {
+		c.Embeddings.APIKey = key
+		c.Embeddings.keyResolved = true
+	}
+
+	return nil
+}
+
+// resolveSecret reads a key from a file or the keyring. The boolean result
+// reports whether a source was configured.
+func resolveSecret(file, keyringAccount string) (string, bool, error) {
+	if file != "" {
+		key, err := readSecretFile(file)
+		return key, true, err
+	}
+	if keyringAccount != "" {
+		key, err := DefaultKeyring.Get(keyringService, keyringAccount)
+		return key, true, err
+	}
+	return "", false, nil
+}
+
+// readSecretFile reads a secret from a file, expanding a leading ~ and
+// trimming surrounding whitespace
+func readSecretFile(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("key file %s is empty", path)
+	}
+
+	return key, nil
+}
+
+// MaskSecret hides all but the last four characters of a secret
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 8 {
+		return "********"
+	}
+	return "********" + secret[len(secret)-4:]
+}
+
+// Redacted returns a copy of the config with API keys masked for display
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.LLM.APIKey = MaskSecret(c.LLM.APIKey)
+	redacted.Embeddings.APIKey = MaskSecret(c.Embeddings.APIKey)
+	return &redacted
+}
@@ -5,12 +5,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 )
 
+// frameworkScanBytes bounds how much of each file is searched for framework
+// indicators, which appear among the imports and setup near the top
+const frameworkScanBytes = 64 * 1024
+
 // Detector detects frameworks and languages in a repository
 type Detector struct {
 	rootPath string
+	files    *FileCache // shared with analysis; nil reads from disk
 }
 
 // NewDetector creates a new framework/language detector
@@ -22,10 +29,18 @@ func NewDetector(rootPath string) *Detector {
 
 // DetectionResult contains detected frameworks and languages
 type DetectionResult struct {
-	Languages  map[Language]int       `json:"languages"`
-	Frameworks []Framework            `json:"frameworks"`
-	Patterns   []string               `json:"patterns"`
-	Files      map[string]interface{} `json:"files"`
+	Languages     map[Language]int       `json:"languages"`      // source files by language
+	LanguageLines map[Language]int       `json:"language_lines"` // non-blank lines by language
+	Frameworks    []Framework            `json:"frameworks"`
+	Patterns      []string               `json:"patterns"`
+	Files         map[string]interface{} `json:"files"`
+	Modules       []ModuleInfo           `json:"modules"`
+}
+
+// SetFileCache makes the detector read source files through a cache shared
+// with later steps of a build, such as the analyzer
+func (d *Detector) SetFileCache(files *FileCache) {
+	d.files = files
 }
 
 // Detect performs framework and language detection
@@ -38,16 +53,25 @@ func (d *Detector) Detect() (*DetectionResult, error) {
 	}
 
 	// Scan repository for files
-	files, err := d.scanRepository()
+	files, manifests, err := d.scanRepository()
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan repository: %w", err)
 	}
 
-	// Detect languages
-	result.Languages = DetectLanguages(files)
+	// Detect languages, from absolute paths so scripts without an
+	// extension can be read for their shebang
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = filepath.Join(d.rootPath, file)
+	}
+	result.Languages = DetectLanguages(paths)
+	result.LanguageLines = detectLanguageLOC(files, d.rootPath, d.files)
+
+	// Detect the subprojects of a monorepo
+	result.Modules = d.detectModules(files, manifests)
 
 	// Detect frameworks
-	frameworks, err := d.detectFrameworks(files)
+	frameworks, err := d.detectFrameworks(files, result.Modules)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect frameworks: %w", err)
 	}
@@ -63,15 +87,27 @@ func (d *Detector) Detect() (*DetectionResult, error) {
 	return result, nil
 }
 
-// scanRepository scans the repository and returns all source files
-func (d *Detector) scanRepository() ([]string, error) {
+// scanRepository scans the repository and returns all source files, and
+// the module manifests found by directory
+func (d *Detector) scanRepository() ([]string, map[string][]string, error) {
 	files := make([]string, 0)
+	manifests := make(map[string][]string)
+	ignore := LoadIgnore(d.rootPath)
 
 	err := filepath.Walk(d.rootPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
+		// Skip paths excluded by .gitignore or .katichignore
+		relPath, _ := filepath.Rel(d.rootPath, path)
+		if relPath != "." && ignore.Match(relPath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		// Skip hidden directories and common ignore patterns
 		if info.IsDir() {
 			name := info.Name()
@@ -87,27 +123,35 @@ func (d *Detector) scanRepository() ([]string, error) {
 			return nil
 		}
 
+		if moduleManifests[info.Name()] {
+			dir := filepath.ToSlash(filepath.Dir(relPath))
+			manifests[dir] = append(manifests[dir], info.Name())
+		}
+
 		// Only include source files
 		if IsSourceFile(path) {
-			relPath, _ := filepath.Rel(d.rootPath, path)
 			files = append(files, relPath)
 		}
 
 		return nil
 	})
 
-	return files, err
+	return files, manifests, err
 }
 
-// detectFrameworks detects frameworks based on files and content
-func (d *Detector) detectFrameworks(files []string) ([]Framework, error) {
+// detectFrameworks detects frameworks based on files and content, including
+// those declared by the package files of nested modules
+func (d *Detector) detectFrameworks(files []string, modules []ModuleInfo) ([]Framework, error) {
 	frameworks := make([]Framework, 0)
 	detected := make(map[string]bool)
 
 	registry := GetFrameworkRegistry()
 
 	// Check package files first (most reliable)
-	packageFrameworks := d.detectFromPackageFiles()
+	packageFrameworks := d.detectFromPackageFiles(".")
+	for _, module := range modules {
+		packageFrameworks = append(packageFrameworks, module.Frameworks...)
+	}
 	for _, fw := range packageFrameworks {
 		if !detected[fw.Name] {
 			frameworks = append(frameworks, fw)
@@ -115,65 +159,138 @@ func (d *Detector) detectFrameworks(files []string) ([]Framework, error) {
 		}
 	}
 
-	// Check file patterns and content
+	// Check file content for the remaining frameworks, each file only for
+	// the frameworks of its language
+	pending := make(map[Language][]FrameworkInfo)
 	for _, fwInfo := range registry {
-		if detected[fwInfo.Name] {
-			continue
+		if !detected[fwInfo.Name] && len(fwInfo.Indicators) > 0 {
+			family := indicatorFamily(fwInfo.Language)
+			pending[family] = append(pending[family], fwInfo)
 		}
+	}
 
-		// Check for indicator patterns in files
-		for _, file := range files {
-			if detected[fwInfo.Name] {
-				break
-			}
+	// Report in registry order whichever file matched first
+	found := d.scanIndicators(files, pending)
+	for _, fwInfo := range registry {
+		if found[fwInfo.Name] {
+			frameworks = append(frameworks, Framework{
+				Name:     fwInfo.Name,
+				Type:     fwInfo.Type,
+				Language: fwInfo.Language,
+			})
+		}
+	}
 
-			content, err := d.readFile(file)
-			if err != nil {
-				continue
-			}
+	return frameworks, nil
+}
+
+// indicatorFamily groups languages whose files share framework indicators:
+// JavaScript frameworks are used from TypeScript and the reverse, and JVM
+// frameworks such as Spring Boot from Kotlin as well as Java
+func indicatorFamily(lang Language) Language {
+	switch lang {
+	case LanguageTypeScript:
+		return LanguageJavaScript
+	case LanguageKotlin:
+		return LanguageJava
+	}
+	return lang
+}
+
+// scanIndicators searches the head of each file for the indicators of the
+// pending frameworks of its language, with one worker per CPU. Once every
+// framework of a language is found, its remaining files are not read.
+func (d *Detector) scanIndicators(files []string, pending map[Language][]FrameworkInfo) map[string]bool {
+	var mu sync.Mutex
+	found := make(map[string]bool)
+	remaining := make(map[Language]int, len(pending))
+	for family, infos := range pending {
+		remaining[family] = len(infos)
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				family := indicatorFamily(DetectLanguage(filepath.Join(d.rootPath, file)))
+				mu.Lock()
+				done := remaining[family] == 0
+				mu.Unlock()
+				if done {
+					continue
+				}
 
-			// Check if any indicator is present
-			for _, indicator := range fwInfo.Indicators {
-				if strings.Contains(content, indicator) {
-					frameworks = append(frameworks, Framework{
-						Name:     fwInfo.Name,
-						Type:     fwInfo.Type,
-						Language: fwInfo.Language,
-					})
-					detected[fwInfo.Name] = true
-					break
+				content, err := d.readHead(file, frameworkScanBytes)
+				if err != nil {
+					continue
+				}
+				for _, fwInfo := range pending[family] {
+					if !containsAny(content, fwInfo.Indicators) {
+						continue
+					}
+					mu.Lock()
+					if !found[fwInfo.Name] {
+						found[fwInfo.Name] = true
+						remaining[family]--
+					}
+					mu.Unlock()
 				}
 			}
-		}
+		}()
 	}
 
-	return frameworks, nil
+	for _, file := range files {
+		jobs <- file
+	}
+	close(jobs)
+	wg.Wait()
+
+	return found
+}
+
+// containsAny reports whether content contains any of the indicators
+func containsAny(content string, indicators []string) bool {
+	for _, indicator := range indicators {
+		if strings.Contains(content, indicator) {
+			return true
+		}
+	}
+	return false
 }
 
 // detectFromPackageFiles detects frameworks from package.json, go.mod, requirements.txt, etc.
-func (d *Detector) detectFromPackageFiles() []Framework {
+// in a directory relative to the root
+func (d *Detector) detectFromPackageFiles(dir string) []Framework {
 	frameworks := make([]Framework, 0)
 
 	// Check package.json (Node.js)
-	packageJSON := d.readPackageJSON()
+	packageJSON := d.readPackageJSON(dir)
 	if packageJSON != nil {
 		frameworks = append(frameworks, d.detectFromNodePackages(packageJSON)...)
 	}
 
 	// Check go.mod (Go)
-	goMod := d.readGoMod()
+	goMod := d.readGoMod(dir)
 	if goMod != "" {
 		frameworks = append(frameworks, d.detectFromGoMod(goMod)...)
 	}
 
 	// Check requirements.txt or pyproject.toml (Python)
-	pythonDeps := d.readPythonDeps()
+	pythonDeps := d.readPythonDeps(dir)
 	if len(pythonDeps) > 0 {
 		frameworks = append(frameworks, d.detectFromPythonDeps(pythonDeps)...)
 	}
 
-	// Check pom.xml or build.gradle (Java)
-	javaDeps := d.readJavaDeps()
+	// Check Cargo.toml (Rust)
+	if crates := d.readCargoToml(dir); len(crates) > 0 {
+		frameworks = append(frameworks, d.detectFromCargo(crates)...)
+	}
+
+	// Check pom.xml or build.gradle (Java and Kotlin)
+	javaDeps := d.readJavaDeps(dir)
 	if javaDeps != "" {
 		frameworks = append(frameworks, d.detectFromJavaDeps(javaDeps)...)
 	}
@@ -182,8 +299,8 @@ func (d *Detector) detectFromPackageFiles() []Framework {
 }
 
 // readPackageJSON reads and parses package.json
-func (d *Detector) readPackageJSON() map[string]interface{} {
-	path := filepath.Join(d.rootPath, "package.json")
+func (d *Detector) readPackageJSON(dir string) map[string]interface{} {
+	path := filepath.Join(d.rootPath, dir, "package.json")
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil
@@ -233,8 +350,8 @@ func (d *Detector) detectFromNodePackages(pkg map[string]interface{}) []Framewor
 }
 
 // readGoMod reads go.mod file
-func (d *Detector) readGoMod() string {
-	path := filepath.Join(d.rootPath, "go.mod")
+func (d *Detector) readGoMod(dir string) string {
+	path := filepath.Join(d.rootPath, dir, "go.mod")
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return ""
@@ -268,18 +385,18 @@ func (d *Detector) detectFromGoMod(content string) []Framework {
 }
 
 // readPythonDeps reads Python dependencies
-func (d *Detector) readPythonDeps() []string {
+func (d *Detector) readPythonDeps(dir string) []string {
 	deps := make([]string, 0)
 
 	// Try requirements.txt
-	reqPath := filepath.Join(d.rootPath, "requirements.txt")
+	reqPath := filepath.Join(d.rootPath, dir, "requirements.txt")
 	if data, err := os.ReadFile(reqPath); err == nil {
 		lines := strings.Split(string(data), "\n")
 		deps = append(deps, lines...)
 	}
 
 	// Try pyproject.toml
-	pyprojectPath := filepath.Join(d.rootPath, "pyproject.toml")
+	pyprojectPath := filepath.Join(d.rootPath, dir, "pyproject.toml")
 	if data, err := os.ReadFile(pyprojectPath); err == nil {
 		deps = append(deps, string(data))
 	}
@@ -314,20 +431,79 @@ func (d *Detector) detectFromPythonDeps(deps []string) []Framework {
 	return frameworks
 }
 
-// readJavaDeps reads Java dependencies
-func (d *Detector) readJavaDeps() string {
-	// Try pom.xml
-	pomPath := filepath.Join(d.rootPath, "pom.xml")
-	if data, err := os.ReadFile(pomPath); err == nil {
-		return string(data)
+// readCargoToml returns the crates a Cargo.toml depends on, from its
+// [dependencies] tables and their dev, build, target and workspace
+// variants, including the [dependencies.name] form
+func (d *Detector) readCargoToml(dir string) map[string]bool {
+	data, err := os.ReadFile(filepath.Join(d.rootPath, dir, "Cargo.toml"))
+	if err != nil {
+		return nil
 	}
 
-	// Try build.gradle
-	gradlePath := filepath.Join(d.rootPath, "build.gradle")
-	if data, err := os.ReadFile(gradlePath); err == nil {
-		return string(data)
+	crates := make(map[string]bool)
+	inDependencies := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			section := strings.Trim(line, "[] ")
+			inDependencies = false
+			for _, table := range []string{"dependencies", "dev-dependencies", "build-dependencies"} {
+				if section == table || strings.HasSuffix(section, "."+table) {
+					inDependencies = true
+				} else if i := strings.Index(section, table+"."); i == 0 || (i > 0 && section[i-1] == '.') {
+					// [dependencies.tokio] declares one crate
+					crates[strings.Trim(section[i+len(table)+1:], `"`)] = true
+				}
+			}
+			continue
+		}
+
+		if inDependencies {
+			if name, _, ok := strings.Cut(line, "="); ok {
+				crates[strings.Trim(strings.TrimSpace(name), `"`)] = true
+			}
+		}
 	}
+	return crates
+}
+
+// detectFromCargo detects frameworks from the crates of a Cargo.toml
+func (d *Detector) detectFromCargo(crates map[string]bool) []Framework {
+	frameworks := make([]Framework, 0)
+	registry := GetFrameworkRegistry()
 
+	for _, fwInfo := range registry {
+		if fwInfo.Language != LanguageRust {
+			continue
+		}
+
+		for _, pkgKey := range fwInfo.PackageKeys {
+			if crates[pkgKey] {
+				frameworks = append(frameworks, Framework{
+					Name:     fwInfo.Name,
+					Type:     fwInfo.Type,
+					Language: fwInfo.Language,
+				})
+				break
+			}
+		}
+	}
+
+	return frameworks
+}
+
+// readJavaDeps reads JVM dependencies from pom.xml, build.gradle or the
+// Kotlin DSL build.gradle.kts
+func (d *Detector) readJavaDeps(dir string) string {
+	for _, name := range []string{"pom.xml", "build.gradle", "build.gradle.kts"} {
+		if data, err := os.ReadFile(filepath.Join(d.rootPath, dir, name)); err == nil {
+			return string(data)
+		}
+	}
 	return ""
 }
 
@@ -374,6 +550,9 @@ func (d *Detector) detectPatterns(frameworks []Framework) []string {
 		case FrameworkNextJS, FrameworkNuxt:
 			pattern = "File-based Routing + SSR/SSG"
 		}
+		if fw.Type == FrameworkTypeORM {
+			pattern = "Repository/ORM Data Layer"
+		}
 
 		if pattern != "" && !detected[pattern] {
 			patterns = append(patterns, pattern)
@@ -395,6 +574,7 @@ func (d *Detector) findImportantFiles() map[string]interface{} {
 		"pyproject.toml",
 		"pom.xml",
 		"build.gradle",
+		"build.gradle.kts",
 		"Cargo.toml",
 		"tsconfig.json",
 		"next.config.js",
@@ -414,10 +594,10 @@ func (d *Detector) findImportantFiles() map[string]interface{} {
 	return files
 }
 
-// readFile reads a file relative to root path
-func (d *Detector) readFile(relPath string) (string, error) {
-	path := filepath.Join(d.rootPath, relPath)
-	data, err := os.ReadFile(path)
+// readHead reads up to limit bytes from the start of a file relative to
+// root path
+func (d *Detector) readHead(relPath string, limit int) (string, error) {
+	data, err := d.files.ReadHead(filepath.Join(d.rootPath, relPath), limit)
 	if err != nil {
 		return "", err
 	}
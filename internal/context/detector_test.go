@@ -0,0 +1,48 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectSpringBootInKotlin(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"kotlin source", map[string]string{
+			"src/main/kotlin/App.kt": "package app\n\n@SpringBootApplication\nclass App\n",
+		}},
+		{"kotlin build script", map[string]string{
+			"build.gradle.kts":       "dependencies {\n    implementation(\"org.springframework.boot:spring-boot-starter-web\")\n}\n",
+			"src/main/kotlin/App.kt": "package app\n\nclass App\n",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := NewDetector(root).Detect()
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+			for _, fw := range result.Frameworks {
+				if fw.Name == FrameworkSpringBoot {
+					return
+				}
+			}
+			t.Errorf("Spring Boot not detected, got %+v", result.Frameworks)
+		})
+	}
+}
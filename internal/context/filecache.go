@@ -0,0 +1,85 @@
+package context
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileCache keeps the content of files read during a context build, so
+// detection, analysis and hashing read each file from disk once. An entry
+// is reused while the file's size and modification time are unchanged. It
+// is safe for concurrent use, and a nil cache reads every file from disk.
+type FileCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedFile
+}
+
+// cachedFile is a file's content with the metadata it was read under
+type cachedFile struct {
+	size    int64
+	modTime time.Time
+	content []byte
+}
+
+// NewFileCache creates an empty file cache
+func NewFileCache() *FileCache {
+	return &FileCache{entries: make(map[string]cachedFile)}
+}
+
+// ReadFile returns the content of the file at path. The returned slice is
+// shared with other readers and must not be modified.
+func (c *FileCache) ReadFile(path string) ([]byte, error) {
+	if c == nil {
+		return os.ReadFile(path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := filepath.Clean(path)
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+		return entry.content, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cachedFile{size: info.Size(), modTime: info.ModTime(), content: content}
+	c.mu.Unlock()
+	return content, nil
+}
+
+// ReadHead returns up to limit bytes from the start of the file at path.
+// A cached file is cut from the cache; otherwise only the head is read and
+// nothing is cached, so scanning the start of many files never keeps their
+// whole content.
+func (c *FileCache) ReadHead(path string, limit int) ([]byte, error) {
+	if c != nil {
+		if info, err := os.Stat(path); err == nil {
+			c.mu.Lock()
+			entry, ok := c.entries[filepath.Clean(path)]
+			c.mu.Unlock()
+			if ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+				return entry.content[:min(limit, len(entry.content))], nil
+			}
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, int64(limit)))
+}
@@ -0,0 +1,43 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileCacheReadHead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.go")
+	if err := os.WriteFile(path, []byte("package big\n\nfunc f() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := NewFileCache()
+	head, err := cache.ReadHead(path, 7)
+	if err != nil {
+		t.Fatalf("ReadHead: %v", err)
+	}
+	if string(head) != "package" {
+		t.Errorf("head = %q, want %q", head, "package")
+	}
+	if len(cache.entries) != 0 {
+		t.Error("a head read cached the whole file")
+	}
+
+	// Once analysis has read the file, heads come from the cache
+	if _, err := cache.ReadFile(path); err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	head, err = cache.ReadHead(path, 100)
+	if err != nil {
+		t.Fatalf("ReadHead: %v", err)
+	}
+	if string(head) != "package big\n\nfunc f() {}\n" {
+		t.Errorf("head past the end = %q, want the whole file", head)
+	}
+
+	var none *FileCache
+	if head, err := none.ReadHead(path, 4); err != nil || string(head) != "pack" {
+		t.Errorf("nil cache ReadHead = %q, %v", head, err)
+	}
+}
@@ -35,6 +35,10 @@ const (
 	FrameworkNestJS     = "NestJS"
 	FrameworkKtor       = "Ktor"
 	FrameworkActix      = "Actix"
+	FrameworkAxum       = "Axum"
+	FrameworkRocket     = "Rocket"
+	FrameworkWarp       = "Warp"
+	FrameworkTokio      = "Tokio"
 
 	// Frontend/UI Frameworks
 	FrameworkReact      = "React"
@@ -88,6 +92,15 @@ const (
 	FrameworkPlaywright = "Playwright"
 	FrameworkCypress    = "Cypress"
 
+	// ORM and Database Frameworks; TypeORM is suffixed to stay clear of
+	// FrameworkTypeORM
+	FrameworkPrisma     = "Prisma"
+	FrameworkTypeORMLib = "TypeORM"
+	FrameworkSequelize  = "Sequelize"
+	FrameworkGORM       = "GORM"
+	FrameworkSQLAlchemy = "SQLAlchemy"
+	FrameworkHibernate  = "Hibernate"
+
 	// Build Tools
 	FrameworkWebpack = "Webpack"
 	FrameworkVite    = "Vite"
@@ -159,6 +172,136 @@ func GetFrameworkRegistry() []FrameworkInfo {
 			Indicators:  []string{"@Module(", "@Controller(", "@Injectable("},
 			PackageKeys: []string{"@nestjs/core"},
 		},
+		{
+			Name:        FrameworkActix,
+			Type:        FrameworkTypeBackend,
+			Language:    LanguageRust,
+			Indicators:  []string{"HttpServer::new", "use actix_web", "#[actix_web::main]"},
+			PackageKeys: []string{"actix-web"},
+		},
+		{
+			Name:        FrameworkAxum,
+			Type:        FrameworkTypeBackend,
+			Language:    LanguageRust,
+			Indicators:  []string{"use axum", "axum::serve", "axum::Router"},
+			PackageKeys: []string{"axum"},
+		},
+		{
+			Name:        FrameworkRocket,
+			Type:        FrameworkTypeBackend,
+			Language:    LanguageRust,
+			Indicators:  []string{"#[launch]", "rocket::build()", "#[macro_use] extern crate rocket"},
+			PackageKeys: []string{"rocket"},
+		},
+		{
+			Name:        FrameworkWarp,
+			Type:        FrameworkTypeBackend,
+			Language:    LanguageRust,
+			Indicators:  []string{"warp::serve", "use warp::Filter"},
+			PackageKeys: []string{"warp"},
+		},
+		{
+			Name:        FrameworkTokio,
+			Type:        FrameworkTypeBackend,
+			Language:    LanguageRust,
+			Indicators:  []string{"#[tokio::main]", "tokio::spawn"},
+			PackageKeys: []string{"tokio"},
+		},
+
+		// ORM and Database Frameworks
+		{
+			Name:        FrameworkPrisma,
+			Type:        FrameworkTypeORM,
+			Language:    LanguageTypeScript,
+			Indicators:  []string{"new PrismaClient(", "@prisma/client"},
+			PackageKeys: []string{"prisma", "@prisma/client"},
+		},
+		{
+			Name:        FrameworkTypeORMLib,
+			Type:        FrameworkTypeORM,
+			Language:    LanguageTypeScript,
+			Indicators:  []string{"from 'typeorm'", `from "typeorm"`, "new DataSource("},
+			PackageKeys: []string{"typeorm"},
+		},
+		{
+			Name:        FrameworkSequelize,
+			Type:        FrameworkTypeORM,
+			Language:    LanguageJavaScript,
+			Indicators:  []string{"new Sequelize(", "sequelize.define("},
+			PackageKeys: []string{"sequelize"},
+		},
+		{
+			Name:        FrameworkGORM,
+			Type:        FrameworkTypeORM,
+			Language:    LanguageGo,
+			Indicators:  []string{"gorm.Open(", "gorm.Model"},
+			PackageKeys: []string{"gorm.io/gorm", "github.com/jinzhu/gorm"},
+		},
+		{
+			Name:        FrameworkSQLAlchemy,
+			Type:        FrameworkTypeORM,
+			Language:    LanguagePython,
+			Indicators:  []string{"from sqlalchemy", "import sqlalchemy", "declarative_base("},
+			PackageKeys: []string{"sqlalchemy", "SQLAlchemy"},
+		},
+		{
+			Name:        FrameworkHibernate,
+			Type:        FrameworkTypeORM,
+			Language:    LanguageJava,
+			Indicators:  []string{"import org.hibernate", "SessionFactory"},
+			PackageKeys: []string{"hibernate-core"},
+		},
+
+		// Testing Frameworks
+		{
+			Name:        FrameworkJest,
+			Type:        FrameworkTypeTesting,
+			Language:    LanguageJavaScript,
+			Indicators:  []string{"jest.fn(", "jest.mock("},
+			PackageKeys: []string{"jest"},
+		},
+		{
+			Name:        FrameworkVitest,
+			Type:        FrameworkTypeTesting,
+			Language:    LanguageTypeScript,
+			Indicators:  []string{"from 'vitest'", `from "vitest"`},
+			PackageKeys: []string{"vitest"},
+		},
+		{
+			Name:        FrameworkMocha,
+			Type:        FrameworkTypeTesting,
+			Language:    LanguageJavaScript,
+			Indicators:  []string{"require('mocha')", "from 'mocha'"},
+			PackageKeys: []string{"mocha"},
+		},
+		{
+			Name:        FrameworkPlaywright,
+			Type:        FrameworkTypeTesting,
+			Language:    LanguageTypeScript,
+			Indicators:  []string{"from '@playwright/test'", `from "@playwright/test"`},
+			PackageKeys: []string{"@playwright/test"},
+		},
+		{
+			Name:        FrameworkCypress,
+			Type:        FrameworkTypeTesting,
+			Language:    LanguageJavaScript,
+			Indicators:  []string{"cy.visit(", "cy.get("},
+			PackageKeys: []string{"cypress"},
+		},
+		{
+			Name:        FrameworkPytest,
+			Type:        FrameworkTypeTesting,
+			Language:    LanguagePython,
+			Indicators:  []string{"import pytest", "@pytest.fixture"},
+			PackageKeys: []string{"pytest"},
+		},
+		{
+			Name:        FrameworkJUnit,
+			Type:        FrameworkTypeTesting,
+			Language:    LanguageJava,
+			Indicators:  []string{"import org.junit", "@Test"},
+			PackageKeys: []string{"junit"},
+		},
 
 		// Frontend/UI Frameworks
 		{
@@ -0,0 +1,157 @@
+package context
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFiles are the files at the repository root whose patterns exclude
+// paths from scanning, in the order they are applied
+var IgnoreFiles = []string{".gitignore", ".katichignore"}
+
+// IgnoreMatcher decides whether a repository path is excluded by gitignore
+// style patterns
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+// ignoreRule is a single compiled pattern line
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool // "!" re-includes a path excluded by an earlier rule
+	dirOnly bool // a trailing "/" only matches directories
+}
+
+// LoadIgnore reads the ignore files at the repository root. Missing files
+// are skipped, so a repository without any yields a matcher that ignores
+// nothing.
+func LoadIgnore(rootPath string) *IgnoreMatcher {
+	matcher := &IgnoreMatcher{}
+	for _, name := range IgnoreFiles {
+		file, err := os.Open(filepath.Join(rootPath, name))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			matcher.AddPattern(scanner.Text())
+		}
+		file.Close()
+	}
+	return matcher
+}
+
+// AddPattern adds one line of gitignore syntax. Blank lines and comments
+// are ignored.
+func (m *IgnoreMatcher) AddPattern(line string) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	rule := ignoreRule{}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// "\#" and "\!" match a literal leading character
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return
+	}
+
+	// A slash anywhere but the end anchors the pattern to the root;
+	// otherwise it matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+
+	pattern, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return
+	}
+	rule.pattern = pattern
+	m.rules = append(m.rules, rule)
+}
+
+// Match reports whether a slash-separated path relative to the repository
+// root is ignored. As in git, a file inside an ignored directory stays
+// ignored even if a later pattern would re-include it.
+func (m *IgnoreMatcher) Match(relPath string, isDir bool) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matchPath(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.matchPath(relPath, isDir)
+}
+
+// matchPath applies every rule to a single path; the last match wins
+func (m *IgnoreMatcher) matchPath(path string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.pattern.MatchString(path) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// globToRegexp translates a gitignore glob into a regular expression. "*"
+// and "?" stay within one path segment while "**" crosses them.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			// Leading or middle "**/" matches zero or more directories
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
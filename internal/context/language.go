@@ -1,6 +1,7 @@
 package context
 
 import (
+	"bytes"
 	"path/filepath"
 	"strings"
 )
@@ -22,6 +23,11 @@ const (
 	LanguageC          Language = "C"
 	LanguageCPP        Language = "C++"
 	LanguageCSharp     Language = "C#"
+	LanguageScala      Language = "Scala"
+	LanguageDart       Language = "Dart"
+	LanguageElixir     Language = "Elixir"
+	LanguageObjectiveC Language = "Objective-C"
+	LanguageShell      Language = "Shell"
 	LanguageUnknown    Language = "Unknown"
 )
 
@@ -32,8 +38,12 @@ var languageExtensions = map[string]Language{
 	".py":   LanguagePython,
 	".js":   LanguageJavaScript,
 	".jsx":  LanguageJavaScript,
+	".mjs":  LanguageJavaScript,
+	".cjs":  LanguageJavaScript,
 	".ts":   LanguageTypeScript,
 	".tsx":  LanguageTypeScript,
+	".vue":  LanguageJavaScript, // single-file components; refined from <script lang> during analysis
+	".svelte": LanguageJavaScript,
 	".rs":   LanguageRust,
 	".kt":   LanguageKotlin,
 	".kts":  LanguageKotlin,
@@ -47,14 +57,29 @@ var languageExtensions = map[string]Language{
 	".cxx":  LanguageCPP,
 	".hpp":  LanguageCPP,
 	".cs":   LanguageCSharp,
+	".scala": LanguageScala,
+	".sc":   LanguageScala,
+	".dart": LanguageDart,
+	".ex":   LanguageElixir,
+	".exs":  LanguageElixir,
+	".m":    LanguageObjectiveC, // also MATLAB, which is far rarer in repositories reviewed here
+	".mm":   LanguageObjectiveC,
+	".sh":   LanguageShell,
+	".bash": LanguageShell,
+	".zsh":  LanguageShell,
 }
 
-// DetectLanguage detects the programming language from a file path
+// DetectLanguage detects the programming language from a file path. Files
+// without an extension, such as scripts in bin/, are read for a shebang
+// line, so filePath must be readable from the working directory for them.
 func DetectLanguage(filePath string) Language {
 	ext := strings.ToLower(filepath.Ext(filePath))
 	if lang, ok := languageExtensions[ext]; ok {
 		return lang
 	}
+	if ext == "" {
+		return detectShebang(filePath)
+	}
 	return LanguageUnknown
 }
 
@@ -72,6 +97,44 @@ func DetectLanguages(filePaths []string) map[Language]int {
 	return languages
 }
 
+// DetectLanguageLOC sums the non-blank lines of the files of each language.
+// Paths are relative to root; unreadable files are skipped.
+func DetectLanguageLOC(filePaths []string, root string) map[Language]int {
+	return detectLanguageLOC(filePaths, root, nil)
+}
+
+// detectLanguageLOC is DetectLanguageLOC reading through a file cache
+func detectLanguageLOC(filePaths []string, root string, files *FileCache) map[Language]int {
+	lines := make(map[Language]int)
+
+	for _, path := range filePaths {
+		path = filepath.Join(root, path)
+		lang := DetectLanguage(path)
+		if lang == LanguageUnknown {
+			continue
+		}
+		content, err := files.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		lines[lang] += countNonBlankLines(content)
+	}
+
+	return lines
+}
+
+// countNonBlankLines counts the lines of content holding more than
+// whitespace
+func countNonBlankLines(content []byte) int {
+	count := 0
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
 // GetPrimaryLanguage returns the most common language from a map
 func GetPrimaryLanguage(languages map[Language]int) Language {
 	var primary Language
@@ -91,3 +154,30 @@ func GetPrimaryLanguage(languages map[Language]int) Language {
 func IsSourceFile(filePath string) bool {
 	return DetectLanguage(filePath) != LanguageUnknown
 }
+
+// testDirs are directories that hold only tests and their fixtures
+var testDirs = []string{"/test/", "/tests/", "/testdata/", "/__tests__/", "/spec/"}
+
+// IsTestFile checks if a file is test code, such as foo_test.go,
+// foo.spec.ts, test_foo.py, FooTest.java or anything under a tests directory
+func IsTestFile(filePath string) bool {
+	slashed := "/" + filepath.ToSlash(filePath)
+	for _, dir := range testDirs {
+		if strings.Contains(slashed, dir) {
+			return true
+		}
+	}
+
+	base := filepath.Base(filePath)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	lower := strings.ToLower(base)
+	return strings.HasSuffix(lower, "_test.go") ||
+		strings.Contains(lower, ".test.") ||
+		strings.Contains(lower, ".spec.") ||
+		strings.HasPrefix(lower, "test_") ||
+		strings.HasSuffix(lower, "_test.py") ||
+		strings.HasSuffix(lower, "_spec.rb") ||
+		lower == "conftest.py" ||
+		strings.HasSuffix(stem, "Test") ||
+		strings.HasSuffix(stem, "Tests")
+}
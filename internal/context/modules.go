@@ -0,0 +1,74 @@
+package context
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// moduleManifests are the package files that mark the root of a module,
+// such as a workspace package in a pnpm, yarn or turborepo monorepo
+var moduleManifests = map[string]bool{
+	"package.json":   true,
+	"go.mod":         true,
+	"pyproject.toml": true,
+	"Cargo.toml":     true,
+}
+
+// ModuleInfo describes a module of the repository: the root directory or a
+// subproject with its own package file
+type ModuleInfo struct {
+	Path       string           `json:"path"`      // directory relative to the repository root, "." for the root
+	Manifests  []string         `json:"manifests"` // package files in the directory, e.g. package.json
+	Languages  map[Language]int `json:"languages"` // source files by language, excluding nested modules
+	Frameworks []Framework      `json:"frameworks"`
+}
+
+// detectModules describes every directory holding a module manifest. Each
+// source file counts toward the deepest module that contains it, so a
+// workspace root does not repeat the languages of its packages.
+func (d *Detector) detectModules(files []string, manifests map[string][]string) []ModuleInfo {
+	dirs := make([]string, 0, len(manifests))
+	for dir := range manifests {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	modules := make([]ModuleInfo, 0, len(dirs))
+	byPath := make(map[string]int, len(dirs))
+	for _, dir := range dirs {
+		byPath[dir] = len(modules)
+		modules = append(modules, ModuleInfo{
+			Path:       dir,
+			Manifests:  manifests[dir],
+			Languages:  make(map[Language]int),
+			Frameworks: d.detectFromPackageFiles(dir),
+		})
+	}
+
+	for _, file := range files {
+		lang := DetectLanguage(filepath.Join(d.rootPath, file))
+		if lang == LanguageUnknown {
+			continue
+		}
+		if i, ok := owningModule(path.Dir(filepath.ToSlash(file)), byPath); ok {
+			modules[i].Languages[lang]++
+		}
+	}
+
+	return modules
+}
+
+// owningModule returns the index of the deepest module directory that
+// contains dir
+func owningModule(dir string, byPath map[string]int) (int, bool) {
+	for {
+		if i, ok := byPath[dir]; ok {
+			return i, true
+		}
+		if dir == "." || dir == "/" {
+			return 0, false
+		}
+		dir = path.Dir(dir)
+	}
+}
@@ -0,0 +1,78 @@
+package context
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// maxShebangLength bounds how much of a file is read to find its shebang
+const maxShebangLength = 256
+
+// shebangInterpreters maps script interpreters to languages
+var shebangInterpreters = map[string]Language{
+	"python":  LanguagePython,
+	"node":    LanguageJavaScript,
+	"nodejs":  LanguageJavaScript,
+	"deno":    LanguageJavaScript,
+	"bun":     LanguageJavaScript,
+	"ts-node": LanguageTypeScript,
+	"tsx":     LanguageTypeScript,
+	"ruby":    LanguageRuby,
+	"php":     LanguagePHP,
+	"elixir":  LanguageElixir,
+	"sh":      LanguageShell,
+	"bash":    LanguageShell,
+	"zsh":     LanguageShell,
+	"dash":    LanguageShell,
+	"ksh":     LanguageShell,
+}
+
+// detectShebang returns the language of a script from its shebang line,
+// reading at most maxShebangLength bytes of the file
+func detectShebang(filePath string) Language {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return LanguageUnknown
+	}
+	defer f.Close()
+
+	head := make([]byte, maxShebangLength)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return LanguageUnknown
+	}
+	line, _, _ := bytes.Cut(head[:n], []byte("\n"))
+	return ShebangLanguage(string(line))
+}
+
+// ShebangLanguage returns the language named by a shebang line such as
+// "#!/usr/bin/env python3" or "#!/bin/bash -e", or LanguageUnknown
+func ShebangLanguage(line string) Language {
+	if !strings.HasPrefix(line, "#!") {
+		return LanguageUnknown
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return LanguageUnknown
+	}
+
+	// env runs the interpreter named after its own options and variables,
+	// e.g. "#!/usr/bin/env -S NODE_ENV=test node"
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = path.Base(field)
+				break
+			}
+		}
+	}
+
+	// Versioned interpreters name the language too, e.g. python3.12
+	return shebangInterpreters[strings.TrimRight(interpreter, "0123456789.")]
+}
@@ -0,0 +1,53 @@
+package embeddings
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// randomIndex builds an index of n seeded random vectors of dim dimensions
+func randomIndex(n, dim int, seed int64) *EmbeddingIndex {
+	rng := rand.New(rand.NewSource(seed))
+	index := &EmbeddingIndex{Dimension: dim, Embeddings: make([]CodeEmbedding, n)}
+	for i := range index.Embeddings {
+		index.Embeddings[i] = CodeEmbedding{
+			ID:        fmt.Sprintf("fn%d", i),
+			Embedding: randomVector(rng, dim),
+		}
+	}
+	return index
+}
+
+// randomVector returns a vector with components uniform in [-1, 1)
+func randomVector(rng *rand.Rand, dim int) []float32 {
+	vector := make([]float32, dim)
+	for i := range vector {
+		vector[i] = rng.Float32()*2 - 1
+	}
+	return vector
+}
+
+func BenchmarkSimilaritySearch(b *testing.B) {
+	index := randomIndex(5000, 768, 1)
+	query := randomVector(rand.New(rand.NewSource(2)), 768)
+
+	for _, mode := range []struct {
+		name string
+		mode SearchMode
+	}{
+		{"exact", SearchExact},
+		{"approximate", SearchApproximate},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			search := NewSimilaritySearch(index)
+			search.SetMode(mode.mode)
+			search.Search(query, 10) // build the approximate index outside the timing
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				search.Search(query, 10)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package embeddings
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/katichai/katich/internal/cache"
+)
+
+// vectorCache stores embedding vectors under .katich/cache/embeddings, one
+// file per vector, keyed by the embedded text and the provider, model and
+// dimension that embedded it. Switching models therefore never reuses a
+// vector, and least recently used vectors are evicted with the rest of the
+// cache.
+type vectorCache struct {
+	dir       string
+	provider  string
+	model     string
+	dimension int
+
+	hits   int
+	misses int
+}
+
+// newVectorCache creates a vector cache for a provider in the repository's
+// cache directory
+func newVectorCache(rootPath string, provider EmbeddingProvider) *vectorCache {
+	return &vectorCache{
+		dir:       filepath.Join(cache.Dir(rootPath), "embeddings"),
+		provider:  provider.GetName(),
+		model:     provider.GetModel(),
+		dimension: provider.GetDimension(),
+	}
+}
+
+// path returns the file of the vector for a content hash. Files are spread
+// over subdirectories by key prefix to keep directories small.
+func (c *vectorCache) path(contentHash string) string {
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%s", c.provider, c.model, c.dimension, contentHash))))
+	return filepath.Join(c.dir, key[:2], key+".bin")
+}
+
+// get returns the cached vector for a content hash
+func (c *vectorCache) get(contentHash string) ([]float32, bool) {
+	path := c.path(contentHash)
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) != c.dimension*4 {
+		c.misses++
+		return nil, false
+	}
+
+	vector := make([]float32, c.dimension)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	cache.Touch(path)
+	c.hits++
+	return vector, true
+}
+
+// put stores a vector. A failed write only costs a regeneration next time.
+func (c *vectorCache) put(contentHash string, vector []float32) {
+	if len(vector) != c.dimension {
+		return
+	}
+
+	data := make([]byte, len(vector)*4)
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(value))
+	}
+
+	path := c.path(contentHash)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		os.WriteFile(path, data, 0644)
+	}
+}
+
+// record saves the hit and miss counts of a generation
+func (c *vectorCache) record() {
+	cache.RecordCounts(filepath.Dir(c.dir), "embeddings", c.hits, c.misses)
+	c.hits, c.misses = 0, 0
+}
@@ -4,36 +4,74 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/context"
 )
 
 // CodeEmbedding represents an embedding for a code block
 type CodeEmbedding struct {
-	ID         string    `json:"id"`          // Unique identifier (hash of code)
-	FilePath   string    `json:"file_path"`   // File containing the code
-	FuncName   string    `json:"func_name"`   // Function/class name
-	StartLine  int       `json:"start_line"`  // Start line number
-	EndLine    int       `json:"end_line"`    // End line number
-	Code       string    `json:"code"`        // The actual code
-	Embedding  []float32 `json:"embedding"`   // The embedding vector
-	Language   string    `json:"language"`    // Programming language
+	ID          string    `json:"id"`                     // Unique identifier (hash of code)
+	FilePath    string    `json:"file_path"`              // File containing the code
+	FuncName    string    `json:"func_name"`              // Function/class name
+	StartLine   int       `json:"start_line"`             // Start line number
+	EndLine     int       `json:"end_line"`               // End line number
+	Code        string    `json:"code"`                   // The actual code
+	Embedding   []float32 `json:"embedding"`              // The embedding vector
+	Language    string    `json:"language"`               // Programming language
+	ContentHash string    `json:"content_hash,omitempty"` // Hash of the embedded text
 }
 
+// IndexVersion is the schema version written to new embedding indexes
+const IndexVersion = "1.0"
+
 // EmbeddingIndex stores all embeddings
 type EmbeddingIndex struct {
 	Embeddings []CodeEmbedding `json:"embeddings"`
 	Dimension  int             `json:"dimension"`
 	Provider   string          `json:"provider"`
+	Model      string          `json:"model,omitempty"` // empty in indexes built before models were configurable
 	Version    string          `json:"version"`
 }
 
+// embeddingBatchSize is the number of functions embedded per provider call
+const embeddingBatchSize = 96
+
+// maxEmbeddingChars caps the text embedded for one function. Code runs at
+// roughly three characters per token, so this keeps inputs under OpenAI's
+// 8191-token limit; longer functions are embedded by their beginning.
+const maxEmbeddingChars = 24000
+
+// maxConsecutiveFailures is how many functions in a row may fail while a
+// failed batch is retried one function at a time before the rest of the
+// batch is given up, e.g. because the provider is down
+const maxConsecutiveFailures = 3
+
 // Generator generates embeddings for code
 type Generator struct {
 	provider EmbeddingProvider
 	rootPath string
+	output   io.Writer
+	files    *context.FileCache // shared with analysis; nil reads from disk
+
+	previous map[string][]float32 // vectors from the last build, by content hash
+	reused   int
+
+	cache   *vectorCache // vectors of earlier builds, across models and indexes
+	refresh bool         // regenerate vectors even if cached
+	cached  int
+
+	minLines int // functions shorter than this are not embedded
+	skipped  int
+
+	progress  func(done, total int)
+	generated int // vectors returned by the provider
+	failed    int // functions left out because the provider failed
 }
 
 // NewGenerator creates a new embedding generator
@@ -41,7 +79,92 @@ func NewGenerator(provider EmbeddingProvider, rootPath string) *Generator {
 	return &Generator{
 		provider: provider,
 		rootPath: rootPath,
+		output:   os.Stdout,
+	}
+}
+
+// SetFileCache makes the generator read function sources through a cache
+// shared with the analysis of the same build
+func (g *Generator) SetFileCache(files *context.FileCache) {
+	g.files = files
+}
+
+// SetOutput sets the destination for progress and warning messages
+func (g *Generator) SetOutput(w io.Writer) {
+	g.output = w
+}
+
+// SetPrevious lets the generator reuse vectors from an earlier index for
+// functions whose embedded text is unchanged. Indexes built by another
+// provider or model, or with another dimension, are ignored.
+func (g *Generator) SetPrevious(index *EmbeddingIndex) {
+	if index == nil || index.Provider != g.provider.GetName() || index.Dimension != g.provider.GetDimension() {
+		return
+	}
+	if index.Model != "" && index.Model != g.provider.GetModel() {
+		return
 	}
+
+	g.previous = make(map[string][]float32, len(index.Embeddings))
+	for _, emb := range index.Embeddings {
+		if emb.ContentHash != "" && len(emb.Embedding) == index.Dimension {
+			g.previous[emb.ContentHash] = emb.Embedding
+		}
+	}
+}
+
+// EnableCache keeps every generated vector in .katich/cache/embeddings and
+// reuses cached vectors of unchanged functions, whichever index or model
+// they were last built for. With refresh, cached vectors are regenerated
+// and overwritten instead.
+func (g *Generator) EnableCache(refresh bool) {
+	g.cache = newVectorCache(g.rootPath, g.provider)
+	g.refresh = refresh
+}
+
+// Cached returns how many embeddings the last generation took from the
+// vector cache
+func (g *Generator) Cached() int {
+	return g.cached
+}
+
+// SetMinFunctionLines skips functions shorter than lines, such as one-line
+// getters, whose near-identical vectors only add noise to duplicate
+// detection. Zero embeds every function.
+func (g *Generator) SetMinFunctionLines(lines int) {
+	g.minLines = lines
+}
+
+// Skipped returns how many functions the last generation left out for being
+// shorter than the minimum length
+func (g *Generator) Skipped() int {
+	return g.skipped
+}
+
+// OnProgress calls fn whenever functions are embedded, reused or fail,
+// with the number done so far out of the total to embed. Functions skipped
+// for their length do not count toward the total. Without a callback,
+// progress is printed to the output after every batch.
+func (g *Generator) OnProgress(fn func(done, total int)) {
+	g.progress = fn
+}
+
+// Generated returns how many embeddings the last generation got from the
+// provider
+func (g *Generator) Generated() int {
+	return g.generated
+}
+
+// Failed returns how many functions the last generation could not embed,
+// because the provider failed or returned vectors of the wrong dimension
+func (g *Generator) Failed() int {
+	return g.failed
+}
+
+// Reused returns how many embeddings the last generation took from the
+// previous index instead of the provider
+func (g *Generator) Reused() int {
+	return g.reused
 }
 
 // GenerateForAnalysis generates embeddings for analyzed code
@@ -50,63 +173,244 @@ func (g *Generator) GenerateForAnalysis(analysisResult *analysis.AnalysisResult)
 		Embeddings: make([]CodeEmbedding, 0),
 		Dimension:  g.provider.GetDimension(),
 		Provider:   g.provider.GetName(),
-		Version:    "1.0",
+		Model:      g.provider.GetModel(),
+		Version:    IndexVersion,
 	}
 
 	totalFunctions := 0
 	for _, fileAnalysis := range analysisResult.Files {
-		totalFunctions += len(fileAnalysis.Functions)
+		for _, fn := range fileAnalysis.Functions {
+			if fn.EndLine-fn.StartLine+1 >= g.minLines {
+				totalFunctions++
+			}
+		}
 	}
 
 	processed := 0
+	g.reused = 0
+	g.skipped = 0
+	g.cached = 0
+	g.generated = 0
+	g.failed = 0
+	advance := func(n int) {
+		processed += n
+		if g.progress != nil {
+			g.progress(processed, totalFunctions)
+		}
+	}
+
+	// Functions whose vectors are not reused are embedded in batches
+	pending := make([]CodeEmbedding, 0)
+	flush := func() {
+		texts := make([]string, len(pending))
+		for i, emb := range pending {
+			texts[i] = truncateInput(emb.Code)
+		}
+		// foreign marks vectors made by another model than the index's, as
+		// a hybrid provider that switched between Ollama and OpenAI returns
+		foreign := make([]bool, len(texts))
+		vectors, err := g.provider.GenerateEmbeddings(texts)
+		if err != nil {
+			// One oversized or rejected input fails the whole request, so
+			// retry one by one to keep the rest of the batch
+			vectors = g.embedOneByOne(texts, err, index, foreign)
+		} else if !g.servedByIndexModel(index) {
+			for i := range foreign {
+				foreign[i] = true
+			}
+		}
+		failed := 0
+		mismatched := 0
+		for i, emb := range pending {
+			if vectors[i] == nil {
+				failed++
+				continue
+			}
+			// Vectors of another model or dimension cannot share the index
+			// with the others
+			if foreign[i] || len(vectors[i]) != index.Dimension {
+				mismatched++
+				continue
+			}
+			emb.Embedding = vectors[i]
+			index.Embeddings = append(index.Embeddings, emb)
+			if g.cache != nil {
+				g.cache.put(emb.ContentHash, emb.Embedding)
+			}
+		}
+		if mismatched > 0 {
+			fmt.Fprintf(g.output, "Warning: Skipped %d embeddings not made by the index's %s model (%d dimensions); rebuild to include them\n", mismatched, index.Provider, index.Dimension)
+		}
+		g.generated += len(pending) - mismatched - failed
+		g.failed += mismatched + failed
+		advance(len(pending))
+		pending = pending[:0]
+		if g.progress == nil {
+			fmt.Fprintf(g.output, "  Embedded %d/%d functions...\n", processed, totalFunctions)
+		}
+	}
+
 	for filePath, fileAnalysis := range analysisResult.Files {
+		// Embed the functions' source, or only their metadata if the file
+		// cannot be read
+		sourceLines := g.readSourceLines(fileAnalysis.FilePath)
+
 		// Generate embeddings for each function
 		for _, fn := range fileAnalysis.Functions {
-			// Create code snippet for embedding
-			codeSnippet := g.createCodeSnippet(fn, fileAnalysis.Language)
-			
-			// Generate embedding
-			embedding, err := g.provider.GenerateEmbedding(codeSnippet)
-			if err != nil {
-				// Log error but continue
-				fmt.Printf("Warning: Failed to generate embedding for %s:%s: %v\n", filePath, fn.Name, err)
+			if fn.EndLine-fn.StartLine+1 < g.minLines {
+				g.skipped++
 				continue
 			}
 
+			// Create code snippet for embedding
+			codeSnippet := g.createCodeSnippet(fn, fileAnalysis.Language, sourceLines)
+			contentHash := hashContent(codeSnippet)
+
 			// Create code embedding
 			codeEmb := CodeEmbedding{
-				ID:        g.generateID(filePath, fn.Name, fn.StartLine),
-				FilePath:  filePath,
-				FuncName:  fn.Name,
-				StartLine: fn.StartLine,
-				EndLine:   fn.EndLine,
-				Code:      codeSnippet,
-				Embedding: embedding,
-				Language:  fileAnalysis.Language,
+				ID:          g.generateID(filePath, fn.Name, fn.StartLine),
+				FilePath:    filePath,
+				FuncName:    fn.Name,
+				StartLine:   fn.StartLine,
+				EndLine:     fn.EndLine,
+				Code:        codeSnippet,
+				Language:    fileAnalysis.Language,
+				ContentHash: contentHash,
 			}
 
-			index.Embeddings = append(index.Embeddings, codeEmb)
-			processed++
+			// Reuse the vector of an unchanged function, even in a modified file
+			if embedding, ok := g.previous[contentHash]; ok {
+				g.reused++
+				codeEmb.Embedding = embedding
+				index.Embeddings = append(index.Embeddings, codeEmb)
+				advance(1)
+				continue
+			}
+			if g.cache != nil && !g.refresh {
+				if embedding, ok := g.cache.get(contentHash); ok {
+					g.cached++
+					codeEmb.Embedding = embedding
+					index.Embeddings = append(index.Embeddings, codeEmb)
+					advance(1)
+					continue
+				}
+			}
 
-			// Progress indicator
-			if processed%10 == 0 {
-				fmt.Printf("  Generated %d/%d embeddings...\n", processed, totalFunctions)
+			pending = append(pending, codeEmb)
+			if len(pending) == embeddingBatchSize {
+				flush()
 			}
 		}
 	}
+	if len(pending) > 0 {
+		flush()
+	}
+	if g.cache != nil {
+		g.cache.record()
+	}
 
 	return index, nil
 }
 
+// embedOneByOne embeds texts one request at a time after their batch
+// request failed with batchErr. Texts that still fail get a nil vector, and
+// texts embedded by another model than the index's are marked in foreign.
+// After maxConsecutiveFailures failures in a row the provider is assumed
+// to be down and the remaining texts are not tried.
+func (g *Generator) embedOneByOne(texts []string, batchErr error, index *EmbeddingIndex, foreign []bool) [][]float32 {
+	vectors := make([][]float32, len(texts))
+	if len(texts) > 1 {
+		fmt.Fprintf(g.output, "Warning: Batch of %d functions failed (%v); retrying one at a time\n", len(texts), batchErr)
+	}
+
+	failed, consecutive := 0, 0
+	lastErr := batchErr
+	for i, text := range texts {
+		if consecutive >= maxConsecutiveFailures {
+			failed += len(texts) - i
+			break
+		}
+		vector, err := g.provider.GenerateEmbedding(text)
+		if err != nil {
+			failed++
+			consecutive++
+			lastErr = err
+			continue
+		}
+		consecutive = 0
+		vectors[i] = vector
+		foreign[i] = !g.servedByIndexModel(index)
+	}
+	if failed > 0 {
+		fmt.Fprintf(g.output, "Warning: Failed to generate embeddings for %d functions: %v\n", failed, lastErr)
+	}
+	return vectors
+}
+
+// servedByIndexModel reports whether the provider's last request was served
+// by the provider and model the index is built with
+func (g *Generator) servedByIndexModel(index *EmbeddingIndex) bool {
+	return g.provider.GetName() == index.Provider && g.provider.GetModel() == index.Model
+}
+
+// truncateInput cuts text to maxEmbeddingChars, on a UTF-8 boundary
+func truncateInput(text string) string {
+	if len(text) <= maxEmbeddingChars {
+		return text
+	}
+	cut := maxEmbeddingChars
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
 // createCodeSnippet creates a code snippet for embedding
-func (g *Generator) createCodeSnippet(fn analysis.FunctionInfo, language string) string {
-	// For now, create a simple representation
-	// In the future, we could read the actual code from the file
+func (g *Generator) createCodeSnippet(fn analysis.FunctionInfo, language string, sourceLines []string) string {
+	return FunctionSnippet(fn, language, sourceLines)
+}
+
+// FunctionSnippet returns the text embedded for a function: its source
+// lines, so that vectors encode what the code does. When the lines are
+// unavailable, or the file changed since analysis and no longer has them,
+// it falls back to the function's metadata. Queries built with it are
+// comparable to the vectors in the index.
+func FunctionSnippet(fn analysis.FunctionInfo, language string, sourceLines []string) string {
+	if body, ok := functionSource(fn, sourceLines); ok {
+		return fmt.Sprintf("// Language: %s\n%s\n", language, body)
+	}
+	return metadataSnippet(fn, language)
+}
+
+// functionSource returns the lines of a function, or false if its line
+// range is not within the file
+func functionSource(fn analysis.FunctionInfo, sourceLines []string) (string, bool) {
+	if fn.StartLine < 1 || fn.EndLine < fn.StartLine || fn.EndLine > len(sourceLines) {
+		return "", false
+	}
+	body := strings.Join(sourceLines[fn.StartLine-1:fn.EndLine], "\n")
+	if strings.TrimSpace(body) == "" {
+		return "", false
+	}
+	return body, true
+}
+
+// readSourceLines reads a file as lines, or returns nil if it cannot be read
+func (g *Generator) readSourceLines(path string) []string {
+	content, err := g.files.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+}
+
+// metadataSnippet describes a function by its signature and metrics
+func metadataSnippet(fn analysis.FunctionInfo, language string) string {
 	snippet := fmt.Sprintf("// Language: %s\n", language)
 	snippet += fmt.Sprintf("// Function: %s\n", fn.Name)
 	
 	if len(fn.Parameters) > 0 {
-		snippet += fmt.Sprintf("// Parameters: %v\n", fn.Parameters)
+		snippet += fmt.Sprintf("// Parameters: %s\n", formatParameters(fn))
 	}
 	
 	if fn.ReturnType != "" {
@@ -123,6 +427,19 @@ func (g *Generator) createCodeSnippet(fn analysis.FunctionInfo, language string)
 	return snippet
 }
 
+// formatParameters renders parameters with their types where known, e.g.
+// "ctx context.Context, id string"
+func formatParameters(fn analysis.FunctionInfo) string {
+	params := make([]string, 0, len(fn.Parameters))
+	for i, name := range fn.Parameters {
+		if i < len(fn.ParameterTypes) && fn.ParameterTypes[i] != "" {
+			name += " " + fn.ParameterTypes[i]
+		}
+		params = append(params, name)
+	}
+	return strings.Join(params, ", ")
+}
+
 // generateID generates a unique ID for a code block
 func (g *Generator) generateID(filePath, funcName string, startLine int) string {
 	data := fmt.Sprintf("%s:%s:%d", filePath, funcName, startLine)
@@ -130,8 +447,101 @@ func (g *Generator) generateID(filePath, funcName string, startLine int) string
 	return fmt.Sprintf("%x", hash[:8])
 }
 
-// SaveIndex saves the embedding index to disk
+// MergeIndex replaces the embeddings of the given files in base with those
+// in update, keeping every other file's embeddings. Both indexes must come
+// from the same provider, model and dimension.
+func MergeIndex(base, update *EmbeddingIndex, replaced []string) (*EmbeddingIndex, error) {
+	if base.Provider != update.Provider || base.Dimension != update.Dimension {
+		return nil, fmt.Errorf("existing index was built by %s (%d dimensions), not %s (%d dimensions)",
+			base.Provider, base.Dimension, update.Provider, update.Dimension)
+	}
+	if base.Model != "" && base.Model != update.Model {
+		return nil, fmt.Errorf("existing index was built with model %s, not %s", base.Model, update.Model)
+	}
+
+	drop := make(map[string]bool, len(replaced))
+	for _, path := range replaced {
+		drop[path] = true
+	}
+
+	merged := &EmbeddingIndex{
+		Embeddings: make([]CodeEmbedding, 0, len(base.Embeddings)+len(update.Embeddings)),
+		Dimension:  update.Dimension,
+		Provider:   update.Provider,
+		Model:      update.Model,
+		Version:    IndexVersion,
+	}
+	for _, emb := range base.Embeddings {
+		if !drop[emb.FilePath] {
+			merged.Embeddings = append(merged.Embeddings, emb)
+		}
+	}
+	merged.Embeddings = append(merged.Embeddings, update.Embeddings...)
+
+	return merged, nil
+}
+
+// ReindexStats counts what RebuildIndex dropped or repaired
+type ReindexStats struct {
+	Kept      int
+	Malformed int // vectors whose length does not match the index dimension
+	Missing   int // embeddings of files that no longer exist
+	Rehashed  int // entries given a content hash so later builds can reuse them
+}
+
+// RebuildIndex rewrites an index in the current layout from its stored
+// vectors, without calling a provider. Entries with malformed vectors or
+// for which exists reports false are dropped.
+func RebuildIndex(index *EmbeddingIndex, exists func(filePath string) bool) (*EmbeddingIndex, ReindexStats) {
+	stats := ReindexStats{}
+	rebuilt := &EmbeddingIndex{
+		Embeddings: make([]CodeEmbedding, 0, len(index.Embeddings)),
+		Dimension:  index.Dimension,
+		Provider:   index.Provider,
+		Model:      index.Model,
+		Version:    IndexVersion,
+	}
+
+	seen := make(map[string]bool, len(index.Embeddings))
+	for _, emb := range index.Embeddings {
+		if len(emb.Embedding) == 0 || len(emb.Embedding) != index.Dimension {
+			stats.Malformed++
+			continue
+		}
+		if !exists(emb.FilePath) {
+			stats.Missing++
+			continue
+		}
+		if seen[emb.ID] {
+			continue
+		}
+		seen[emb.ID] = true
+
+		if emb.ContentHash == "" && emb.Code != "" {
+			emb.ContentHash = hashContent(emb.Code)
+			stats.Rehashed++
+		}
+		rebuilt.Embeddings = append(rebuilt.Embeddings, emb)
+	}
+
+	stats.Kept = len(rebuilt.Embeddings)
+	return rebuilt, stats
+}
+
+// hashContent returns the hash used to match unchanged functions across builds
+func hashContent(text string) string {
+	hash := sha256.Sum256([]byte(text))
+	return fmt.Sprintf("%x", hash)
+}
+
+// SaveIndex saves the embedding index to disk in the binary format
 func (g *Generator) SaveIndex(index *EmbeddingIndex, outputPath string) error {
+	return SaveIndexBinary(index, outputPath)
+}
+
+// WriteIndex writes an embedding index to disk as JSON, for export to
+// other tools
+func WriteIndex(index *EmbeddingIndex, outputPath string) error {
 	// Ensure directory exists
 	dir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -152,12 +562,16 @@ func (g *Generator) SaveIndex(index *EmbeddingIndex, outputPath string) error {
 	return nil
 }
 
-// LoadIndex loads an embedding index from disk
+// LoadIndex loads an embedding index from disk, in the binary or the JSON
+// format
 func LoadIndex(indexPath string) (*EmbeddingIndex, error) {
 	data, err := os.ReadFile(indexPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read index: %w", err)
 	}
+	if isBinaryIndex(data) {
+		return decodeIndexBinary(data)
+	}
 
 	var index EmbeddingIndex
 	if err := json.Unmarshal(data, &index); err != nil {
@@ -0,0 +1,144 @@
+package embeddings
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/katichai/katich/internal/analysis"
+)
+
+// fakeProvider returns fixed vectors and rejects any text containing
+// "reject", failing the whole batch like a real API does
+type fakeProvider struct {
+	longest int // length of the longest text received
+	batches int
+}
+
+func (p *fakeProvider) GenerateEmbedding(text string) ([]float32, error) {
+	p.longest = max(p.longest, len(text))
+	if strings.Contains(text, "reject") {
+		return nil, errors.New("input rejected")
+	}
+	return make([]float32, p.GetDimension()), nil
+}
+
+func (p *fakeProvider) GenerateEmbeddings(texts []string) ([][]float32, error) {
+	p.batches++
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vector, err := p.GenerateEmbedding(text)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, vector)
+	}
+	return vectors, nil
+}
+
+func (p *fakeProvider) GetDimension() int { return 4 }
+func (p *fakeProvider) GetName() string   { return "Fake" }
+func (p *fakeProvider) GetModel() string  { return "fake" }
+
+// analysisOf returns an analysis of one file holding the given functions
+func analysisOf(functions ...analysis.FunctionInfo) *analysis.AnalysisResult {
+	return &analysis.AnalysisResult{
+		Files: map[string]*analysis.FileAnalysis{
+			"main.go": {FilePath: "/nonexistent/main.go", Language: "Go", Functions: functions},
+		},
+	}
+}
+
+func TestGenerateRetriesFailedBatchOneByOne(t *testing.T) {
+	provider := &fakeProvider{}
+	generator := NewGenerator(provider, t.TempDir())
+	generator.SetOutput(io.Discard)
+
+	index, err := generator.GenerateForAnalysis(analysisOf(
+		analysis.FunctionInfo{Name: "first", StartLine: 1, EndLine: 3},
+		analysis.FunctionInfo{Name: "reject", StartLine: 5, EndLine: 7},
+		analysis.FunctionInfo{Name: "last", StartLine: 9, EndLine: 11},
+	))
+	if err != nil {
+		t.Fatalf("GenerateForAnalysis: %v", err)
+	}
+
+	if len(index.Embeddings) != 2 || generator.Generated() != 2 || generator.Failed() != 1 {
+		t.Errorf("got %d embeddings (%d generated, %d failed), want 2 (2 generated, 1 failed)",
+			len(index.Embeddings), generator.Generated(), generator.Failed())
+	}
+	for _, emb := range index.Embeddings {
+		if emb.FuncName == "reject" {
+			t.Error("the rejected function was indexed")
+		}
+	}
+}
+
+func TestGenerateTruncatesLongInputs(t *testing.T) {
+	provider := &fakeProvider{}
+	generator := NewGenerator(provider, t.TempDir())
+	generator.SetOutput(io.Discard)
+
+	long := analysis.FunctionInfo{Name: "long", StartLine: 1, EndLine: 3, Comments: strings.Repeat("é", maxEmbeddingChars)}
+	if _, err := generator.GenerateForAnalysis(analysisOf(long)); err != nil {
+		t.Fatalf("GenerateForAnalysis: %v", err)
+	}
+	if provider.longest > maxEmbeddingChars {
+		t.Errorf("provider received %d characters, want at most %d", provider.longest, maxEmbeddingChars)
+	}
+	if got := truncateInput(strings.Repeat("é", maxEmbeddingChars)); !utf8.ValidString(got) {
+		t.Error("truncation split a multi-byte character")
+	}
+}
+
+// switchingProvider reports another provider once it has served
+// switchAfter requests, like a hybrid provider falling back to OpenAI
+type switchingProvider struct {
+	fakeProvider
+	calls       int
+	switchAfter int
+}
+
+func (p *switchingProvider) GenerateEmbedding(text string) ([]float32, error) {
+	p.calls++
+	return p.fakeProvider.GenerateEmbedding(text)
+}
+
+func (p *switchingProvider) GenerateEmbeddings(texts []string) ([][]float32, error) {
+	p.calls++
+	return p.fakeProvider.GenerateEmbeddings(texts)
+}
+
+func (p *switchingProvider) GetName() string {
+	if p.calls > p.switchAfter {
+		return "Other"
+	}
+	return "Fake"
+}
+
+func TestGenerateSkipsVectorsOfAnotherProvider(t *testing.T) {
+	// The batch fails, "first" is embedded by Fake, "reject" fails and
+	// "last" is embedded after the switch, with the same dimension
+	provider := &switchingProvider{switchAfter: 2}
+	generator := NewGenerator(provider, t.TempDir())
+	var output strings.Builder
+	generator.SetOutput(&output)
+
+	index, err := generator.GenerateForAnalysis(analysisOf(
+		analysis.FunctionInfo{Name: "first", StartLine: 1, EndLine: 3},
+		analysis.FunctionInfo{Name: "reject", StartLine: 5, EndLine: 7},
+		analysis.FunctionInfo{Name: "last", StartLine: 9, EndLine: 11},
+	))
+	if err != nil {
+		t.Fatalf("GenerateForAnalysis: %v", err)
+	}
+
+	if len(index.Embeddings) != 1 || index.Embeddings[0].FuncName != "first" {
+		t.Errorf("got %d embeddings, want only the one from the index's provider", len(index.Embeddings))
+	}
+	if !strings.Contains(output.String(), "Skipped 1 embeddings not made by the index's Fake model") {
+		t.Errorf("output does not report the skipped vector:\n%s", output.String())
+	}
+}
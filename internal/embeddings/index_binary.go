@@ -0,0 +1,199 @@
+package embeddings
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// IndexFile is the name of the binary embedding index in .katich
+	IndexFile = "embeddings.bin"
+
+	// LegacyIndexFile is the name of the JSON index written by earlier
+	// versions; it is still read when no binary index exists
+	LegacyIndexFile = "embeddings.json"
+)
+
+// binaryMagic starts every binary index file
+var binaryMagic = [8]byte{'K', 'A', 'T', 'I', 'C', 'H', 'V', 'X'}
+
+// binaryFormat is the layout version of the binary index
+const binaryFormat = 1
+
+// binaryHeader is the fixed-size start of a binary index. It is followed by
+// count*dimension little-endian float32 values, one row per embedding, and
+// then metaLength bytes of JSON metadata.
+type binaryHeader struct {
+	Magic      [8]byte
+	Format     uint32
+	Dimension  uint32
+	Count      uint32
+	MetaLength uint64
+}
+
+// binaryMetadata is the side table of a binary index: everything except
+// the vectors
+type binaryMetadata struct {
+	Provider   string          `json:"provider"`
+	Model      string          `json:"model,omitempty"`
+	Version    string          `json:"version"`
+	Embeddings []CodeEmbedding `json:"embeddings"`
+}
+
+// IndexPath returns the embedding index to read in a .katich directory:
+// the binary index, or the legacy JSON index if only that exists
+func IndexPath(katichDir string) string {
+	path := filepath.Join(katichDir, IndexFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		legacy := filepath.Join(katichDir, LegacyIndexFile)
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return path
+}
+
+// SaveIndexBinary writes an embedding index in the binary format. Every
+// vector must have the index dimension.
+func SaveIndexBinary(index *EmbeddingIndex, outputPath string) error {
+	for _, emb := range index.Embeddings {
+		if len(emb.Embedding) != index.Dimension {
+			return fmt.Errorf("embedding %s has dimension %d, index has %d", emb.ID, len(emb.Embedding), index.Dimension)
+		}
+	}
+
+	// The metadata omits the vectors, which are stored in the matrix
+	meta := binaryMetadata{
+		Provider:   index.Provider,
+		Model:      index.Model,
+		Version:    index.Version,
+		Embeddings: make([]CodeEmbedding, len(index.Embeddings)),
+	}
+	for i, emb := range index.Embeddings {
+		emb.Embedding = nil
+		meta.Embeddings[i] = emb
+	}
+	metaData, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Write to a temporary file so a failed save keeps the old index
+	tmpPath := outputPath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	w := bufio.NewWriterSize(file, 1<<20)
+	header := binaryHeader{
+		Magic:      binaryMagic,
+		Format:     binaryFormat,
+		Dimension:  uint32(index.Dimension),
+		Count:      uint32(len(index.Embeddings)),
+		MetaLength: uint64(len(metaData)),
+	}
+	err = binary.Write(w, binary.LittleEndian, header)
+
+	row := make([]byte, index.Dimension*4)
+	for _, emb := range index.Embeddings {
+		if err != nil {
+			break
+		}
+		for i, value := range emb.Embedding {
+			binary.LittleEndian.PutUint32(row[i*4:], math.Float32bits(value))
+		}
+		_, err = w.Write(row)
+	}
+	if err == nil {
+		_, err = w.Write(metaData)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, outputPath); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// LoadIndexBinary loads an embedding index written by SaveIndexBinary
+func LoadIndexBinary(indexPath string) (*EmbeddingIndex, error) {
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	return decodeIndexBinary(data)
+}
+
+// isBinaryIndex reports whether data starts like a binary index
+func isBinaryIndex(data []byte) bool {
+	return bytes.HasPrefix(data, binaryMagic[:])
+}
+
+// decodeIndexBinary parses a binary index held in memory. All vectors share
+// one backing array, which keeps loading large indexes fast.
+func decodeIndexBinary(data []byte) (*EmbeddingIndex, error) {
+	var header binaryHeader
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("failed to read index header: %w", err)
+	}
+	if header.Magic != binaryMagic {
+		return nil, fmt.Errorf("not a binary embedding index")
+	}
+	if header.Format != binaryFormat {
+		return nil, fmt.Errorf("unsupported binary index format %d", header.Format)
+	}
+
+	headerSize := binary.Size(header)
+	dimension, count := int(header.Dimension), int(header.Count)
+	matrixSize := uint64(count) * uint64(dimension) * 4
+	if uint64(len(data)-headerSize) != matrixSize+header.MetaLength {
+		return nil, fmt.Errorf("binary index is truncated or corrupt")
+	}
+
+	var meta binaryMetadata
+	metaStart := headerSize + int(matrixSize)
+	if err := json.Unmarshal(data[metaStart:], &meta); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal index: %w", err)
+	}
+	if len(meta.Embeddings) != count {
+		return nil, fmt.Errorf("binary index lists %d embeddings but holds %d vectors", len(meta.Embeddings), count)
+	}
+
+	matrix := make([]float32, count*dimension)
+	raw := data[headerSize:metaStart]
+	for i := range matrix {
+		matrix[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:]))
+	}
+
+	index := &EmbeddingIndex{
+		Embeddings: meta.Embeddings,
+		Dimension:  dimension,
+		Provider:   meta.Provider,
+		Model:      meta.Model,
+		Version:    meta.Version,
+	}
+	for i := range index.Embeddings {
+		index.Embeddings[i].Embedding = matrix[i*dimension : (i+1)*dimension : (i+1)*dimension]
+	}
+	return index, nil
+}
@@ -6,14 +6,75 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
 // EmbeddingProvider generates embeddings for code
 type EmbeddingProvider interface {
 	GenerateEmbedding(text string) ([]float32, error)
+	// GenerateEmbeddings embeds several texts, in one request where the
+	// provider supports it. Vectors are returned in the order of texts.
+	GenerateEmbeddings(texts []string) ([][]float32, error)
 	GetDimension() int
 	GetName() string
+	GetModel() string
+}
+
+// openAIBatchSize is the number of texts sent in one OpenAI request
+const openAIBatchSize = 96
+
+const (
+	// DefaultOllamaURL is the Ollama server used when none is configured
+	DefaultOllamaURL = "http://localhost:11434"
+	// DefaultOllamaModel is the local embedding model used when none is configured
+	DefaultOllamaModel = "nomic-embed-text"
+	// DefaultOpenAIModel is the OpenAI embedding model used when none is configured
+	DefaultOpenAIModel = "text-embedding-3-small"
+)
+
+// knownDimensions are the vector sizes of common embedding models, by name
+// without an Ollama tag. Other models are measured with one request.
+var knownDimensions = map[string]int{
+	"nomic-embed-text":       768,
+	"mxbai-embed-large":      1024,
+	"snowflake-arctic-embed": 1024,
+	"bge-m3":                 1024,
+	"all-minilm":             384,
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+	"text-embedding-ada-002": 1536,
+}
+
+// modelDimension returns the known dimension of model, or 0
+func modelDimension(model string) int {
+	name, _, _ := strings.Cut(model, ":")
+	return knownDimensions[name]
+}
+
+// measureDimension embeds a short text to find the dimension of a model
+// that is not in knownDimensions. It returns 0 when the request fails.
+func measureDimension(provider EmbeddingProvider) int {
+	vector, err := provider.GenerateEmbedding("func f() {}")
+	if err != nil {
+		return 0
+	}
+	return len(vector)
+}
+
+// generateSequentially embeds texts one request at a time, for providers
+// without a batch API
+func generateSequentially(provider EmbeddingProvider, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vector, err := provider.GenerateEmbedding(text)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, vector)
+	}
+	return vectors, nil
 }
 
 // OllamaProvider uses Ollama for local embeddings
@@ -21,23 +82,32 @@ type OllamaProvider struct {
 	baseURL string
 	model   string
 	client  *http.Client
+
+	dimension     int
+	dimensionOnce sync.Once
+
+	MaxRetries int           // retries of a failed request, e.g. while Ollama restarts
+	BaseDelay  time.Duration // wait before the first retry, doubled per attempt
 }
 
 // NewOllamaProvider creates a new Ollama provider
 func NewOllamaProvider(baseURL, model string) *OllamaProvider {
 	if baseURL == "" {
-		baseURL = "http://localhost:11434"
+		baseURL = DefaultOllamaURL
 	}
 	if model == "" {
-		model = "nomic-embed-text"
+		model = DefaultOllamaModel
 	}
 
 	return &OllamaProvider{
-		baseURL: baseURL,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
 		model:   model,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		dimension:  modelDimension(model),
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultBaseDelay,
 	}
 }
 
@@ -54,7 +124,14 @@ func (p *OllamaProvider) GenerateEmbedding(text string) ([]float32, error) {
 	}
 
 	url := fmt.Sprintf("%s/api/embeddings", p.baseURL)
-	resp, err := p.client.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := doWithRetry(p.client, p.MaxRetries, p.BaseDelay, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("ollama request failed: %w", err)
 	}
@@ -80,9 +157,26 @@ func (p *OllamaProvider) GenerateEmbedding(text string) ([]float32, error) {
 	return response.Embedding, nil
 }
 
-// GetDimension returns the embedding dimension
+// GenerateEmbeddings generates embeddings one at a time; the Ollama
+// embeddings API takes a single prompt
+func (p *OllamaProvider) GenerateEmbeddings(texts []string) ([][]float32, error) {
+	return generateSequentially(p, texts)
+}
+
+// GetDimension returns the embedding dimension of the model, measured on
+// first use for models not in knownDimensions
 func (p *OllamaProvider) GetDimension() int {
-	return 768 // nomic-embed-text dimension
+	p.dimensionOnce.Do(func() {
+		if p.dimension == 0 {
+			p.dimension = measureDimension(p)
+		}
+	})
+	return p.dimension
+}
+
+// GetURL returns the address of the Ollama server
+func (p *OllamaProvider) GetURL() string {
+	return p.baseURL
 }
 
 // GetName returns the provider name
@@ -90,6 +184,11 @@ func (p *OllamaProvider) GetName() string {
 	return "Ollama"
 }
 
+// GetModel returns the embedding model
+func (p *OllamaProvider) GetModel() string {
+	return p.model
+}
+
 // IsAvailable checks if Ollama is available
 func (p *OllamaProvider) IsAvailable() bool {
 	url := fmt.Sprintf("%s/api/tags", p.baseURL)
@@ -106,12 +205,18 @@ type OpenAIProvider struct {
 	apiKey string
 	model  string
 	client *http.Client
+
+	dimension     int
+	dimensionOnce sync.Once
+
+	MaxRetries int           // retries of a failed or rate-limited request
+	BaseDelay  time.Duration // wait before the first retry, doubled per attempt unless Retry-After says otherwise
 }
 
 // NewOpenAIProvider creates a new OpenAI provider
 func NewOpenAIProvider(apiKey, model string) *OpenAIProvider {
 	if model == "" {
-		model = "text-embedding-3-small"
+		model = DefaultOpenAIModel
 	}
 
 	return &OpenAIProvider{
@@ -120,13 +225,44 @@ func NewOpenAIProvider(apiKey, model string) *OpenAIProvider {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		dimension:  modelDimension(model),
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultBaseDelay,
 	}
 }
 
 // GenerateEmbedding generates an embedding using OpenAI
 func (p *OpenAIProvider) GenerateEmbedding(text string) ([]float32, error) {
+	vectors, err := p.request(text)
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// GenerateEmbeddings generates embeddings using OpenAI, sending up to
+// openAIBatchSize texts per request
+func (p *OpenAIProvider) GenerateEmbeddings(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += openAIBatchSize {
+		end := min(start+openAIBatchSize, len(texts))
+		batch, err := p.request(texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("openai returned %d embeddings for %d inputs", len(batch), end-start)
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// request sends one embeddings request. input is a string or a slice of
+// strings; the vectors are returned in input order.
+func (p *OpenAIProvider) request(input interface{}) ([][]float32, error) {
 	requestBody := map[string]interface{}{
-		"input": text,
+		"input": input,
 		"model": p.model,
 	}
 
@@ -135,15 +271,16 @@ func (p *OpenAIProvider) GenerateEmbedding(text string) ([]float32, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/embeddings", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.apiKey))
+	resp, err := doWithRetry(p.client, p.MaxRetries, p.BaseDelay, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", "https://api.openai.com/v1/embeddings", bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	resp, err := p.client.Do(req)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.apiKey))
+		return req, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("openai request failed: %w", err)
 	}
@@ -156,6 +293,7 @@ func (p *OpenAIProvider) GenerateEmbedding(text string) ([]float32, error) {
 
 	var response struct {
 		Data []struct {
+			Index     int       `json:"index"`
 			Embedding []float32 `json:"embedding"`
 		} `json:"data"`
 	}
@@ -164,16 +302,35 @@ func (p *OpenAIProvider) GenerateEmbedding(text string) ([]float32, error) {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if len(response.Data) == 0 || len(response.Data[0].Embedding) == 0 {
+	if len(response.Data) == 0 {
 		return nil, fmt.Errorf("openai returned empty embedding")
 	}
 
-	return response.Data[0].Embedding, nil
+	vectors := make([][]float32, len(response.Data))
+	for _, data := range response.Data {
+		if data.Index < 0 || data.Index >= len(vectors) || len(data.Embedding) == 0 {
+			return nil, fmt.Errorf("openai returned empty embedding")
+		}
+		vectors[data.Index] = data.Embedding
+	}
+	for _, vector := range vectors {
+		if vector == nil {
+			return nil, fmt.Errorf("openai returned empty embedding")
+		}
+	}
+
+	return vectors, nil
 }
 
-// GetDimension returns the embedding dimension
+// GetDimension returns the embedding dimension of the model, measured on
+// first use for models not in knownDimensions
 func (p *OpenAIProvider) GetDimension() int {
-	return 1536 // text-embedding-3-small dimension
+	p.dimensionOnce.Do(func() {
+		if p.dimension == 0 {
+			p.dimension = measureDimension(p)
+		}
+	})
+	return p.dimension
 }
 
 // GetName returns the provider name
@@ -181,81 +338,223 @@ func (p *OpenAIProvider) GetName() string {
 	return "OpenAI"
 }
 
-// HybridProvider tries Ollama first, falls back to OpenAI
+// GetModel returns the embedding model
+func (p *OpenAIProvider) GetModel() string {
+	return p.model
+}
+
+const (
+	// defaultOllamaFailureLimit is the number of consecutive Ollama failures
+	// after which the hybrid provider stops trying Ollama for each request
+	defaultOllamaFailureLimit = 3
+
+	// defaultOllamaProbeInterval is the wait between availability checks of
+	// Ollama while the hybrid provider uses OpenAI
+	defaultOllamaProbeInterval = 30 * time.Second
+)
+
+// HybridProvider tries Ollama first, falls back to OpenAI. A failed Ollama
+// request is retried with OpenAI; after FailureLimit consecutive failures
+// Ollama is skipped and probed every ProbeInterval until it answers again.
+// GetName, GetModel and GetDimension describe the provider that served the
+// last request, so callers can tell which model made the vectors they got
+// back. It is safe for concurrent use.
 type HybridProvider struct {
-	ollama *OllamaProvider
+	ollama *OllamaProvider // nil for embeddings.provider api
 	openai *OpenAIProvider
+
+	FailureLimit  int           // consecutive Ollama failures before switching to OpenAI
+	ProbeInterval time.Duration // wait between checks of Ollama after switching
+
+	mu        sync.Mutex
 	useOllama bool
+	failures  int               // consecutive Ollama failures
+	nextProbe time.Time         // when to check Ollama again while it is skipped
+	lastUsed  EmbeddingProvider // provider that served the last request
 }
 
-// NewHybridProvider creates a new hybrid provider
+// NewHybridProvider creates a new hybrid provider. Without an OpenAI key
+// there is no fallback.
 func NewHybridProvider(ollamaURL, ollamaModel, openaiKey, openaiModel string) *HybridProvider {
-	ollama := NewOllamaProvider(ollamaURL, ollamaModel)
-	
-	var openai *OpenAIProvider
+	p := &HybridProvider{
+		ollama:        NewOllamaProvider(ollamaURL, ollamaModel),
+		FailureLimit:  defaultOllamaFailureLimit,
+		ProbeInterval: defaultOllamaProbeInterval,
+	}
 	if openaiKey != "" {
-		openai = NewOpenAIProvider(openaiKey, openaiModel)
+		p.openai = NewOpenAIProvider(openaiKey, openaiModel)
 	}
 
-	// Check if Ollama is available
-	useOllama := ollama.IsAvailable()
+	p.useOllama = p.ollama.IsAvailable()
+	p.nextProbe = time.Now().Add(p.ProbeInterval)
+	return p
+}
 
-	return &HybridProvider{
-		ollama:    ollama,
-		openai:    openai,
-		useOllama: useOllama,
+// NewAPIProvider creates a hybrid provider that only uses OpenAI, for
+// embeddings.provider api. Without a key no provider is available.
+func NewAPIProvider(openaiKey, openaiModel string) *HybridProvider {
+	p := &HybridProvider{}
+	if openaiKey != "" {
+		p.openai = NewOpenAIProvider(openaiKey, openaiModel)
 	}
+	return p
 }
 
+// errNoProvider is returned when neither Ollama nor OpenAI can be used
+var errNoProvider = fmt.Errorf("no embedding provider available (Ollama not running, OpenAI key not configured)")
+
 // GenerateEmbedding generates an embedding using the best available provider
 func (p *HybridProvider) GenerateEmbedding(text string) ([]float32, error) {
 	// Try Ollama first if available
-	if p.useOllama {
+	if p.routeToOllama() {
 		embedding, err := p.ollama.GenerateEmbedding(text)
-		if err == nil {
-			return embedding, nil
+		p.recordOllama(err)
+		if err == nil || p.openai == nil {
+			return embedding, err
 		}
-		// If Ollama fails, mark as unavailable and try OpenAI
-		p.useOllama = false
 	}
 
 	// Fall back to OpenAI
-	if p.openai != nil {
-		return p.openai.GenerateEmbedding(text)
+	if p.openai == nil {
+		return nil, errNoProvider
+	}
+	embedding, err := p.openai.GenerateEmbedding(text)
+	if err == nil {
+		p.setLastUsed(p.openai)
+	}
+	return embedding, err
+}
+
+// GenerateEmbeddings generates embeddings using the best available
+// provider, in batches when falling back to OpenAI
+func (p *HybridProvider) GenerateEmbeddings(texts []string) ([][]float32, error) {
+	if p.routeToOllama() {
+		vectors, err := p.ollama.GenerateEmbeddings(texts)
+		p.recordOllama(err)
+		if err == nil || p.openai == nil {
+			return vectors, err
+		}
 	}
 
-	return nil, fmt.Errorf("no embedding provider available (Ollama not running, OpenAI key not configured)")
+	if p.openai == nil {
+		return nil, errNoProvider
+	}
+	vectors, err := p.openai.GenerateEmbeddings(texts)
+	if err == nil {
+		p.setLastUsed(p.openai)
+	}
+	return vectors, err
 }
 
-// GetDimension returns the embedding dimension
+// GetDimension returns the embedding dimension of the active provider
 func (p *HybridProvider) GetDimension() int {
-	if p.useOllama {
-		return p.ollama.GetDimension()
-	}
-	if p.openai != nil {
-		return p.openai.GetDimension()
+	if active := p.active(); active != nil {
+		return active.GetDimension()
 	}
 	return 768 // Default to Ollama dimension
 }
 
 // GetName returns the active provider name
 func (p *HybridProvider) GetName() string {
-	if p.useOllama {
-		return p.ollama.GetName()
-	}
-	if p.openai != nil {
-		return p.openai.GetName()
+	if active := p.active(); active != nil {
+		return active.GetName()
 	}
 	return "None"
 }
 
+// GetModel returns the model of the active provider
+func (p *HybridProvider) GetModel() string {
+	if active := p.active(); active != nil {
+		return active.GetModel()
+	}
+	return ""
+}
+
 // GetActiveProvider returns which provider is being used
 func (p *HybridProvider) GetActiveProvider() string {
-	if p.useOllama {
+	switch active := p.active(); {
+	case active == nil:
+		return "None"
+	case active == EmbeddingProvider(p.openai):
+		return "OpenAI (API)"
+	default:
 		return "Ollama (local)"
 	}
+}
+
+// active returns the provider that served the last request, or before any
+// request the one the next request will try first. Vectors already returned
+// are therefore always described by the provider that made them.
+func (p *HybridProvider) active() EmbeddingProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lastUsed != nil {
+		return p.lastUsed
+	}
+	if p.useOllama {
+		return p.ollama
+	}
 	if p.openai != nil {
-		return "OpenAI (API)"
+		return p.openai
 	}
-	return "None"
+	return nil
+}
+
+// routeToOllama reports whether the next request should go to Ollama. While
+// Ollama is skipped it is probed once per ProbeInterval and used again as
+// soon as it answers.
+func (p *HybridProvider) routeToOllama() bool {
+	if p.ollama == nil {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.useOllama {
+		return true
+	}
+	if time.Now().Before(p.nextProbe) {
+		return false
+	}
+
+	// Probing under the lock keeps concurrent callers from probing at once
+	p.nextProbe = time.Now().Add(p.ProbeInterval)
+	if p.ollama.IsAvailable() {
+		p.useOllama = true
+		p.failures = 0
+	}
+	return p.useOllama
+}
+
+// recordOllama tracks the outcome of an Ollama request, switching to OpenAI
+// after FailureLimit consecutive failures
+func (p *HybridProvider) recordOllama(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil {
+		p.failures = 0
+		p.lastUsed = p.ollama
+		return
+	}
+
+	p.failures++
+	if p.failures >= max(p.FailureLimit, 1) {
+		p.useOllama = false
+		p.nextProbe = time.Now().Add(p.ProbeInterval)
+	}
+}
+
+// setLastUsed records the provider that served a request
+func (p *HybridProvider) setLastUsed(provider EmbeddingProvider) {
+	p.mu.Lock()
+	p.lastUsed = provider
+	p.mu.Unlock()
+}
+
+// Ollama returns the Ollama provider, or nil when only OpenAI is used
+func (p *HybridProvider) Ollama() *OllamaProvider {
+	return p.ollama
 }
@@ -0,0 +1,162 @@
+package embeddings
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// newOllamaStub serves /api/tags and /api/embeddings, failing every
+// failEvery-th embedding request when failEvery is positive
+func newOllamaStub(t *testing.T, failEvery int64) *httptest.Server {
+	t.Helper()
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if n := requests.Add(1); failEvery > 0 && n%failEvery == 0 {
+			http.Error(w, "model not loaded", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string][]float32{"embedding": make([]float32, 768)})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Run with -race: concurrent calls must not race on the provider's
+// routing state
+func TestHybridProviderConcurrentCalls(t *testing.T) {
+	server := newOllamaStub(t, 5)
+	provider := NewHybridProvider(server.URL, "nomic-embed-text", "", "")
+	provider.ollama.MaxRetries = 0
+	provider.ProbeInterval = 0 // probe on every skipped request
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if vector, err := provider.GenerateEmbedding("func f() {}"); err == nil && len(vector) != 768 {
+					t.Errorf("got a %d-dimension vector, want 768", len(vector))
+				}
+				provider.GenerateEmbeddings([]string{"a", "b"})
+				provider.GetDimension()
+				provider.GetActiveProvider()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := provider.GetName(); got != "Ollama" {
+		t.Errorf("active provider = %s, want Ollama", got)
+	}
+}
+
+// roundTripFunc serves HTTP requests without a network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// stubOpenAI answers the provider's requests with one 1536-dimension vector
+func stubOpenAI(provider *OpenAIProvider) {
+	provider.client.Transport = roundTripFunc(func(*http.Request) (*http.Response, error) {
+		var body bytes.Buffer
+		json.NewEncoder(&body).Encode(map[string]any{
+			"data": []map[string]any{{"index": 0, "embedding": make([]float32, 1536)}},
+		})
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(&body), Header: http.Header{}}, nil
+	})
+}
+
+func TestHybridProviderRecoversOllama(t *testing.T) {
+	var down atomic.Bool
+	var embeddingRequests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			if r.URL.Path == "/api/embeddings" {
+				embeddingRequests.Add(1)
+			}
+			return
+		}
+		if r.URL.Path == "/api/tags" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		embeddingRequests.Add(1)
+		json.NewEncoder(w).Encode(map[string][]float32{"embedding": make([]float32, 768)})
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewHybridProvider(server.URL, "nomic-embed-text", "sk-test", "")
+	provider.ollama.MaxRetries = 0
+	provider.FailureLimit = 3
+	provider.ProbeInterval = 0 // probe on every skipped request
+	stubOpenAI(provider.openai)
+
+	embed := func(wantName string, wantDimension int) {
+		t.Helper()
+		vector, err := provider.GenerateEmbedding("func f() {}")
+		if err != nil {
+			t.Fatalf("GenerateEmbedding: %v", err)
+		}
+		if len(vector) != wantDimension {
+			t.Errorf("got a %d-dimension vector, want %d", len(vector), wantDimension)
+		}
+		if got := provider.GetName(); got != wantName {
+			t.Errorf("active provider = %s, want %s", got, wantName)
+		}
+		if got := provider.GetDimension(); got != len(vector) {
+			t.Errorf("GetDimension = %d, want %d to describe the last vector", got, len(vector))
+		}
+	}
+
+	embed("Ollama", 768)
+
+	// Each failed Ollama request falls back to OpenAI; after FailureLimit
+	// failures Ollama is skipped and only probed
+	down.Store(true)
+	for i := 0; i < 5; i++ {
+		embed("OpenAI", 1536)
+	}
+	if got := embeddingRequests.Load(); got != 1+3 {
+		t.Errorf("Ollama got %d embedding requests, want 4: it was not skipped after 3 failures", got)
+	}
+
+	down.Store(false)
+	embed("Ollama", 768)
+}
+
+func TestHybridProviderWithoutProviders(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	provider := NewHybridProvider(server.URL, "nomic-embed-text", "", "")
+	if got := provider.GetName(); got != "None" {
+		t.Errorf("active provider = %s, want None", got)
+	}
+	if _, err := provider.GenerateEmbeddings([]string{"a"}); err == nil {
+		t.Error("GenerateEmbeddings succeeded without a provider")
+	}
+}
+
+func TestOllamaDimension(t *testing.T) {
+	server := newOllamaStub(t, 0)
+
+	// Known models need no request; others are measured once
+	if got := NewOllamaProvider(server.URL, "mxbai-embed-large:latest").GetDimension(); got != 1024 {
+		t.Errorf("mxbai-embed-large dimension = %d, want 1024", got)
+	}
+	if got := NewOllamaProvider(server.URL, "custom-embed").GetDimension(); got != 768 {
+		t.Errorf("measured dimension = %d, want the stub's 768", got)
+	}
+}
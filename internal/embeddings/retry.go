@@ -0,0 +1,104 @@
+package embeddings
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultMaxRetries is how often a failed request is retried
+	defaultMaxRetries = 3
+
+	// defaultBaseDelay is the wait before the first retry; it doubles on
+	// each further attempt
+	defaultBaseDelay = 500 * time.Millisecond
+
+	// maxRetryDelay caps backoff and Retry-After waits
+	maxRetryDelay = time.Minute
+)
+
+// doWithRetry sends a request, retrying connection errors and transient
+// statuses (429 and 5xx gateway errors) with exponential backoff and jitter.
+// A Retry-After header on the response takes precedence over the backoff.
+// newRequest is called for every attempt, since a request body can only be
+// read once. The last response is returned even if its status is an error,
+// for the caller to report.
+func doWithRetry(client *http.Client, maxRetries int, baseDelay time.Duration, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err == nil && !retryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if attempt >= maxRetries {
+			return resp, err
+		}
+
+		delay := backoff(baseDelay, attempt)
+		if resp != nil {
+			if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = wait
+			}
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		time.Sleep(delay)
+	}
+}
+
+// retryableStatus reports whether a status is worth retrying: rate limits
+// and servers that are restarting or overloaded
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the wait before a retry: baseDelay doubled per earlier
+// attempt, randomized between half and the full value so that parallel
+// clients do not retry in step. A zero baseDelay retries immediately;
+// delays are capped at maxRetryDelay, including when doubling overflows.
+func backoff(baseDelay time.Duration, attempt int) time.Duration {
+	if baseDelay <= 0 {
+		return 0
+	}
+	delay := baseDelay << attempt
+	if attempt >= 63 || delay>>attempt != baseDelay || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	half := int64(delay / 2)
+	return time.Duration(half + rand.Int64N(half+1))
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an
+// HTTP date
+func retryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		wait = time.Until(date)
+	} else {
+		return 0, false
+	}
+
+	if wait < 0 {
+		wait = 0
+	}
+	return min(wait, maxRetryDelay), true
+}
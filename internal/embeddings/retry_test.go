@@ -0,0 +1,32 @@
+package embeddings
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		attempt  int
+		min, max time.Duration
+	}{
+		{"zero base delay", 0, 3, 0, 0},
+		{"first retry", time.Second, 0, 500 * time.Millisecond, time.Second},
+		{"doubled", time.Second, 2, 2 * time.Second, 4 * time.Second},
+		{"capped", time.Second, 20, maxRetryDelay / 2, maxRetryDelay},
+		{"overflow", time.Second, 62, maxRetryDelay / 2, maxRetryDelay},
+		{"shift past width", time.Second, 70, maxRetryDelay / 2, maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				if got := backoff(tt.base, tt.attempt); got < tt.min || got > tt.max {
+					t.Fatalf("backoff(%v, %d) = %v, want between %v and %v", tt.base, tt.attempt, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
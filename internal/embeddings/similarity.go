@@ -2,7 +2,6 @@ package embeddings
 
 import (
 	"fmt"
-	"math"
 	"sort"
 )
 
@@ -12,66 +11,188 @@ type SimilarityResult struct {
 	Similarity float32 `json:"similarity"` // Cosine similarity score (0-1)
 }
 
-// SimilaritySearch performs similarity search on embeddings
+// SearchMode selects how SimilaritySearch finds neighbors
+type SearchMode int
+
+const (
+	// SearchExact scores every embedding. It is the default.
+	SearchExact SearchMode = iota
+	// SearchApproximate scores only the embeddings in the inverted-file
+	// lists nearest to the query. It may miss some neighbors, in exchange
+	// for scoring a fraction of the index.
+	SearchApproximate
+	// SearchAuto scans exactly below ivfMinSize embeddings and uses the
+	// approximate index above
+	SearchAuto
+)
+
+// SimilaritySearch performs similarity search on embeddings. Vector norms
+// are computed once, so scoring an embedding is a single dot product with
+// the normalized query.
 type SimilaritySearch struct {
 	index *EmbeddingIndex
+	norms []float32
+
+	mode   SearchMode
+	probes int       // lists searched per approximate query, 0 for the default
+	ivf    *ivfIndex // built on the first approximate query
 }
 
 // NewSimilaritySearch creates a new similarity search
 func NewSimilaritySearch(index *EmbeddingIndex) *SimilaritySearch {
+	norms := make([]float32, len(index.Embeddings))
+	for i, emb := range index.Embeddings {
+		norms[i] = norm(emb.Embedding)
+	}
+
 	return &SimilaritySearch{
 		index: index,
+		norms: norms,
 	}
 }
 
+// ParseSearchMode parses a search mode name: exact, approximate or auto
+func ParseSearchMode(name string) (SearchMode, error) {
+	switch name {
+	case "exact":
+		return SearchExact, nil
+	case "approximate":
+		return SearchApproximate, nil
+	case "auto":
+		return SearchAuto, nil
+	}
+	return SearchExact, fmt.Errorf("invalid search mode %q (use exact, approximate or auto)", name)
+}
+
+// SetMode chooses between exact and approximate search. Building the
+// approximate index costs about as much as a few hundred exact queries, so
+// it pays off when one search serves many queries.
+func (s *SimilaritySearch) SetMode(mode SearchMode) {
+	s.mode = mode
+}
+
+// SetProbes sets how many inverted-file lists an approximate query
+// searches; more lists raise recall and cost. 0 restores the default.
+func (s *SimilaritySearch) SetProbes(probes int) {
+	s.probes = probes
+}
+
 // Search finds the top-k most similar code blocks
 func (s *SimilaritySearch) Search(queryEmbedding []float32, topK int) []SimilarityResult {
+	return s.search(queryEmbedding, topK, s.Approximate())
+}
+
+// search finds the top-k most similar code blocks, exactly or approximately
+func (s *SimilaritySearch) search(queryEmbedding []float32, topK int, approximate bool) []SimilarityResult {
 	if len(s.index.Embeddings) == 0 {
 		return []SimilarityResult{}
 	}
+	if topK > len(s.index.Embeddings) {
+		topK = len(s.index.Embeddings)
+	}
+
+	query := scaled(queryEmbedding, inverse(norm(queryEmbedding)))
+	best := newTopK(topK)
+	s.score(query, approximate, func(position int, similarity float32) {
+		best.push(position, similarity)
+	})
 
-	// Calculate similarity for all embeddings
-	results := make([]SimilarityResult, 0, len(s.index.Embeddings))
-	
-	for _, codeEmb := range s.index.Embeddings {
-		similarity := cosineSimilarity(queryEmbedding, codeEmb.Embedding)
-		
+	// Sort by similarity (descending)
+	sort.Slice(best.items, func(i, j int) bool {
+		return best.items[i].score > best.items[j].score
+	})
+
+	results := make([]SimilarityResult, 0, len(best.items))
+	for _, item := range best.items {
 		results = append(results, SimilarityResult{
-			CodeEmbedding: codeEmb,
-			Similarity:    similarity,
+			CodeEmbedding: s.index.Embeddings[item.position],
+			Similarity:    item.score,
 		})
 	}
+	return results
+}
 
-	// Sort by similarity (descending)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Similarity > results[j].Similarity
-	})
+// score calls visit with the cosine similarity of each candidate embedding
+// to a unit query: every embedding, or those in the nearest lists
+func (s *SimilaritySearch) score(query []float32, approximate bool, visit func(position int, similarity float32)) {
+	if !approximate {
+		for i, emb := range s.index.Embeddings {
+			visit(i, dot(query, emb.Embedding)*inverse(s.norms[i]))
+		}
+		return
+	}
+
+	if s.ivf == nil {
+		vectors := make([][]float32, len(s.index.Embeddings))
+		for i, emb := range s.index.Embeddings {
+			vectors[i] = emb.Embedding
+		}
+		s.ivf = buildIVF(vectors, s.norms)
+	}
+	probes := s.probes
+	if probes == 0 {
+		probes = s.ivf.defaultProbes()
+	}
+	for _, i := range s.ivf.candidates(query, probes) {
+		visit(i, dot(query, s.index.Embeddings[i].Embedding)*inverse(s.norms[i]))
+	}
+}
+
+// Approximate reports whether queries use the approximate index
+func (s *SimilaritySearch) Approximate() bool {
+	switch s.mode {
+	case SearchApproximate:
+		return true
+	case SearchAuto:
+		return len(s.index.Embeddings) >= ivfMinSize
+	}
+	return false
+}
 
-	// Return top-k
-	if topK > len(results) {
-		topK = len(results)
+// Recall measures how many of the exact top-k neighbors approximate search
+// finds, averaged over the given queries: 1 means it missed none. Use it
+// to choose probes for an index.
+func (s *SimilaritySearch) Recall(queries [][]float32, topK int) float64 {
+	if len(queries) == 0 || topK <= 0 {
+		return 1
 	}
 
-	return results[:topK]
+	found, total := 0, 0
+	for _, query := range queries {
+		exact := s.search(query, topK, false)
+		approximate := make(map[string]bool, topK)
+		for _, result := range s.search(query, topK, true) {
+			approximate[result.ID] = true
+		}
+		for _, result := range exact {
+			if approximate[result.ID] {
+				found++
+			}
+		}
+		total += len(exact)
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(found) / float64(total)
 }
 
 // FindDuplicates finds code blocks that are very similar (>threshold)
 func (s *SimilaritySearch) FindDuplicates(queryEmbedding []float32, threshold float32, excludeID string) []SimilarityResult {
-	results := s.Search(queryEmbedding, len(s.index.Embeddings))
-	
+	query := scaled(queryEmbedding, inverse(norm(queryEmbedding)))
+
 	duplicates := make([]SimilarityResult, 0)
-	for _, result := range results {
-		// Skip the query itself
-		if result.ID == excludeID {
-			continue
-		}
-		
-		// Only include results above threshold
-		if result.Similarity >= threshold {
-			duplicates = append(duplicates, result)
+	s.score(query, s.Approximate(), func(position int, similarity float32) {
+		emb := s.index.Embeddings[position]
+		// Skip the query itself, and keep only results above threshold
+		if emb.ID != excludeID && similarity >= threshold {
+			duplicates = append(duplicates, SimilarityResult{CodeEmbedding: emb, Similarity: similarity})
 		}
-	}
+	})
 
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i].Similarity > duplicates[j].Similarity
+	})
 	return duplicates
 }
 
@@ -86,29 +207,6 @@ func (s *SimilaritySearch) SearchByCode(provider EmbeddingProvider, code string,
 	return s.Search(queryEmbedding, topK), nil
 }
 
-// cosineSimilarity calculates cosine similarity between two vectors
-func cosineSimilarity(a, b []float32) float32 {
-	if len(a) != len(b) {
-		return 0
-	}
-
-	var dotProduct float64
-	var normA float64
-	var normB float64
-
-	for i := 0; i < len(a); i++ {
-		dotProduct += float64(a[i]) * float64(b[i])
-		normA += float64(a[i]) * float64(a[i])
-		normB += float64(b[i]) * float64(b[i])
-	}
-
-	if normA == 0 || normB == 0 {
-		return 0
-	}
-
-	return float32(dotProduct / (math.Sqrt(normA) * math.Sqrt(normB)))
-}
-
 // DuplicateDetector detects duplicate code
 type DuplicateDetector struct {
 	search    *SimilaritySearch
@@ -122,13 +220,42 @@ func NewDuplicateDetector(index *EmbeddingIndex, provider EmbeddingProvider, thr
 		threshold = 0.85 // Default threshold
 	}
 
+	// Every function is a query when clustering, so large indexes are
+	// searched approximately unless SetSearchMode says otherwise
+	search := NewSimilaritySearch(index)
+	search.SetMode(SearchAuto)
+
 	return &DuplicateDetector{
-		search:    NewSimilaritySearch(index),
+		search:    search,
 		provider:  provider,
 		threshold: threshold,
 	}
 }
 
+// SetSearchMode chooses exact or approximate search and, for approximate
+// search, how many lists each query probes (0 for the default)
+func (d *DuplicateDetector) SetSearchMode(mode SearchMode, probes int) {
+	d.search.SetMode(mode)
+	d.search.SetProbes(probes)
+}
+
+// Approximate reports whether the detector searches approximately
+func (d *DuplicateDetector) Approximate() bool {
+	return d.search.Approximate()
+}
+
+// SampleRecall estimates the recall of the approximate search over the
+// top-k neighbors, using up to samples indexed vectors as queries
+func (d *DuplicateDetector) SampleRecall(samples, topK int) float64 {
+	embeddings := d.search.index.Embeddings
+	samples = min(samples, len(embeddings))
+	queries := make([][]float32, 0, samples)
+	for i := 0; i < samples; i++ {
+		queries = append(queries, embeddings[i*len(embeddings)/samples].Embedding)
+	}
+	return d.search.Recall(queries, topK)
+}
+
 // DetectDuplicates detects if new code is duplicate
 func (d *DuplicateDetector) DetectDuplicates(code, filePath, funcName string) ([]SimilarityResult, error) {
 	// Generate embedding for new code
@@ -151,6 +278,104 @@ func (d *DuplicateDetector) DetectDuplicates(code, filePath, funcName string) ([
 	return filtered, nil
 }
 
+// DuplicateCluster is a group of near-identical functions in different files
+type DuplicateCluster struct {
+	Functions  []CodeEmbedding `json:"functions"`
+	Similarity float32         `json:"similarity"` // lowest similarity among the pairs joining the cluster
+}
+
+// FindClusters compares every indexed function of at least minLines lines
+// with the others and groups those at or above the threshold into
+// clusters, largest first. Each pair is counted once however it was found,
+// and functions are only paired across files.
+func (d *DuplicateDetector) FindClusters(minLines int) []DuplicateCluster {
+	embeddings := d.search.index.Embeddings
+
+	positions := make(map[string]int, len(embeddings))
+	for i, emb := range embeddings {
+		positions[emb.ID] = i
+	}
+
+	parent := make([]int, len(embeddings))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	type pair struct{ a, b int }
+	seen := make(map[pair]bool)
+	lowest := make(map[pair]float32)
+	for i, emb := range embeddings {
+		if emb.EndLine-emb.StartLine+1 < minLines {
+			continue
+		}
+		for _, dup := range d.search.FindDuplicates(emb.Embedding, d.threshold, emb.ID) {
+			j, ok := positions[dup.ID]
+			if !ok || dup.FilePath == emb.FilePath || dup.EndLine-dup.StartLine+1 < minLines {
+				continue
+			}
+			key := pair{i, j}
+			if j < i {
+				key = pair{j, i}
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			lowest[key] = dup.Similarity
+			parent[find(i)] = find(j)
+		}
+	}
+
+	members := make(map[int][]int)
+	similarity := make(map[int]float32)
+	for key, score := range lowest {
+		root := find(key.a)
+		if current, ok := similarity[root]; !ok || score < current {
+			similarity[root] = score
+		}
+	}
+	for i := range embeddings {
+		if _, ok := similarity[find(i)]; ok {
+			members[find(i)] = append(members[find(i)], i)
+		}
+	}
+
+	clusters := make([]DuplicateCluster, 0, len(members))
+	for root, indexes := range members {
+		cluster := DuplicateCluster{Similarity: similarity[root]}
+		for _, i := range indexes {
+			cluster.Functions = append(cluster.Functions, embeddings[i])
+		}
+		sort.Slice(cluster.Functions, func(i, j int) bool {
+			a, b := cluster.Functions[i], cluster.Functions[j]
+			if a.FilePath != b.FilePath {
+				return a.FilePath < b.FilePath
+			}
+			return a.StartLine < b.StartLine
+		})
+		clusters = append(clusters, cluster)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		if len(a.Functions) != len(b.Functions) {
+			return len(a.Functions) > len(b.Functions)
+		}
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+		return a.Functions[0].FilePath < b.Functions[0].FilePath
+	})
+	return clusters
+}
+
 // GetSimilarityLevel returns a human-readable similarity level
 func GetSimilarityLevel(similarity float32) string {
 	if similarity >= 0.95 {
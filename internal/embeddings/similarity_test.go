@@ -0,0 +1,82 @@
+package embeddings
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// clusteredIndex builds a seeded index of n vectors scattered around
+// clusters centers, like embeddings of related code, and returns it with
+// queries drawn from the same distribution
+func clusteredIndex(n, clusters, dim, queries int, seed int64) (*EmbeddingIndex, [][]float32) {
+	rng := rand.New(rand.NewSource(seed))
+	centers := make([][]float32, clusters)
+	for i := range centers {
+		centers[i] = randomVector(rng, dim)
+	}
+	near := func() []float32 {
+		center := centers[rng.Intn(clusters)]
+		vector := make([]float32, dim)
+		for d := range vector {
+			vector[d] = center[d] + float32(rng.NormFloat64())*0.8
+		}
+		return vector
+	}
+
+	index := &EmbeddingIndex{Dimension: dim, Embeddings: make([]CodeEmbedding, n)}
+	for i := range index.Embeddings {
+		index.Embeddings[i] = CodeEmbedding{ID: fmt.Sprintf("fn%d", i), Embedding: near()}
+	}
+	sample := make([][]float32, queries)
+	for i := range sample {
+		sample[i] = near()
+	}
+	return index, sample
+}
+
+func TestApproximateSearchRecall(t *testing.T) {
+	index, queries := clusteredIndex(4000, 40, 64, 100, 7)
+	search := NewSimilaritySearch(index)
+
+	// The default probes keep recall of the top 10 at 0.9 or more
+	if recall := search.Recall(queries, 10); recall < 0.9 {
+		t.Errorf("recall with default probes = %.3f, want at least 0.9", recall)
+	}
+
+	// Probing every list is an exact search
+	search.SetProbes(len(index.Embeddings))
+	if recall := search.Recall(queries, 10); recall != 1 {
+		t.Errorf("recall probing every list = %.3f, want 1", recall)
+	}
+}
+
+func TestSearchModes(t *testing.T) {
+	index, queries := clusteredIndex(500, 10, 16, 1, 3)
+	search := NewSimilaritySearch(index)
+
+	for _, tt := range []struct {
+		name        string
+		approximate bool
+	}{
+		{"exact", false},
+		{"approximate", true},
+		{"auto", false}, // below ivfMinSize
+	} {
+		mode, err := ParseSearchMode(tt.name)
+		if err != nil {
+			t.Fatalf("ParseSearchMode(%q): %v", tt.name, err)
+		}
+		search.SetMode(mode)
+		if got := search.Approximate(); got != tt.approximate {
+			t.Errorf("%s: Approximate() = %v, want %v", tt.name, got, tt.approximate)
+		}
+		if results := search.Search(queries[0], 5); len(results) != 5 {
+			t.Errorf("%s: got %d results, want 5", tt.name, len(results))
+		}
+	}
+
+	if _, err := ParseSearchMode("fast"); err == nil {
+		t.Error("ParseSearchMode accepted an unknown mode")
+	}
+}
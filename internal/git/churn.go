@@ -0,0 +1,163 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/katichai/katich/internal/cache"
+)
+
+// FileChurn summarizes the commit history of a single file
+type FileChurn struct {
+	Commits      int       `json:"commits"`
+	Additions    int       `json:"additions"`
+	Deletions    int       `json:"deletions"`
+	LastModified time.Time `json:"last_modified"`
+	LastAuthor   string    `json:"last_author"`
+}
+
+// churnCache is the on-disk form of the churn data, valid for one HEAD
+type churnCache struct {
+	Head  string                `json:"head"`
+	Files map[string]*FileChurn `json:"files"`
+}
+
+// churnCachePath returns the location of the churn cache file
+func (r *Repository) churnCachePath() string {
+	return filepath.Join(cache.Dir(r.RootPath), "git", "churn.json")
+}
+
+// GetFileChurn returns the commit history summary for a file. Churn for the
+// whole repository is computed in one pass and cached in
+// .katich/cache/git/ until HEAD changes. Files without history return a
+// zero FileChurn.
+func (r *Repository) GetFileChurn(filePath string) (*FileChurn, error) {
+	churn, err := r.loadChurn()
+	if err != nil {
+		return nil, err
+	}
+
+	if fileChurn, ok := churn[filepath.ToSlash(filePath)]; ok {
+		return fileChurn, nil
+	}
+	return &FileChurn{}, nil
+}
+
+// loadChurn returns the churn for all files, using the cache when it
+// matches the current HEAD
+func (r *Repository) loadChurn() (map[string]*FileChurn, error) {
+	if r.churn != nil {
+		return r.churn, nil
+	}
+
+	head, err := r.headHash()
+	if err != nil {
+		return nil, err
+	}
+
+	cachePath := r.churnCachePath()
+	if data, err := os.ReadFile(cachePath); err == nil {
+		var cached churnCache
+		if json.Unmarshal(data, &cached) == nil && cached.Head == head && cached.Files != nil {
+			cache.Touch(cachePath)
+			cache.Record(cache.Dir(r.RootPath), "git", true)
+			r.churn = cached.Files
+			return r.churn, nil
+		}
+	}
+	cache.Record(cache.Dir(r.RootPath), "git", false)
+
+	files, err := r.computeChurn()
+	if err != nil {
+		return nil, err
+	}
+	r.churn = files
+
+	// A failed cache write only costs a recomputation next time
+	if data, err := json.Marshal(churnCache{Head: head, Files: files}); err == nil {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+			os.WriteFile(cachePath, data, 0644)
+		}
+	}
+
+	return r.churn, nil
+}
+
+// computeChurn walks the full history once, accumulating per-file stats
+func (r *Repository) computeChurn() (map[string]*FileChurn, error) {
+	cmd := exec.Command("git", "log", "--no-renames", "--numstat", "--format=%x00%at|%an")
+	cmd.Dir = r.RootPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git history: %w", err)
+	}
+
+	files := make(map[string]*FileChurn)
+	var commitTime time.Time
+	var author string
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		// Commit header: NUL timestamp|author
+		if line[0] == 0 {
+			parts := strings.SplitN(line[1:], "|", 2)
+			if len(parts) == 2 {
+				timestamp, _ := strconv.ParseInt(parts[0], 10, 64)
+				commitTime = time.Unix(timestamp, 0)
+				author = parts[1]
+			}
+			continue
+		}
+
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		fileChurn, ok := files[parts[2]]
+		if !ok {
+			// Log order is newest first, so the first commit seen is the latest
+			fileChurn = &FileChurn{LastModified: commitTime, LastAuthor: author}
+			files[parts[2]] = fileChurn
+		}
+		fileChurn.Commits++
+		if added, err := strconv.Atoi(parts[0]); err == nil {
+			fileChurn.Additions += added
+		}
+		if deleted, err := strconv.Atoi(parts[1]); err == nil {
+			fileChurn.Deletions += deleted
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse git history: %w", err)
+	}
+
+	return files, nil
+}
+
+// headHash returns the full hash of HEAD
+func (r *Repository) headHash() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = r.RootPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -13,10 +13,17 @@ type Commit struct {
 	Author    string
 	Email     string
 	Date      time.Time
-	Message   string
+	Message   string // subject line
+	Body      string // message after the subject, empty if there is none
 	ShortHash string
 }
 
+// commitFormat is the git log format parsed by parseCommitRecord. Fields are
+// separated by the ASCII unit separator and records end with the record
+// separator, so neither "|" in a subject nor newlines in a body can break
+// parsing.
+const commitFormat = "%H%x1f%an%x1f%ae%x1f%at%x1f%s%x1f%b%x1e"
+
 // GetLatestCommit returns the most recent commit
 func (r *Repository) GetLatestCommit() (*Commit, error) {
 	return r.GetCommit("HEAD")
@@ -24,10 +31,7 @@ func (r *Repository) GetLatestCommit() (*Commit, error) {
 
 // GetCommit returns information about a specific commit
 func (r *Repository) GetCommit(ref string) (*Commit, error) {
-	// Format: hash|author|email|timestamp|subject
-	format := "%H|%an|%ae|%at|%s"
-	
-	cmd := exec.Command("git", "log", "-1", fmt.Sprintf("--format=%s", format), ref)
+	cmd := exec.Command("git", "log", "-1", "--format="+commitFormat, ref)
 	cmd.Dir = r.RootPath
 	
 	output, err := cmd.Output()
@@ -35,27 +39,9 @@ func (r *Repository) GetCommit(ref string) (*Commit, error) {
 		return nil, fmt.Errorf("failed to get commit info: %w", err)
 	}
 	
-	parts := strings.Split(strings.TrimSpace(string(output)), "|")
-	if len(parts) != 5 {
-		return nil, fmt.Errorf("unexpected git log output format")
-	}
-	
-	// Parse timestamp
-	timestamp := strings.TrimSpace(parts[3])
-	var date time.Time
-	if timestamp != "" {
-		var unixTime int64
-		fmt.Sscanf(timestamp, "%d", &unixTime)
-		date = time.Unix(unixTime, 0)
-	}
-	
-	commit := &Commit{
-		Hash:      parts[0],
-		Author:    parts[1],
-		Email:     parts[2],
-		Date:      date,
-		Message:   parts[4],
-		ShortHash: parts[0][:7],
+	commit, err := parseCommitRecord(strings.TrimSuffix(strings.TrimSpace(string(output)), "\x1e"))
+	if err != nil {
+		return nil, err
 	}
 	
 	return commit, nil
@@ -63,9 +49,7 @@ func (r *Repository) GetCommit(ref string) (*Commit, error) {
 
 // GetCommitRange returns commits in a range
 func (r *Repository) GetCommitRange(rangeSpec string) ([]*Commit, error) {
-	format := "%H|%an|%ae|%at|%s"
-	
-	cmd := exec.Command("git", "log", fmt.Sprintf("--format=%s", format), rangeSpec)
+	cmd := exec.Command("git", "log", "--format="+commitFormat, rangeSpec)
 	cmd.Dir = r.RootPath
 	
 	output, err := cmd.Output()
@@ -73,39 +57,55 @@ func (r *Repository) GetCommitRange(rangeSpec string) ([]*Commit, error) {
 		return nil, fmt.Errorf("failed to get commit range: %w", err)
 	}
 	
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	commits := make([]*Commit, 0, len(lines))
+	records := strings.Split(string(output), "\x1e")
+	commits := make([]*Commit, 0, len(records))
 	
-	for _, line := range lines {
-		if line == "" {
+	for _, record := range records {
+		record = strings.TrimSpace(record)
+		if record == "" {
 			continue
 		}
 		
-		parts := strings.Split(line, "|")
-		if len(parts) != 5 {
+		commit, err := parseCommitRecord(record)
+		if err != nil {
 			continue
 		}
 		
-		var date time.Time
-		var unixTime int64
-		fmt.Sscanf(parts[3], "%d", &unixTime)
-		date = time.Unix(unixTime, 0)
-		
-		commit := &Commit{
-			Hash:      parts[0],
-			Author:    parts[1],
-			Email:     parts[2],
-			Date:      date,
-			Message:   parts[4],
-			ShortHash: parts[0][:7],
-		}
-		
 		commits = append(commits, commit)
 	}
 	
 	return commits, nil
 }
 
+// parseCommitRecord parses one git log record written with commitFormat
+func parseCommitRecord(record string) (*Commit, error) {
+	parts := strings.SplitN(record, "\x1f", 6)
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("unexpected git log output format")
+	}
+	if parts[0] == "" {
+		return nil, fmt.Errorf("git log returned an empty commit hash")
+	}
+	
+	// Parse timestamp
+	var date time.Time
+	if timestamp := strings.TrimSpace(parts[3]); timestamp != "" {
+		var unixTime int64
+		fmt.Sscanf(timestamp, "%d", &unixTime)
+		date = time.Unix(unixTime, 0)
+	}
+	
+	return &Commit{
+		Hash:      parts[0],
+		Author:    parts[1],
+		Email:     parts[2],
+		Date:      date,
+		Message:   parts[4],
+		Body:      strings.TrimSpace(parts[5]),
+		ShortHash: parts[0][:min(len(parts[0]), 7)],
+	}, nil
+}
+
 // GetChangedFiles returns the list of files changed in a commit
 func (r *Repository) GetChangedFiles(ref string) ([]string, error) {
 	cmd := exec.Command("git", "diff-tree", "--no-commit-id", "--name-only", "-r", ref)
@@ -129,9 +129,17 @@ func (r *Repository) GetChangedFiles(ref string) ([]string, error) {
 	return files, nil
 }
 
-// GetFileContent returns the content of a file at a specific commit
+// IndexRef is the ref GetFileContent accepts for the staged version of a file
+const IndexRef = ":"
+
+// GetFileContent returns the content of a file at a specific commit, or in
+// the staging area for IndexRef
 func (r *Repository) GetFileContent(ref, filePath string) (string, error) {
-	cmd := exec.Command("git", "show", fmt.Sprintf("%s:%s", ref, filePath))
+	spec := fmt.Sprintf("%s:%s", ref, filePath)
+	if ref == IndexRef {
+		spec = ":" + filePath
+	}
+	cmd := exec.Command("git", "show", spec)
 	cmd.Dir = r.RootPath
 	
 	output, err := cmd.Output()
@@ -0,0 +1,35 @@
+package git
+
+import "testing"
+
+func TestParseCommitRecordShortHash(t *testing.T) {
+	tests := []struct {
+		name      string
+		record    string
+		wantShort string
+		wantErr   bool
+	}{
+		{"full hash", "0123456789abcdef\x1fAda\x1fada@example.com\x1f1700000000\x1fSubject\x1f", "0123456", false},
+		{"short hash", "abc\x1fAda\x1fada@example.com\x1f1700000000\x1fSubject\x1f", "abc", false},
+		{"empty hash", "\x1fAda\x1fada@example.com\x1f1700000000\x1fSubject\x1f", "", true},
+		{"malformed", "abc|Ada", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, err := parseCommitRecord(tt.record)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseCommitRecord accepted %q", tt.record)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCommitRecord: %v", err)
+			}
+			if commit.ShortHash != tt.wantShort {
+				t.Errorf("ShortHash = %q, want %q", commit.ShortHash, tt.wantShort)
+			}
+		})
+	}
+}
@@ -14,6 +14,15 @@ type DiffFile struct {
 	Additions int
 	Deletions int
 	Patch     string // The actual diff content
+	Hunks     []Hunk // Patch parsed into hunks
+
+	// FormattingOnly is set when the change only touches whitespace or
+	// line breaks, e.g. a gofmt or prettier reformat
+	FormattingOnly bool
+
+	// IsBinary is set for files git reports without line counts, such as
+	// images or compiled artifacts. They have no patch.
+	IsBinary bool
 }
 
 // Diff represents a complete diff
@@ -52,12 +61,30 @@ func (r *Repository) GetDiff(ref string) (*Diff, error) {
 
 // GetDiffRange returns the diff for a commit range
 func (r *Repository) GetDiffRange(rangeSpec string) (*Diff, error) {
-	files, err := r.getDiffFilesRange(rangeSpec)
+	return r.getDiffArgs([]string{rangeSpec})
+}
+
+// GetWorkingTreeDiff returns the unstaged changes in the working tree,
+// like `git diff`
+func (r *Repository) GetWorkingTreeDiff() (*Diff, error) {
+	return r.getDiffArgs(nil)
+}
+
+// GetStagedDiff returns the changes staged for the next commit, like
+// `git diff --cached`
+func (r *Repository) GetStagedDiff() (*Diff, error) {
+	return r.getDiffArgs([]string{"--cached"})
+}
+
+// getDiffArgs returns the diff selected by git diff arguments, such as a
+// range or --cached
+func (r *Repository) getDiffArgs(revArgs []string) (*Diff, error) {
+	files, err := r.getDiffFilesArgs(revArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get diff files: %w", err)
 	}
 
-	summary, err := r.getDiffSummaryRange(rangeSpec)
+	summary, err := r.getDiffSummaryArgs(revArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get diff summary: %w", err)
 	}
@@ -71,7 +98,7 @@ func (r *Repository) GetDiffRange(rangeSpec string) (*Diff, error) {
 // getDiffFiles gets the list of changed files with stats for a single commit
 func (r *Repository) getDiffFiles(ref string) ([]*DiffFile, error) {
 	// Get file stats
-	cmd := exec.Command("git", "diff-tree", "--no-commit-id", "--numstat", "-r", ref)
+	cmd := exec.Command("git", "diff-tree", "--root", "--no-commit-id", "--numstat", "-M", "-r", ref)
 	cmd.Dir = r.RootPath
 
 	output, err := cmd.Output()
@@ -79,41 +106,31 @@ func (r *Repository) getDiffFiles(ref string) ([]*DiffFile, error) {
 		return nil, fmt.Errorf("failed to get diff stats: %w", err)
 	}
 
+	statuses := r.getFileStatuses(ref)
+
 	files := make([]*DiffFile, 0)
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 
 	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-
-		parts := strings.Fields(line)
-		if len(parts) < 3 {
+		file, ok := parseNumstatLine(line)
+		if !ok {
 			continue
 		}
 
-		file := &DiffFile{
-			Path: parts[2],
+		// Get file status, defaulting to modified
+		file.Status = "M"
+		if status, ok := statuses[file.Path]; ok {
+			file.Status = status.Status
 		}
 
-		// Parse additions/deletions
-		if parts[0] != "-" {
-			fmt.Sscanf(parts[0], "%d", &file.Additions)
-		}
-		if parts[1] != "-" {
-			fmt.Sscanf(parts[1], "%d", &file.Deletions)
-		}
-
-		// Get file status
-		status, err := r.getFileStatus(ref, file.Path)
-		if err == nil {
-			file.Status = status
-		}
-
-		// Get patch for this file
-		patch, err := r.getFilePatch(ref, file.Path)
-		if err == nil {
-			file.Patch = patch
+		// Get patch for this file; binary files have none
+		if !file.IsBinary {
+			patch, err := r.getFilePatch(ref, file)
+			if err == nil {
+				file.Patch = patch
+				file.Hunks = ParseHunks(patch)
+			}
+			r.classifyFormatting(file)
 		}
 
 		files = append(files, file)
@@ -122,9 +139,9 @@ func (r *Repository) getDiffFiles(ref string) ([]*DiffFile, error) {
 	return files, nil
 }
 
-// getDiffFilesRange gets the list of changed files for a range
-func (r *Repository) getDiffFilesRange(rangeSpec string) ([]*DiffFile, error) {
-	cmd := exec.Command("git", "diff", "--numstat", rangeSpec)
+// getDiffFilesArgs gets the list of changed files for git diff arguments
+func (r *Repository) getDiffFilesArgs(revArgs []string) ([]*DiffFile, error) {
+	cmd := exec.Command("git", r.diffArgs(revArgs, "--numstat", "-M")...)
 	cmd.Dir = r.RootPath
 
 	output, err := cmd.Output()
@@ -132,34 +149,26 @@ func (r *Repository) getDiffFilesRange(rangeSpec string) ([]*DiffFile, error) {
 		return nil, fmt.Errorf("failed to get diff stats: %w", err)
 	}
 
+	statuses := r.getFileStatusesArgs(revArgs)
+
 	files := make([]*DiffFile, 0)
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 
 	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-
-		parts := strings.Fields(line)
-		if len(parts) < 3 {
+		file, ok := parseNumstatLine(line)
+		if !ok {
 			continue
 		}
-
-		file := &DiffFile{
-			Path: parts[2],
-		}
-
-		if parts[0] != "-" {
-			fmt.Sscanf(parts[0], "%d", &file.Additions)
-		}
-		if parts[1] != "-" {
-			fmt.Sscanf(parts[1], "%d", &file.Deletions)
-		}
-
-		// Get patch for this file
-		patch, err := r.getFilePatchRange(rangeSpec, file.Path)
-		if err == nil {
-			file.Patch = patch
+		file.Status = statuses[file.Path].Status
+
+		// Get patch for this file; binary files have none
+		if !file.IsBinary {
+			patch, err := r.getFilePatchArgs(revArgs, file)
+			if err == nil {
+				file.Patch = patch
+				file.Hunks = ParseHunks(patch)
+			}
+			r.classifyFormatting(file)
 		}
 
 		files = append(files, file)
@@ -168,31 +177,122 @@ func (r *Repository) getDiffFilesRange(rangeSpec string) ([]*DiffFile, error) {
 	return files, nil
 }
 
-// getFileStatus gets the status of a file (A, M, D, R)
-func (r *Repository) getFileStatus(ref, filePath string) (string, error) {
-	cmd := exec.Command("git", "diff-tree", "--no-commit-id", "--name-status", "-r", ref)
+// fileStatus is a path's entry in git's --name-status output
+type fileStatus struct {
+	Status  string
+	OldPath string // Set for renames
+}
+
+// getFileStatusesArgs maps each changed path to its status for git diff
+// arguments. Failures leave statuses empty.
+func (r *Repository) getFileStatusesArgs(revArgs []string) map[string]fileStatus {
+	cmd := exec.Command("git", r.diffArgs(revArgs, "--name-status", "-M")...)
 	cmd.Dir = r.RootPath
 
 	output, err := cmd.Output()
 	if err != nil {
-		return "", err
+		return make(map[string]fileStatus)
 	}
+	return parseNameStatus(string(output))
+}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		parts := strings.Fields(line)
-		if len(parts) >= 2 && parts[1] == filePath {
-			return parts[0], nil
+// getFileStatuses maps each path changed by a commit to its status.
+// Failures leave statuses empty.
+func (r *Repository) getFileStatuses(ref string) map[string]fileStatus {
+	cmd := exec.Command("git", "diff-tree", "--root", "--no-commit-id", "--name-status", "-M", "-r", ref)
+	cmd.Dir = r.RootPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return make(map[string]fileStatus)
+	}
+	return parseNameStatus(string(output))
+}
+
+// parseNameStatus parses --name-status output. Renames appear as
+// "R<score>\told\tnew" and are keyed by the new path with status R.
+func parseNameStatus(output string) map[string]fileStatus {
+	statuses := make(map[string]fileStatus)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) < 2 || parts[0] == "" {
+			continue
+		}
+
+		if parts[0][0] == 'R' && len(parts) >= 3 {
+			statuses[parts[2]] = fileStatus{Status: "R", OldPath: parts[1]}
+			continue
 		}
+		statuses[parts[1]] = fileStatus{Status: parts[0]}
+	}
+	return statuses
+}
+
+// parseNumstatLine parses a --numstat line into a file with its stats.
+// Binary files report "-" for both counts.
+func parseNumstatLine(line string) (*DiffFile, bool) {
+	parts := strings.SplitN(line, "\t", 3)
+	if len(parts) < 3 {
+		return nil, false
+	}
+
+	file := &DiffFile{}
+	file.OldPath, file.Path = parseRenamePath(parts[2])
+
+	if parts[0] == "-" && parts[1] == "-" {
+		file.IsBinary = true
+		return file, true
+	}
+	fmt.Sscanf(parts[0], "%d", &file.Additions)
+	fmt.Sscanf(parts[1], "%d", &file.Deletions)
+	return file, true
+}
+
+// parseRenamePath splits a numstat path into its old and new paths. git
+// writes renames as "old => new", or with the common parts factored out as
+// "src/{old => new}/file.go". The old path is empty when there is no rename.
+func parseRenamePath(path string) (string, string) {
+	if !strings.Contains(path, " => ") {
+		return "", path
+	}
+
+	start, end := strings.Index(path, "{"), strings.LastIndex(path, "}")
+	if start < 0 || end < start {
+		parts := strings.SplitN(path, " => ", 2)
+		return parts[0], parts[1]
 	}
 
-	return "M", nil // Default to modified
+	prefix, suffix := path[:start], path[end+1:]
+	parts := strings.SplitN(path[start+1:end], " => ", 2)
+	if len(parts) != 2 {
+		return "", path
+	}
+	return joinRenamePath(prefix, parts[0], suffix), joinRenamePath(prefix, parts[1], suffix)
+}
+
+// joinRenamePath rebuilds one side of a braced rename. An empty middle, as
+// in "src/{ => pkg}/file.go", would otherwise leave a doubled slash.
+func joinRenamePath(prefix, middle, suffix string) string {
+	if middle == "" {
+		return prefix + strings.TrimPrefix(suffix, "/")
+	}
+	return prefix + middle + suffix
+}
+
+// diffArgs builds a git diff command line: the given options, the revision
+// arguments, then the repository's pathspec
+func (r *Repository) diffArgs(revArgs []string, options ...string) []string {
+	args := append([]string{"diff"}, options...)
+	args = append(args, revArgs...)
+	return append(args, r.pathspecArgs()...)
 }
 
 // getFilePatch gets the patch for a specific file
-func (r *Repository) getFilePatch(ref, filePath string) (string, error) {
+func (r *Repository) getFilePatch(ref string, file *DiffFile) (string, error) {
 	// Get the actual diff
-	diffCmd := exec.Command("git", "diff", fmt.Sprintf("%s^", ref), ref, "--", filePath)
+	args := append([]string{"diff"}, r.diffOptions()...)
+	args = append(args, r.parentRef(ref), ref, "--")
+	diffCmd := exec.Command("git", append(args, file.paths()...)...)
 	diffCmd.Dir = r.RootPath
 
 	diffOutput, err := diffCmd.Output()
@@ -203,9 +303,11 @@ func (r *Repository) getFilePatch(ref, filePath string) (string, error) {
 	return string(diffOutput), nil
 }
 
-// getFilePatchRange gets the patch for a file in a range
-func (r *Repository) getFilePatchRange(rangeSpec, filePath string) (string, error) {
-	cmd := exec.Command("git", "diff", rangeSpec, "--", filePath)
+// getFilePatchArgs gets the patch for a file for git diff arguments
+func (r *Repository) getFilePatchArgs(revArgs []string, file *DiffFile) (string, error) {
+	args := append([]string{"diff"}, r.diffOptions()...)
+	args = append(args, revArgs...)
+	cmd := exec.Command("git", append(args, append([]string{"--"}, file.paths()...)...)...)
 	cmd.Dir = r.RootPath
 
 	output, err := cmd.Output()
@@ -216,29 +318,100 @@ func (r *Repository) getFilePatchRange(rangeSpec, filePath string) (string, erro
 	return string(output), nil
 }
 
+// diffOptions returns extra options for git diff based on repository
+// settings. Rename detection is always on so a renamed file's patch only
+// shows what changed.
+func (r *Repository) diffOptions() []string {
+	if r.IgnoreWhitespace {
+		return []string{"-M", "-w"}
+	}
+	return []string{"-M"}
+}
+
+// paths returns the paths to pass to git for a file's patch; both sides of
+// a rename are needed for git to pair them up
+func (f *DiffFile) paths() []string {
+	if f.OldPath != "" {
+		return []string{f.OldPath, f.Path}
+	}
+	return []string{f.Path}
+}
+
+// classifyFormatting marks a file whose patch only changes whitespace. With
+// IgnoreWhitespace, git already drops such hunks and leaves an empty patch.
+func (r *Repository) classifyFormatting(file *DiffFile) {
+	if file.Additions+file.Deletions == 0 {
+		return
+	}
+	if r.IgnoreWhitespace && strings.TrimSpace(file.Patch) == "" {
+		file.FormattingOnly = true
+		return
+	}
+	file.FormattingOnly = IsFormattingOnly(file.Patch)
+}
+
+// IsFormattingOnly reports whether a unified diff only reflows code: the
+// removed and added lines are identical once all whitespace is stripped
+func IsFormattingOnly(patch string) bool {
+	var removed, added strings.Builder
+	changed := false
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+		case strings.HasPrefix(line, "-"):
+			removed.WriteString(stripWhitespace(line[1:]))
+			changed = true
+		case strings.HasPrefix(line, "+"):
+			added.WriteString(stripWhitespace(line[1:]))
+			changed = true
+		}
+	}
+	return changed && removed.String() == added.String()
+}
+
+// stripWhitespace removes every whitespace character from a line
+func stripWhitespace(line string) string {
+	return strings.Join(strings.Fields(line), "")
+}
+
 // getDiffSummary gets a summary of the diff
 func (r *Repository) getDiffSummary(ref string) (string, error) {
-	// Try with parent first
-	cmd := exec.Command("git", "diff", "--stat", fmt.Sprintf("%s^", ref), ref)
+	cmd := exec.Command("git", "diff", "--stat", "-M", r.parentRef(ref), ref)
 	cmd.Dir = r.RootPath
 
 	output, err := cmd.Output()
 	if err != nil {
-		// Might be initial commit, try with --root
-		cmd = exec.Command("git", "show", "--stat", "--format=", ref)
-		cmd.Dir = r.RootPath
-		output, err = cmd.Output()
-		if err != nil {
-			return "", nil // Return empty summary on error
-		}
+		return "", nil // Return empty summary on error
 	}
 
 	return string(output), nil
 }
 
-// getDiffSummaryRange gets a summary for a range
-func (r *Repository) getDiffSummaryRange(rangeSpec string) (string, error) {
-	cmd := exec.Command("git", "diff", "--stat", rangeSpec)
+// pathspecArgs returns the trailing "-- <paths>" arguments for Pathspec
+func (r *Repository) pathspecArgs() []string {
+	if len(r.Pathspec) == 0 {
+		return nil
+	}
+	return append([]string{"--"}, r.Pathspec...)
+}
+
+// emptyTree is the hash of git's empty tree, used as the parent of a root
+// commit
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// parentRef returns the first parent of ref, or the empty tree when ref is
+// a root commit so that its diff shows every file as added
+func (r *Repository) parentRef(ref string) string {
+	parent := ref + "^"
+	if r.RefExists(parent) {
+		return parent
+	}
+	return emptyTree
+}
+
+// getDiffSummaryArgs gets a summary for git diff arguments
+func (r *Repository) getDiffSummaryArgs(revArgs []string) (string, error) {
+	cmd := exec.Command("git", r.diffArgs(revArgs, "--stat", "-M")...)
 	cmd.Dir = r.RootPath
 
 	output, err := cmd.Output()
@@ -261,3 +434,35 @@ func (r *Repository) GetFullDiff(ref string) (string, error) {
 
 	return string(output), nil
 }
+
+// ResolveRange splits a range spec into base and head refs. For three-dot
+// ranges (A...B) the base is the merge base of A and B. An empty head means
+// the working tree, matching `git diff <ref>`.
+func (r *Repository) ResolveRange(rangeSpec string) (string, string, error) {
+	if parts := strings.SplitN(rangeSpec, "...", 2); len(parts) == 2 {
+		left, right := defaultRef(parts[0]), defaultRef(parts[1])
+
+		cmd := exec.Command("git", "merge-base", left, right)
+		cmd.Dir = r.RootPath
+
+		output, err := cmd.Output()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to find merge base of %s and %s: %w", left, right, err)
+		}
+		return strings.TrimSpace(string(output)), right, nil
+	}
+
+	if parts := strings.SplitN(rangeSpec, "..", 2); len(parts) == 2 {
+		return defaultRef(parts[0]), defaultRef(parts[1]), nil
+	}
+
+	return rangeSpec, "", nil
+}
+
+// defaultRef returns HEAD for an omitted side of a range
+func defaultRef(ref string) string {
+	if ref == "" {
+		return "HEAD"
+	}
+	return ref
+}
@@ -0,0 +1,66 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo initializes a repository in a temporary directory with one
+// commit holding the given files
+func newTestRepo(t *testing.T, files map[string]string) *Repository {
+	t.Helper()
+	root := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := &Repository{RootPath: root}
+	runGit(t, repo, "init", "-q")
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "Initial commit")
+	return repo
+}
+
+// runGit runs a git command in the repository, failing the test on error
+func runGit(t *testing.T, repo *Repository, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repo.RootPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+func TestGetDiffRootCommit(t *testing.T) {
+	repo := newTestRepo(t, map[string]string{
+		"main.go": "package main\n\nfunc main() {}\n",
+	})
+
+	diff, err := repo.GetDiff("HEAD")
+	if err != nil {
+		t.Fatalf("GetDiff: %v", err)
+	}
+	if len(diff.Files) != 1 {
+		t.Fatalf("got %d files, want 1", len(diff.Files))
+	}
+
+	file := diff.Files[0]
+	if file.Path != "main.go" || file.Additions != 3 || file.Deletions != 0 {
+		t.Errorf("file = %s +%d -%d, want main.go +3 -0", file.Path, file.Additions, file.Deletions)
+	}
+	if !strings.Contains(file.Patch, "+func main() {}") {
+		t.Errorf("patch does not contain the added lines:\n%s", file.Patch)
+	}
+	if !strings.Contains(diff.Summary, "main.go") {
+		t.Errorf("summary does not mention main.go: %q", diff.Summary)
+	}
+}
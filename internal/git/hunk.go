@@ -0,0 +1,105 @@
+package git
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HunkLineKind classifies a line within a hunk
+type HunkLineKind int
+
+const (
+	HunkContext HunkLineKind = iota // unchanged line
+	HunkAdded                       // line added by the change
+	HunkDeleted                     // line removed by the change
+)
+
+// HunkLine is a single line of a hunk. OldLine and NewLine are the 1-based
+// line numbers on each side, or 0 when the line does not exist there.
+type HunkLine struct {
+	Kind    HunkLineKind
+	Content string // the line without its +, - or space prefix
+	OldLine int
+	NewLine int
+}
+
+// Hunk is one "@@ -a,b +c,d @@" section of a unified diff
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []HunkLine
+}
+
+// hunkHeader matches "@@ -a,b +c,d @@", where either count may be omitted
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseHunks splits a unified diff into its hunks. File headers and lines
+// outside a hunk are ignored, as are "\ No newline at end of file" markers.
+func ParseHunks(patch string) []Hunk {
+	hunks := make([]Hunk, 0)
+	var current *Hunk
+	oldLine, newLine := 0, 0
+
+	for _, line := range strings.Split(patch, "\n") {
+		if m := hunkHeader.FindStringSubmatch(line); m != nil {
+			hunks = append(hunks, Hunk{
+				OldStart: atoi(m[1]),
+				OldLines: hunkCount(m[2]),
+				NewStart: atoi(m[3]),
+				NewLines: hunkCount(m[4]),
+			})
+			current = &hunks[len(hunks)-1]
+			oldLine, newLine = current.OldStart, current.NewStart
+			continue
+		}
+		if current == nil || line == "" {
+			continue
+		}
+
+		switch line[0] {
+		case '+':
+			current.Lines = append(current.Lines, HunkLine{Kind: HunkAdded, Content: line[1:], NewLine: newLine})
+			newLine++
+		case '-':
+			current.Lines = append(current.Lines, HunkLine{Kind: HunkDeleted, Content: line[1:], OldLine: oldLine})
+			oldLine++
+		case ' ':
+			current.Lines = append(current.Lines, HunkLine{Kind: HunkContext, Content: line[1:], OldLine: oldLine, NewLine: newLine})
+			oldLine++
+			newLine++
+		case 'd':
+			// "diff --git" starts the next file in a multi-file patch
+			current = nil
+		}
+	}
+
+	return hunks
+}
+
+// AddedLines returns the new-file line numbers added by a hunk
+func (h Hunk) AddedLines() []int {
+	lines := make([]int, 0)
+	for _, line := range h.Lines {
+		if line.Kind == HunkAdded {
+			lines = append(lines, line.NewLine)
+		}
+	}
+	return lines
+}
+
+// hunkCount parses a hunk header line count, which git omits when it is 1
+func hunkCount(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
+
+// atoi parses a number matched by hunkHeader
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,39 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IsReflogRef reports whether ref uses reflog syntax, e.g. HEAD@{1} or
+// main@{yesterday}
+func IsReflogRef(ref string) bool {
+	return strings.Contains(ref, "@{")
+}
+
+// ValidateRange checks that every ref in a range resolves to a commit. A
+// reflog entry that has expired or was never written gets its own error so
+// it is not mistaken for a typo in a branch name.
+func (r *Repository) ValidateRange(rangeSpec string) error {
+	for _, ref := range rangeRefs(rangeSpec) {
+		if r.RefExists(ref) {
+			continue
+		}
+		if IsReflogRef(ref) {
+			return fmt.Errorf("reflog entry %s does not exist (see 'git reflog' for available entries)", ref)
+		}
+		return fmt.Errorf("%s does not resolve to a commit", ref)
+	}
+	return nil
+}
+
+// rangeRefs returns the refs named by a range spec, filling in HEAD for an
+// omitted side
+func rangeRefs(rangeSpec string) []string {
+	for _, sep := range []string{"...", ".."} {
+		if parts := strings.SplitN(rangeSpec, sep, 2); len(parts) == 2 {
+			return []string{defaultRef(parts[0]), defaultRef(parts[1])}
+		}
+	}
+	return []string{rangeSpec}
+}
@@ -11,6 +11,15 @@ import (
 // Repository represents a Git repository
 type Repository struct {
 	RootPath string
+
+	// IgnoreWhitespace passes -w to git diff when producing patches
+	IgnoreWhitespace bool
+
+	// Pathspec limits range diffs to these repository-relative paths
+	Pathspec []string
+
+	churn         map[string]*FileChurn // loaded on first GetFileChurn call
+	defaultBranch string                // cached by GetDefaultBranch
 }
 
 // FindRepository finds the Git repository root from the current directory
@@ -121,3 +130,65 @@ func (r *Repository) GetRelativePath(absPath string) (string, error) {
 	}
 	return relPath, nil
 }
+
+// GetHooksDir returns the absolute path of the repository's hooks directory,
+// honoring core.hooksPath
+func (r *Repository) GetHooksDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = r.RootPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate hooks directory: %w", err)
+	}
+
+	hooksDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(r.RootPath, hooksDir)
+	}
+	return hooksDir, nil
+}
+
+// RefExists reports whether a ref resolves to a commit
+func (r *Repository) RefExists(ref string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd.Dir = r.RootPath
+	return cmd.Run() == nil
+}
+
+// Fetch fetches refspecs from a remote, such as a pull request head that a
+// shallow CI checkout lacks
+func (r *Repository) Fetch(remote string, refspecs ...string) error {
+	cmd := exec.Command("git", append([]string{"fetch", "--quiet", remote}, refspecs...)...)
+	cmd.Dir = r.RootPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fetch from %s: %s", remote, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetDefaultBranch returns the branch origin/HEAD points to, falling back to
+// the first of main or master that exists. The result is cached.
+func (r *Repository) GetDefaultBranch() (string, error) {
+	if r.defaultBranch != "" {
+		return r.defaultBranch, nil
+	}
+
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	cmd.Dir = r.RootPath
+	if output, err := cmd.Output(); err == nil {
+		if ref := strings.TrimSpace(string(output)); ref != "" {
+			r.defaultBranch = ref
+			return ref, nil
+		}
+	}
+
+	for _, candidate := range []string{"main", "master", "origin/main", "origin/master"} {
+		if r.RefExists(candidate) {
+			r.defaultBranch = candidate
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not determine the default branch: origin/HEAD is not set and neither main nor master exists")
+}
@@ -0,0 +1,164 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the GitHub REST API endpoint used when none is configured
+const DefaultAPIURL = "https://api.github.com"
+
+// remotePattern matches the host, owner and name in HTTPS, SSH and
+// scp-style remote URLs, e.g. git@github.com:owner/repo.git
+var remotePattern = regexp.MustCompile(`^(?:https?://|ssh://)?(?:[^@/]+@)?([^:/]+)[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// WebHost returns the host git remotes use for the server behind apiURL:
+// github.com for the public API and the Enterprise server's host otherwise,
+// e.g. ghe.example.com for https://ghe.example.com/api/v3
+func WebHost(apiURL string) string {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	parsed, err := url.Parse(apiURL)
+	if err != nil || parsed.Hostname() == "" {
+		return "github.com"
+	}
+	return strings.TrimPrefix(parsed.Hostname(), "api.")
+}
+
+// ParseRemoteURL returns the owner and name of a repository from its
+// remote URL, which must point at host
+func ParseRemoteURL(remoteURL, host string) (owner, name string, err error) {
+	match := remotePattern.FindStringSubmatch(strings.TrimSpace(remoteURL))
+	if match == nil || !strings.EqualFold(match[1], host) {
+		return "", "", fmt.Errorf("remote is not a repository on %s", host)
+	}
+	return match[2], match[3], nil
+}
+
+// Client calls the GitHub REST API for one repository
+type Client struct {
+	token   string
+	baseURL string
+	owner   string
+	repo    string
+	client  *http.Client
+}
+
+// NewClient creates a client for owner/repo. An empty token sends
+// unauthenticated requests, which can read public repositories only; an
+// empty baseURL uses the public API.
+func NewClient(token, baseURL, owner, repo string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &Client{
+		token:   token,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		owner:   owner,
+		repo:    repo,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// PullRequest is the part of a pull request a review needs
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"html_url"`
+	Base   Branch `json:"base"`
+	Head   Branch `json:"head"`
+}
+
+// Branch is one side of a pull request
+type Branch struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// ReviewComment is an inline comment on a line of the pull request diff
+type ReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// GetPullRequest fetches a pull request by number
+func (c *Client) GetPullRequest(number int) (*PullRequest, error) {
+	var pr PullRequest
+	if err := c.do("GET", fmt.Sprintf("/repos/%s/%s/pulls/%d", c.owner, c.repo, number), nil, &pr); err != nil {
+		return nil, fmt.Errorf("failed to get pull request #%d: %w", number, err)
+	}
+	return &pr, nil
+}
+
+// CreateReview posts a review on commitID of a pull request: body as the
+// summary and comments inline. The review only comments; it never approves
+// or requests changes.
+func (c *Client) CreateReview(number int, commitID, body string, comments []ReviewComment) error {
+	request := map[string]interface{}{
+		"commit_id": commitID,
+		"body":      body,
+		"event":     "COMMENT",
+		"comments":  comments,
+	}
+	if err := c.do("POST", fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", c.owner, c.repo, number), request, nil); err != nil {
+		return fmt.Errorf("failed to post review on pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// do sends a request with an optional JSON body and decodes the JSON
+// response into result when it is not nil
+func (c *Client) do(method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package github
+
+import "testing"
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		remoteURL string
+		apiURL    string
+		want      string
+		wantErr   bool
+	}{
+		{"https", "https://github.com/owner/repo.git", "", "owner/repo", false},
+		{"scp", "git@github.com:owner/repo.git", "", "owner/repo", false},
+		{"ssh", "ssh://git@github.com/owner/repo", "https://api.github.com", "owner/repo", false},
+		{"enterprise https", "https://ghe.example.com/owner/repo.git", "https://ghe.example.com/api/v3", "owner/repo", false},
+		{"enterprise scp", "git@ghe.example.com:owner/repo.git", "https://ghe.example.com/api/v3/", "owner/repo", false},
+		{"enterprise remote on public api", "git@ghe.example.com:owner/repo.git", "", "", true},
+		{"public remote on enterprise api", "https://github.com/owner/repo", "https://ghe.example.com/api/v3", "", true},
+		{"other host", "https://gitlab.com/owner/repo.git", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, name, err := ParseRemoteURL(tt.remoteURL, WebHost(tt.apiURL))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRemoteURL accepted %s as %s/%s", tt.remoteURL, owner, name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRemoteURL: %v", err)
+			}
+			if got := owner + "/" + name; got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
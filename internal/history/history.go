@@ -0,0 +1,83 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxEntries bounds the number of builds kept in the history file
+const maxEntries = 200
+
+// Entry records repository-wide metrics for a single context build
+type Entry struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Commit      string    `json:"commit,omitempty"`
+	Files       int       `json:"files"`
+	LinesOfCode int       `json:"lines_of_code"`
+	Issues      int       `json:"issues"`
+	DebtMarkers int       `json:"debt_markers"`
+}
+
+// History is the sequence of recorded builds, oldest first
+type History struct {
+	Entries []Entry `json:"entries"`
+}
+
+// DefaultPath returns the history file location for a repository
+func DefaultPath(rootPath string) string {
+	return filepath.Join(rootPath, ".katich", "history.json")
+}
+
+// Load reads the history file. A missing file yields an empty history.
+func Load(path string) (*History, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &History{Entries: make([]Entry, 0)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var history History
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+	return &history, nil
+}
+
+// Save writes the history file, keeping only the most recent entries
+func (h *History) Save(path string) error {
+	if len(h.Entries) > maxEntries {
+		h.Entries = h.Entries[len(h.Entries)-maxEntries:]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Latest returns the most recent entry, or nil if none were recorded
+func (h *History) Latest() *Entry {
+	if len(h.Entries) == 0 {
+		return nil
+	}
+	return &h.Entries[len(h.Entries)-1]
+}
+
+// Add appends an entry
+func (h *History) Add(entry Entry) {
+	h.Entries = append(h.Entries, entry)
+}
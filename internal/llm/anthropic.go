@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// anthropicVersion is the Messages API version sent with every request
+	anthropicVersion = "2023-06-01"
+
+	// anthropicMaxTokens bounds the length of a reply; the API requires it
+	anthropicMaxTokens = 4096
+
+	// defaultAnthropicModel is used when no Claude model is configured
+	defaultAnthropicModel = "claude-sonnet-4-5"
+)
+
+// AnthropicProvider uses the Anthropic Messages API
+type AnthropicProvider struct {
+	apiKey  string
+	model   string
+	baseURL string
+	client  *http.Client
+}
+
+// NewAnthropicProvider creates a new Anthropic provider. An empty baseURL
+// uses the Anthropic API.
+func NewAnthropicProvider(apiKey, model, baseURL string) *AnthropicProvider {
+	if model == "" {
+		model = defaultAnthropicModel
+	}
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com/v1"
+	}
+
+	return &AnthropicProvider{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{
+			Timeout: 2 * time.Minute,
+		},
+	}
+}
+
+// Complete sends a Messages API request. The API has no JSON mode, so with
+// jsonOutput the system prompt asks for a bare JSON object instead.
+func (p *AnthropicProvider) Complete(system, prompt string, jsonOutput bool) (string, error) {
+	if jsonOutput {
+		system += "\n\nReply with the JSON object only, without any text before or after it."
+	}
+
+	requestBody := map[string]interface{}{
+		"model":      p.model,
+		"system":     system,
+		"max_tokens": anthropicMaxTokens,
+		"messages": []chatMessage{
+			{Role: "user", Content: prompt},
+		},
+		"temperature": 0,
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", p.baseURL+"/messages", bytes.NewReader(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%s request failed: %w", p.GetName(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("%s returned status %d: %s", p.GetName(), resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var reply strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			reply.WriteString(block.Text)
+		}
+	}
+	if reply.Len() == 0 {
+		return "", fmt.Errorf("%s returned an empty reply", p.GetName())
+	}
+	if response.StopReason == "max_tokens" {
+		return "", fmt.Errorf("%s reply was cut off after %d tokens", p.GetName(), anthropicMaxTokens)
+	}
+	return reply.String(), nil
+}
+
+// GetName returns the provider name
+func (p *AnthropicProvider) GetName() string {
+	return "Anthropic"
+}
+
+// GetModel returns the Claude model
+func (p *AnthropicProvider) GetModel() string {
+	return p.model
+}
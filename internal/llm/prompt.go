@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+// FileContext is a changed file to include in a review prompt
+type FileContext struct {
+	Path     string
+	Language string
+	Patch    string // unified diff for the file
+	Source   string // file content after the change, empty if deleted
+}
+
+// hunkHeader matches "@@ -a,b +c,d @@" and captures the new-file range
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// BuildReviewPrompt assembles the code section of a review prompt. Depending
+// on the review settings each file contributes its diff hunks, the hunks
+// plus surrounding lines, or the whole file up to MaxFileBytes.
+func BuildReviewPrompt(cfg config.ReviewConfig, files []FileContext) string {
+	var b strings.Builder
+
+	for _, file := range files {
+		fmt.Fprintf(&b, "## %s", file.Path)
+		if file.Language != "" {
+			fmt.Fprintf(&b, " (%s)", file.Language)
+		}
+		b.WriteString("\n\n")
+
+		b.WriteString("### Diff\n\n```diff\n")
+		b.WriteString(strings.TrimRight(file.Patch, "\n"))
+		b.WriteString("\n```\n\n")
+
+		if file.Source == "" {
+			continue
+		}
+
+		lines := strings.Split(strings.TrimSuffix(file.Source, "\n"), "\n")
+
+		switch {
+		case cfg.IncludeFullFile && (cfg.MaxFileBytes == 0 || len(file.Source) <= cfg.MaxFileBytes):
+			b.WriteString("### File\n\n```\n")
+			b.WriteString(numberLines(lines, 1, -1))
+			b.WriteString("```\n\n")
+
+		case cfg.ContextLines > 0:
+			b.WriteString("### Context\n\n")
+			for _, r := range mergeRanges(hunkRanges(file.Patch), cfg.ContextLines, len(lines)) {
+				b.WriteString("```\n")
+				b.WriteString(numberLines(lines, r[0], r[1]))
+				b.WriteString("```\n\n")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// hunkRanges returns the new-file line ranges [start, end] of each hunk
+func hunkRanges(patch string) [][2]int {
+	ranges := make([][2]int, 0)
+	for _, line := range strings.Split(patch, "\n") {
+		m := hunkHeader.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		start, _ := strconv.Atoi(m[1])
+		count := 1
+		if m[2] != "" {
+			count, _ = strconv.Atoi(m[2])
+		}
+		if count == 0 {
+			// Pure deletion: anchor the context at the deletion point
+			ranges = append(ranges, [2]int{start, start})
+			continue
+		}
+		ranges = append(ranges, [2]int{start, start + count - 1})
+	}
+	return ranges
+}
+
+// mergeRanges widens each range by context lines, clamps it to the file
+// and merges overlapping ranges
+func mergeRanges(ranges [][2]int, context, total int) [][2]int {
+	merged := make([][2]int, 0, len(ranges))
+	for _, r := range ranges {
+		start, end := r[0]-context, r[1]+context
+		if start < 1 {
+			start = 1
+		}
+		if end > total {
+			end = total
+		}
+		if start > end {
+			continue
+		}
+		if n := len(merged); n > 0 && start <= merged[n-1][1]+1 {
+			if end > merged[n-1][1] {
+				merged[n-1][1] = end
+			}
+			continue
+		}
+		merged = append(merged, [2]int{start, end})
+	}
+	return merged
+}
+
+// numberLines renders lines start..end (1-based, inclusive) with line
+// numbers. An end of -1 means the last line.
+func numberLines(lines []string, start, end int) string {
+	if end < 0 || end > len(lines) {
+		end = len(lines)
+	}
+
+	var b strings.Builder
+	for i := start; i <= end; i++ {
+		fmt.Fprintf(&b, "%5d  %s\n", i, lines[i-1])
+	}
+	return b.String()
+}
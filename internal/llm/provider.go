@@ -0,0 +1,156 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+// LLMProvider sends prompts to a language model
+type LLMProvider interface {
+	// Complete returns the model's reply to a system and a user prompt. With
+	// jsonOutput the model is asked to reply with a JSON object.
+	Complete(system, prompt string, jsonOutput bool) (string, error)
+	GetName() string
+	GetModel() string
+}
+
+// NewProvider creates the provider configured in llm.provider: openai,
+// anthropic or local. The local
+// provider talks to an OpenAI-compatible server at llm.base_url, such as
+// Ollama or llama.cpp.
+func NewProvider(cfg config.LLMConfig) (LLMProvider, error) {
+	switch cfg.Provider {
+	case "", "openai":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("LLM API key is required for provider: openai")
+		}
+		return NewOpenAIProvider(cfg.APIKey, cfg.Model, cfg.BaseURL), nil
+	case "anthropic":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("LLM API key is required for provider: anthropic")
+		}
+		model := cfg.Model
+		if model == config.DefaultConfig().LLM.Model {
+			// The default model is an OpenAI one; use a Claude model unless
+			// another was configured
+			model = ""
+		}
+		return NewAnthropicProvider(cfg.APIKey, model, cfg.BaseURL), nil
+	case "local":
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = "http://localhost:11434/v1"
+		}
+		return NewOpenAIProvider(cfg.APIKey, cfg.Model, baseURL), nil
+	}
+	return nil, fmt.Errorf("LLM provider %q is not supported (use openai, anthropic or local)", cfg.Provider)
+}
+
+// OpenAIProvider uses the OpenAI chat completions API
+type OpenAIProvider struct {
+	apiKey  string
+	model   string
+	baseURL string
+	client  *http.Client
+}
+
+// NewOpenAIProvider creates a new OpenAI provider. An empty baseURL uses the
+// OpenAI API.
+func NewOpenAIProvider(apiKey, model, baseURL string) *OpenAIProvider {
+	if model == "" {
+		model = "gpt-4"
+	}
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+
+	return &OpenAIProvider{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{
+			// Reviews of large files take a while to generate
+			Timeout: 2 * time.Minute,
+		},
+	}
+}
+
+// chatMessage is one message of a chat completion request
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Complete sends a chat completion request
+func (p *OpenAIProvider) Complete(system, prompt string, jsonOutput bool) (string, error) {
+	requestBody := map[string]interface{}{
+		"model": p.model,
+		"messages": []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+		"temperature": 0,
+	}
+	if jsonOutput {
+		requestBody["response_format"] = map[string]string{"type": "json_object"}
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", p.baseURL+"/chat/completions", bytes.NewReader(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.apiKey))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%s request failed: %w", p.GetName(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("%s returned status %d: %s", p.GetName(), resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(response.Choices) == 0 || response.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("%s returned an empty reply", p.GetName())
+	}
+	return response.Choices[0].Message.Content, nil
+}
+
+// GetName returns the provider name
+func (p *OpenAIProvider) GetName() string {
+	if p.baseURL != "https://api.openai.com/v1" {
+		return "OpenAI-compatible"
+	}
+	return "OpenAI"
+}
+
+// GetModel returns the chat model
+func (p *OpenAIProvider) GetModel() string {
+	return p.model
+}
@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ReviewSystemPrompt instructs the model how to review a file and how to
+// shape its reply
+const ReviewSystemPrompt = `You are a senior engineer reviewing code for a team that wants to avoid
+unnecessary, duplicated or AI-generated boilerplate. Report concrete
+problems only: bugs, missing error handling, needless complexity, logic
+that duplicates the related code shown, and code that ignores the
+project's existing helpers. Do not restate the static analysis findings
+and do not comment on formatting.
+
+Reply with a JSON object of the form
+{"findings": [{"line": 12, "severity": "warning", "message": "...", "suggestion": "..."}]}
+where line is the 1-based line in the reviewed file, severity is one of
+info, warning or error, and suggestion may be empty. Reply with
+{"findings": []} if there is nothing worth reporting.`
+
+// RelatedCode is a function elsewhere in the repository that resembles
+// the code under review
+type RelatedCode struct {
+	Path       string
+	Name       string
+	StartLine  int
+	EndLine    int
+	Similarity float32
+	Code       string
+}
+
+// ReviewFinding is one problem reported by the model
+type ReviewFinding struct {
+	Line       int    `json:"line"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+// BuildFileReviewPrompt assembles the prompt for reviewing one file: its
+// numbered source, truncated to maxBytes when that is set, the static
+// analysis findings already reported and similar code from the index
+func BuildFileReviewPrompt(path, language, source string, staticFindings []string, related []RelatedCode, maxBytes int) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## %s", path)
+	if language != "" {
+		fmt.Fprintf(&b, " (%s)", language)
+	}
+	b.WriteString("\n\n")
+
+	truncated := maxBytes > 0 && len(source) > maxBytes
+	if truncated {
+		source = source[:maxBytes]
+	}
+	lines := strings.Split(strings.TrimSuffix(source, "\n"), "\n")
+	if truncated && len(lines) > 1 {
+		// Drop the partial last line
+		lines = lines[:len(lines)-1]
+	}
+	b.WriteString("```\n")
+	b.WriteString(numberLines(lines, 1, -1))
+	b.WriteString("```\n")
+	if truncated {
+		fmt.Fprintf(&b, "\n(File truncated after line %d.)\n", len(lines))
+	}
+	b.WriteString("\n")
+
+	if len(staticFindings) > 0 {
+		b.WriteString("### Static analysis findings\n\n")
+		for _, finding := range staticFindings {
+			fmt.Fprintf(&b, "- %s\n", finding)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(related) > 0 {
+		b.WriteString("### Similar code elsewhere in the repository\n\n")
+		for _, code := range related {
+			fmt.Fprintf(&b, "%s:%d-%d %s (similarity %.2f)\n\n```\n%s\n```\n\n",
+				code.Path, code.StartLine, code.EndLine, code.Name, code.Similarity, strings.TrimRight(code.Code, "\n"))
+		}
+	}
+
+	return b.String()
+}
+
+// ParseReviewFindings reads the findings from a model reply, tolerating a
+// Markdown code fence around the JSON
+func ParseReviewFindings(reply string) ([]ReviewFinding, error) {
+	text := strings.TrimSpace(reply)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	}
+
+	var response struct {
+		Findings []ReviewFinding `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(text), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM review: %w", err)
+	}
+
+	findings := make([]ReviewFinding, 0, len(response.Findings))
+	for _, finding := range response.Findings {
+		if strings.TrimSpace(finding.Message) == "" {
+			continue
+		}
+		switch finding.Severity {
+		case "info", "warning", "error":
+		default:
+			finding.Severity = "warning"
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
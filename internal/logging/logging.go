@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/katichai/katich/internal/config"
+)
+
+// redacted replaces secret values in log records
+const redacted = "[redacted]"
+
+// sensitiveKeys are attribute key fragments whose values are never logged
+var sensitiveKeys = []string{"api_key", "apikey", "authorization", "password", "secret", "token"}
+
+// Logger writes structured JSON records of a run to the audit log
+type Logger struct {
+	*slog.Logger
+	file *os.File
+}
+
+// Dir returns the log directory of a repository
+func Dir(rootPath string) string {
+	return filepath.Join(rootPath, ".katich", "logs")
+}
+
+// Discard returns a logger that drops every record
+func Discard() *Logger {
+	return &Logger{Logger: slog.New(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))}
+}
+
+// Open starts the audit log configured for a repository, rotating the file
+// first if it has outgrown max_size_mb. A relative file is placed in
+// .katich/logs. secrets are the configured keys; any value containing one
+// is redacted, as are attributes whose key names a credential.
+func Open(rootPath string, cfg config.LogConfig, secrets ...string) (*Logger, error) {
+	path := cfg.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(Dir(rootPath), path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := rotate(path, cfg.MaxBytes(), cfg.MaxBackups); err != nil {
+		return nil, fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	handler := slog.NewJSONHandler(file, &slog.HandlerOptions{
+		Level:       parseLevel(cfg.Level),
+		ReplaceAttr: redactor(secrets),
+	})
+	return &Logger{Logger: slog.New(handler), file: file}, nil
+}
+
+// Close closes the log file
+func (l *Logger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// Phase logs the start of a named phase of the run and returns a function
+// that logs its duration when the phase ends
+func (l *Logger) Phase(name string) func() {
+	start := time.Now()
+	l.Debug("phase started", "phase", name)
+	return func() {
+		l.Info("phase finished", "phase", name, "duration_ms", time.Since(start).Milliseconds())
+	}
+}
+
+// parseLevel maps a configured level name to a slog level, defaulting to info
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// redactor returns a ReplaceAttr function that hides credentials, both by
+// attribute name and by value
+func redactor(secrets []string) func([]string, slog.Attr) slog.Attr {
+	known := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		// Very short values would redact unrelated text
+		if len(secret) >= 8 {
+			known = append(known, secret)
+		}
+	}
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		key := strings.ToLower(a.Key)
+		for _, fragment := range sensitiveKeys {
+			if strings.Contains(key, fragment) {
+				return slog.String(a.Key, redacted)
+			}
+		}
+
+		var text string
+		switch value := a.Value.Resolve(); {
+		case value.Kind() == slog.KindString:
+			text = value.String()
+		case value.Kind() == slog.KindAny:
+			err, ok := value.Any().(error)
+			if !ok {
+				return a
+			}
+			text = err.Error()
+		default:
+			return a
+		}
+
+		for _, secret := range known {
+			text = strings.ReplaceAll(text, secret, redacted)
+		}
+		return slog.String(a.Key, text)
+	}
+}
+
+// rotate moves an oversized log aside as path.1, shifting older backups up
+// and dropping the oldest beyond maxBackups. A maxBytes of 0 disables
+// rotation.
+func rotate(path string, maxBytes int64, maxBackups int) error {
+	info, err := os.Stat(path)
+	if err != nil || maxBytes <= 0 || info.Size() < maxBytes {
+		return nil
+	}
+
+	if maxBackups <= 0 {
+		return os.Remove(path)
+	}
+
+	os.Remove(backupPath(path, maxBackups))
+	for i := maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backupPath(path, i), backupPath(path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(path, backupPath(path, 1))
+}
+
+// backupPath returns the name of the nth rotated log
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
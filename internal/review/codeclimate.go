@@ -0,0 +1,103 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/katichai/katich/internal/analysis"
+)
+
+// codeClimateIssue is an issue in the Code Climate engine specification
+// (also consumed by GitLab Code Quality)
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Content     *codeClimateContent `json:"content,omitempty"`
+	Categories  []string            `json:"categories"`
+	Location    codeClimateLocation `json:"location"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+}
+
+type codeClimateContent struct {
+	Body string `json:"body"`
+}
+
+type codeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeClimateLines `json:"lines"`
+}
+
+type codeClimateLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
+}
+
+// WriteCodeClimate writes the result as a JSON array of Code Climate issues
+func WriteCodeClimate(w io.Writer, result *Result) error {
+	issues := make([]codeClimateIssue, 0, result.TotalFindings())
+
+	for _, file := range result.Files {
+		for _, finding := range file.Findings {
+			line := finding.Line
+			if line < 1 {
+				line = 1
+			}
+
+			issue := codeClimateIssue{
+				Type:        "issue",
+				CheckName:   string(finding.Type),
+				Description: finding.Message,
+				Categories:  []string{codeClimateCategory(finding.Type)},
+				Location: codeClimateLocation{
+					Path:  file.Path,
+					Lines: codeClimateLines{Begin: line, End: line},
+				},
+				Fingerprint: finding.Fingerprint,
+				Severity:    codeClimateSeverity(finding.Severity),
+			}
+			if finding.Suggestion != "" {
+				issue.Content = &codeClimateContent{Body: finding.Suggestion}
+			}
+
+			issues = append(issues, issue)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(issues); err != nil {
+		return fmt.Errorf("failed to encode Code Climate report: %w", err)
+	}
+
+	return nil
+}
+
+// codeClimateSeverity maps an issue severity to a Code Climate severity
+func codeClimateSeverity(severity analysis.Severity) string {
+	switch severity {
+	case analysis.SeverityError:
+		return "critical"
+	case analysis.SeverityWarning:
+		return "major"
+	default:
+		return "info"
+	}
+}
+
+// codeClimateCategory maps an issue type to a Code Climate category
+func codeClimateCategory(issueType analysis.IssueType) string {
+	switch issueType {
+	case analysis.IssueTypeComplexity, analysis.IssueTypeFunctionLength:
+		return "Complexity"
+	case analysis.IssueTypeDuplication:
+		return "Duplication"
+	case analysis.IssueTypeNaming, analysis.IssueTypeStyleViolation:
+		return "Style"
+	default:
+		return "Clarity"
+	}
+}
@@ -0,0 +1,105 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CommentPayload is what a pull or merge request poster would send: a
+// summary body plus one inline comment per finding that can be anchored to
+// a line of the diff
+type CommentPayload struct {
+	Body       string           `json:"body"`
+	Comments   []PlannedComment `json:"comments"`
+	Unanchored []PlannedComment `json:"unanchored"` // findings outside the diff, folded into the body when posted
+}
+
+// PlannedComment is an inline comment anchored to a line of the diff.
+// Side follows the GitHub convention: RIGHT for the new version of the
+// file.
+type PlannedComment struct {
+	Path        string `json:"path"`
+	Line        int    `json:"line"`
+	Side        string `json:"side,omitempty"`
+	Body        string `json:"body"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// PlanComments maps every finding to an inline comment. Code hosts only
+// accept comments on lines shown in the diff, so findings on other lines
+// are returned as unanchored.
+func PlanComments(result *Result) *CommentPayload {
+	payload := &CommentPayload{
+		Body:       BuildSummary(result).Markdown(),
+		Comments:   make([]PlannedComment, 0),
+		Unanchored: make([]PlannedComment, 0),
+	}
+
+	for _, file := range result.Files {
+		lines := file.diffLines()
+		for _, finding := range file.Findings {
+			comment := PlannedComment{
+				Path:        file.Path,
+				Line:        finding.Line,
+				Body:        commentBody(finding),
+				Fingerprint: finding.Fingerprint,
+			}
+			if lines[finding.Line] {
+				comment.Side = "RIGHT"
+				payload.Comments = append(payload.Comments, comment)
+			} else {
+				payload.Unanchored = append(payload.Unanchored, comment)
+			}
+		}
+	}
+
+	return payload
+}
+
+// ReviewBody returns the summary body followed by the unanchored findings,
+// which a code host will not accept as inline comments
+func (p *CommentPayload) ReviewBody() string {
+	if len(p.Unanchored) == 0 {
+		return p.Body
+	}
+
+	var b strings.Builder
+	b.WriteString(p.Body)
+	b.WriteString("**Findings outside the diff**\n\n")
+	for _, comment := range p.Unanchored {
+		fmt.Fprintf(&b, "- `%s:%d` %s\n", comment.Path, comment.Line, strings.ReplaceAll(comment.Body, "\n\n", " — "))
+	}
+	return b.String()
+}
+
+// WriteCommentPayload writes the planned comments as indented JSON
+func WriteCommentPayload(w io.Writer, result *Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(PlanComments(result))
+}
+
+// diffLines returns the new-file lines shown in the file's diff, added or
+// context, which are the lines an inline comment can be attached to
+func (f FileResult) diffLines() map[int]bool {
+	lines := make(map[int]bool)
+	for _, hunk := range f.hunks {
+		for _, line := range hunk.Lines {
+			if line.NewLine > 0 {
+				lines[line.NewLine] = true
+			}
+		}
+	}
+	return lines
+}
+
+// commentBody renders a finding as the Markdown body of a comment
+func commentBody(finding Finding) string {
+	body := fmt.Sprintf("**%s** (%s): %s", finding.Type, finding.Severity, finding.Message)
+	if finding.Suggestion != "" {
+		body += "\n\n" + finding.Suggestion
+	}
+	return body
+}
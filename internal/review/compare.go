@@ -0,0 +1,165 @@
+package review
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/katichai/katich/internal/analysis"
+)
+
+// WriteJSON writes the result model as indented JSON. Saved results can be
+// compared with LoadResult and Compare.
+func WriteJSON(w io.Writer, result *Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to encode review result: %w", err)
+	}
+	return nil
+}
+
+// LoadResult reads a review saved with --output json, or a Code Climate
+// report, whose issues carry the same fingerprints
+func LoadResult(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read review result: %w", err)
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var issues []codeClimateIssue
+		if err := json.Unmarshal(trimmed, &issues); err != nil {
+			return nil, fmt.Errorf("failed to parse Code Climate report %s: %w", path, err)
+		}
+		return resultFromCodeClimate(issues), nil
+	}
+
+	result := NewResult()
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("failed to parse review result %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// resultFromCodeClimate rebuilds the findings of a Code Climate report,
+// grouped by file in report order
+func resultFromCodeClimate(issues []codeClimateIssue) *Result {
+	result := NewResult()
+	files := make(map[string]int)
+
+	for _, issue := range issues {
+		index, ok := files[issue.Location.Path]
+		if !ok {
+			index = len(result.Files)
+			files[issue.Location.Path] = index
+			result.Files = append(result.Files, FileResult{Path: issue.Location.Path, Findings: make([]Finding, 0)})
+		}
+
+		finding := Finding{
+			Issue: analysis.Issue{
+				Type:     analysis.IssueType(issue.CheckName),
+				Severity: severityFromCodeClimate(issue.Severity),
+				Line:     issue.Location.Lines.Begin,
+				Message:  issue.Description,
+			},
+			Fingerprint: issue.Fingerprint,
+		}
+		if issue.Content != nil {
+			finding.Suggestion = issue.Content.Body
+		}
+		result.Files[index].Findings = append(result.Files[index].Findings, finding)
+	}
+
+	return result
+}
+
+// severityFromCodeClimate reverses codeClimateSeverity
+func severityFromCodeClimate(severity string) analysis.Severity {
+	switch severity {
+	case "critical", "blocker":
+		return analysis.SeverityError
+	case "major":
+		return analysis.SeverityWarning
+	default:
+		return analysis.SeverityInfo
+	}
+}
+
+// ComparedFinding is a finding together with the file it appears in
+type ComparedFinding struct {
+	Path string `json:"path"`
+	Finding
+}
+
+// Comparison sorts the findings of two reviews of the same change by
+// whether a later run still reports them
+type Comparison struct {
+	Resolved   []ComparedFinding `json:"resolved"`   // only in the old review
+	Persisting []ComparedFinding `json:"persisting"` // in both, as reported by the new review
+	Introduced []ComparedFinding `json:"introduced"` // only in the new review
+}
+
+// Compare matches findings by fingerprint, so a finding whose code merely
+// moved still counts as persisting. A fingerprint reported several times
+// is matched as often as it appears in both reviews.
+func Compare(oldResult, newResult *Result) *Comparison {
+	comparison := &Comparison{
+		Resolved:   make([]ComparedFinding, 0),
+		Persisting: make([]ComparedFinding, 0),
+		Introduced: make([]ComparedFinding, 0),
+	}
+
+	oldFindings, newFindings := flattenFindings(oldResult), flattenFindings(newResult)
+
+	inOld := countFingerprints(oldFindings)
+	for _, finding := range newFindings {
+		if inOld[finding.Fingerprint] > 0 {
+			inOld[finding.Fingerprint]--
+			comparison.Persisting = append(comparison.Persisting, finding)
+		} else {
+			comparison.Introduced = append(comparison.Introduced, finding)
+		}
+	}
+
+	inNew := countFingerprints(newFindings)
+	for _, finding := range oldFindings {
+		if inNew[finding.Fingerprint] > 0 {
+			inNew[finding.Fingerprint]--
+		} else {
+			comparison.Resolved = append(comparison.Resolved, finding)
+		}
+	}
+
+	return comparison
+}
+
+// countFingerprints counts how often each fingerprint occurs
+func countFingerprints(findings []ComparedFinding) map[string]int {
+	counts := make(map[string]int)
+	for _, finding := range findings {
+		counts[finding.Fingerprint]++
+	}
+	return counts
+}
+
+// flattenFindings lists every finding of a result by path and line
+func flattenFindings(result *Result) []ComparedFinding {
+	findings := make([]ComparedFinding, 0, result.TotalFindings())
+	for _, file := range result.Files {
+		for _, finding := range file.Findings {
+			findings = append(findings, ComparedFinding{Path: file.Path, Finding: finding})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
@@ -0,0 +1,56 @@
+package review
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/katichai/katich/internal/analysis"
+)
+
+// Fingerprint returns a stable identifier for an issue. It hashes the rule,
+// the file path and the whitespace-normalized source context (rather than
+// the line number) so an issue keeps its identity when code above it moves.
+// occurrence tells apart issues of the same rule on identical lines in one
+// file; the first occurrence (0) hashes as if it were unique.
+func Fingerprint(rule analysis.IssueType, path, context string, occurrence int) string {
+	normalized := strings.Join(strings.Fields(context), " ")
+	data := fmt.Sprintf("%s|%s|%s", rule, path, normalized)
+	if occurrence > 0 {
+		data = fmt.Sprintf("%s|%d", data, occurrence)
+	}
+	hash := sha256.Sum256([]byte(data))
+	return fmt.Sprintf("%x", hash[:16])
+}
+
+// Fingerprinter fingerprints the issues of one file from the source that
+// was analyzed, numbering repeats of the same rule and line content in
+// the order the issues are fingerprinted
+type Fingerprinter struct {
+	path  string
+	lines []string
+	seen  map[string]int
+}
+
+// NewFingerprinter creates a Fingerprinter for the file at path with the
+// given analyzed content
+func NewFingerprinter(path string, source []byte) *Fingerprinter {
+	var lines []string
+	if source != nil {
+		lines = strings.Split(string(source), "\n")
+	}
+	return &Fingerprinter{path: path, lines: lines, seen: make(map[string]int)}
+}
+
+// Fingerprint returns the fingerprint for an issue, using its line's
+// content as context or its message when the line is unknown
+func (f *Fingerprinter) Fingerprint(issue analysis.Issue) string {
+	context := issue.Message
+	if issue.Line > 0 && issue.Line <= len(f.lines) {
+		context = f.lines[issue.Line-1]
+	}
+	key := Fingerprint(issue.Type, f.path, context, 0)
+	occurrence := f.seen[key]
+	f.seen[key]++
+	return Fingerprint(issue.Type, f.path, context, occurrence)
+}
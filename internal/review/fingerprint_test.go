@@ -0,0 +1,40 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/git"
+)
+
+func TestNewFileResultFingerprints(t *testing.T) {
+	source := []byte("package p\n\nfunc f() {\n\t_ = x == x\n\t_ = x == x\n}\n")
+	fileAnalysis := &analysis.FileAnalysis{
+		Issues: []analysis.Issue{
+			{Type: analysis.IssueTypeSimplification, Line: 4, Message: "compares a value with itself"},
+			{Type: analysis.IssueTypeSimplification, Line: 5, Message: "compares a value with itself"},
+		},
+	}
+
+	result := NewFileResult(&git.DiffFile{Path: "p.go"}, fileAnalysis, source)
+	if len(result.Findings) != 2 {
+		t.Fatalf("got %d findings, want 2", len(result.Findings))
+	}
+
+	first, second := result.Findings[0].Fingerprint, result.Findings[1].Fingerprint
+	if first == second {
+		t.Error("identical lines in one file share a fingerprint")
+	}
+	if want := Fingerprint(analysis.IssueTypeSimplification, "p.go", "\t_ = x == x", 0); first != want {
+		t.Errorf("first fingerprint = %s, want the unnumbered %s", first, want)
+	}
+
+	// The fingerprint comes from the given source, not from a file on disk
+	moved := []byte("package p\n\n// moved down\nfunc f() {\n\t_ = x == x\n}\n")
+	fileAnalysis.Issues = fileAnalysis.Issues[:1]
+	fileAnalysis.Issues[0].Line = 5
+	result = NewFileResult(&git.DiffFile{Path: "p.go"}, fileAnalysis, moved)
+	if got := result.Findings[0].Fingerprint; got != first {
+		t.Errorf("fingerprint changed when the line moved: %s, want %s", got, first)
+	}
+}
@@ -0,0 +1,67 @@
+package review
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// htmlReport is the self-contained page rendered by WriteHTML
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>katich review{{with .Result.Commit}} · {{.Hash}}{{end}}{{with .Result.Range}} · {{.}}{{end}}</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem auto; max-width: 960px; color: #1f2328; }
+  h1 { font-size: 1.5rem; }
+  h2 { font-size: 1.1rem; margin-top: 2rem; border-bottom: 1px solid #d0d7de; padding-bottom: .3rem; }
+  table { border-collapse: collapse; width: 100%; margin-top: .5rem; }
+  th, td { text-align: left; padding: .35rem .6rem; border-bottom: 1px solid #eaeef2; vertical-align: top; }
+  code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: .9em; }
+  .meta { color: #57606a; }
+  .status { font-size: 1.05rem; padding: .6rem .8rem; background: #f6f8fa; border-radius: 6px; }
+  .error { color: #cf222e; font-weight: 600; }
+  .warning { color: #9a6700; font-weight: 600; }
+  .info { color: #0969da; }
+  .suggestion { color: #57606a; font-size: .9em; }
+</style>
+</head>
+<body>
+<h1>katich review</h1>
+{{with .Result.Commit}}<p class="meta"><code>{{.Hash}}</code> by {{.Author}} &lt;{{.Email}}&gt; on {{.Date.Format "2006-01-02 15:04"}}<br>{{.Message}}</p>{{end}}
+{{with .Result.Range}}<p class="meta">Range <code>{{.}}</code></p>{{end}}
+<p class="status">{{.Status}}</p>
+{{if .Result.Symbols}}
+<h2>Changed symbols</h2>
+<table>
+<tr><th>Change</th><th>Kind</th><th>Symbol</th><th>File</th></tr>
+{{range .Result.Symbols}}<tr><td>{{.Change}}</td><td>{{.Kind}}</td><td><code>{{.Name}}</code></td><td><code>{{.File}}</code></td></tr>
+{{end}}</table>
+{{end}}
+{{range .Result.Files}}{{if .Findings}}
+<h2><code>{{.Path}}</code> <span class="meta">+{{.Additions}} −{{.Deletions}}</span></h2>
+<table>
+<tr><th>Line</th><th>Severity</th><th>Issue</th></tr>
+{{range .Findings}}<tr><td>{{.Line}}</td><td class="{{.Severity}}">{{.Severity}}</td><td>{{.Message}}{{with .Suggestion}}<div class="suggestion">{{.}}</div>{{end}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes the result as a standalone HTML page
+func WriteHTML(w io.Writer, result *Result) error {
+	data := struct {
+		Result *Result
+		Status string
+	}{
+		Result: result,
+		Status: result.StatusLine(),
+	}
+
+	if err := htmlReport.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
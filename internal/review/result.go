@@ -0,0 +1,258 @@
+package review
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/git"
+)
+
+// SchemaVersion identifies the layout of the JSON review output. It changes
+// only when existing fields are renamed or removed.
+const SchemaVersion = 1
+
+// Result is the outcome of a review. All output formats render this model.
+type Result struct {
+	SchemaVersion int                       `json:"schema_version"`
+	Commit        *CommitInfo               `json:"commit,omitempty"`
+	Range         string                    `json:"range,omitempty"`
+	Symbols       []SymbolChange            `json:"symbols"`
+	Files         []FileResult              `json:"files"`
+	Duplicates    []analysis.DuplicateBlock `json:"duplicates"`
+}
+
+// CommitInfo describes the reviewed commit
+type CommitInfo struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+	Body    string    `json:"body,omitempty"`
+}
+
+// FileResult holds the review findings for a single file
+type FileResult struct {
+	Path      string    `json:"path"`
+	Language  string    `json:"language,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Additions int       `json:"additions"`
+	Deletions int       `json:"deletions"`
+	Findings  []Finding `json:"findings"`
+
+	// Metrics of the file after the change; nil for files not analyzed
+	Metrics *analysis.CodeMetrics `json:"metrics,omitempty"`
+
+	// FormattingOnly files are reported but not analyzed
+	FormattingOnly bool `json:"formatting_only,omitempty"`
+
+	// Weight multiplies the score penalty of the file's findings. Files
+	// under a critical path weigh more; zero counts as 1.
+	Weight float64 `json:"weight,omitempty"`
+
+	// FailOn fails the review on any finding in this file at or above this
+	// severity, regardless of the overall score
+	FailOn analysis.Severity `json:"fail_on,omitempty"`
+
+	hunks []git.Hunk // anchors for inline comments
+}
+
+// Finding is an analysis issue with a stable fingerprint that identifies it
+// across runs even when surrounding lines move
+type Finding struct {
+	analysis.Issue
+	Fingerprint string `json:"fingerprint"`
+}
+
+// NewResult creates an empty review result
+func NewResult() *Result {
+	return &Result{
+		SchemaVersion: SchemaVersion,
+		Symbols:       make([]SymbolChange, 0),
+		Files:         make([]FileResult, 0),
+		Duplicates:    make([]analysis.DuplicateBlock, 0),
+	}
+}
+
+// NewCommitInfo converts git commit metadata for a review result
+func NewCommitInfo(commit *git.Commit) *CommitInfo {
+	if commit == nil {
+		return nil
+	}
+	return &CommitInfo{
+		Hash:    commit.Hash,
+		Author:  commit.Author,
+		Email:   commit.Email,
+		Date:    commit.Date,
+		Message: commit.Message,
+		Body:    commit.Body,
+	}
+}
+
+// NewFileResult builds the findings for a changed file. source is the
+// content that was analyzed, from which fingerprints are derived.
+func NewFileResult(file *git.DiffFile, fileAnalysis *analysis.FileAnalysis, source []byte) FileResult {
+	result := FileResult{
+		Path:      file.Path,
+		Status:    file.Status,
+		Additions: file.Additions,
+		Deletions: file.Deletions,
+		Findings:  make([]Finding, 0),
+
+		FormattingOnly: file.FormattingOnly,
+
+		hunks: file.Hunks,
+	}
+
+	if fileAnalysis == nil || file.FormattingOnly {
+		return result
+	}
+
+	result.Language = fileAnalysis.Language
+	metrics := fileAnalysis.Metrics
+	result.Metrics = &metrics
+	fingerprints := NewFingerprinter(file.Path, source)
+
+	for _, issue := range fileAnalysis.Issues {
+		result.Findings = append(result.Findings, Finding{
+			Issue:       issue,
+			Fingerprint: fingerprints.Fingerprint(issue),
+		})
+	}
+
+	return result
+}
+
+// FilterChangedLines drops the findings that are not on a line the diff
+// added and returns how many were dropped. Context lines around a hunk do
+// not count. Findings without a line and files without a parsed patch are
+// kept, as they cannot be placed.
+func (f *FileResult) FilterChangedLines() int {
+	if len(f.hunks) == 0 {
+		return 0
+	}
+
+	added := addedLines(f.hunks)
+	kept := make([]Finding, 0, len(f.Findings))
+	for _, finding := range f.Findings {
+		if finding.Line <= 0 || added[finding.Line] {
+			kept = append(kept, finding)
+		}
+	}
+	dropped := len(f.Findings) - len(kept)
+	f.Findings = kept
+	return dropped
+}
+
+// addedLines returns the set of new-file lines the hunks add
+func addedLines(hunks []git.Hunk) map[int]bool {
+	lines := make(map[int]bool)
+	for _, hunk := range hunks {
+		for _, line := range hunk.AddedLines() {
+			lines[line] = true
+		}
+	}
+	return lines
+}
+
+// TotalFindings returns the number of findings across all files
+func (r *Result) TotalFindings() int {
+	total := 0
+	for _, file := range r.Files {
+		total += len(file.Findings)
+	}
+	return total
+}
+
+// CountBySeverity returns the number of findings per severity
+func (r *Result) CountBySeverity() map[analysis.Severity]int {
+	counts := make(map[analysis.Severity]int)
+	for _, file := range r.Files {
+		for _, finding := range file.Findings {
+			counts[finding.Severity]++
+		}
+	}
+	return counts
+}
+
+// severityPenalty is the score deduction for a finding of each severity
+var severityPenalty = map[analysis.Severity]int{
+	analysis.SeverityError:   10,
+	analysis.SeverityWarning: 3,
+	analysis.SeverityInfo:    1,
+}
+
+// QualityScore rates the reviewed change from 0 to 100, deducting points
+// for each finding according to its severity and the weight of its file
+func (r *Result) QualityScore() int {
+	penalty := 0.0
+	for _, file := range r.Files {
+		weight := file.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		for _, finding := range file.Findings {
+			penalty += float64(severityPenalty[finding.Severity]) * weight
+		}
+	}
+
+	score := 100 - int(math.Round(penalty))
+	if score < 0 {
+		score = 0
+	}
+	return score
+}
+
+// severityRank orders severities from least to most severe
+var severityRank = map[analysis.Severity]int{
+	analysis.SeverityInfo:    1,
+	analysis.SeverityWarning: 2,
+	analysis.SeverityError:   3,
+}
+
+// CountAtOrAbove returns the number of findings of the given severity or
+// worse
+func (r *Result) CountAtOrAbove(severity analysis.Severity) int {
+	count := 0
+	for _, file := range r.Files {
+		for _, finding := range file.Findings {
+			if severityRank[finding.Severity] >= severityRank[severity] {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// CriticalFailures returns the findings in files with a FailOn severity
+// that reach it, formatted as path:line messages
+func (r *Result) CriticalFailures() []string {
+	failures := make([]string, 0)
+	for _, file := range r.Files {
+		if file.FailOn == "" {
+			continue
+		}
+		for _, finding := range file.Findings {
+			if severityRank[finding.Severity] >= severityRank[file.FailOn] {
+				failures = append(failures, fmt.Sprintf("%s:%d %s", file.Path, finding.Line, finding.Message))
+			}
+		}
+	}
+	return failures
+}
+
+// StatusLine returns a one-line summary of the review outcome
+func (r *Result) StatusLine() string {
+	total := r.TotalFindings()
+	if total == 0 {
+		return fmt.Sprintf("✅ Reviewed %d file(s), 0 issues, quality score %d", len(r.Files), r.QualityScore())
+	}
+
+	counts := r.CountBySeverity()
+	return fmt.Sprintf("⚠️  Reviewed %d file(s), %d issue(s) (%d error, %d warning, %d info), quality score %d",
+		len(r.Files), total,
+		counts[analysis.SeverityError], counts[analysis.SeverityWarning], counts[analysis.SeverityInfo],
+		r.QualityScore())
+}
@@ -0,0 +1,35 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/katichai/katich/internal/analysis"
+	"github.com/katichai/katich/internal/git"
+)
+
+func TestFilterChangedLinesSkipsContext(t *testing.T) {
+	// Line 11 is added; 8-10 and 12-14 are unchanged context
+	patch := "@@ -8,6 +8,7 @@ func f() {\n a := 1\n b := 2\n c := 3\n+d := 4\n e := 5\n f := 6\n g := 7\n"
+	file := &git.DiffFile{Path: "p.go", Hunks: git.ParseHunks(patch)}
+	fileAnalysis := &analysis.FileAnalysis{
+		Issues: []analysis.Issue{
+			{Type: analysis.IssueTypeComplexity, Line: 9, Message: "context above"},
+			{Type: analysis.IssueTypeComplexity, Line: 11, Message: "added"},
+			{Type: analysis.IssueTypeComplexity, Line: 13, Message: "context below"},
+			{Type: analysis.IssueTypeComplexity, Message: "no line"},
+		},
+	}
+
+	result := NewFileResult(file, fileAnalysis, nil)
+	if dropped := result.FilterChangedLines(); dropped != 2 {
+		t.Errorf("dropped %d findings, want 2", dropped)
+	}
+
+	var kept []string
+	for _, finding := range result.Findings {
+		kept = append(kept, finding.Message)
+	}
+	if len(kept) != 2 || kept[0] != "added" || kept[1] != "no line" {
+		t.Errorf("kept %q, want [added no line]", kept)
+	}
+}
@@ -0,0 +1,157 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/katichai/katich/internal/analysis"
+)
+
+// sarifSchema is the JSON schema of SARIF 2.1.0, the format GitHub code
+// scanning accepts
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF writes the result as a SARIF 2.1.0 log for upload to GitHub
+// code scanning. Each finding becomes a result whose rule is its issue type;
+// the suggestion is appended to the message, which is what code scanning
+// shows inline.
+func WriteSARIF(w io.Writer, result *Result) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "katich",
+			InformationURI: "https://github.com/katichai/katich",
+			Rules:          make([]sarifRule, 0),
+		}},
+		Results: make([]sarifResult, 0, result.TotalFindings()),
+	}
+	ruleIndex := make(map[analysis.IssueType]int)
+
+	for _, file := range result.Files {
+		for _, finding := range file.Findings {
+			index, ok := ruleIndex[finding.Type]
+			if !ok {
+				index = len(run.Tool.Driver.Rules)
+				ruleIndex[finding.Type] = index
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+					ID:               string(finding.Type),
+					ShortDescription: sarifMessage{Text: ruleDescription(finding.Type)},
+				})
+			}
+
+			line := finding.Line
+			if line < 1 {
+				line = 1
+			}
+			text := finding.Message
+			if finding.Suggestion != "" {
+				text += "\n\nSuggestion: " + finding.Suggestion
+			}
+
+			sarif := sarifResult{
+				RuleID:    string(finding.Type),
+				RuleIndex: index,
+				Level:     sarifLevel(finding.Severity),
+				Message:   sarifMessage{Text: text},
+				Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: file.Path, URIBaseID: "%SRCROOT%"},
+					Region:           sarifRegion{StartLine: line},
+				}}},
+			}
+			if finding.Fingerprint != "" {
+				sarif.PartialFingerprints = map[string]string{"katich/v1": finding.Fingerprint}
+			}
+			run.Results = append(run.Results, sarif)
+		}
+	}
+
+	log := sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to encode SARIF report: %w", err)
+	}
+	return nil
+}
+
+// sarifLevel maps an issue severity to a SARIF result level
+func sarifLevel(severity analysis.Severity) string {
+	switch severity {
+	case analysis.SeverityError:
+		return "error"
+	case analysis.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// ruleDescription returns the catalog description of an issue type, or the
+// type itself for issues outside the catalog
+func ruleDescription(issueType analysis.IssueType) string {
+	for _, check := range analysis.Checks {
+		if check.Type == issueType {
+			return check.Description
+		}
+	}
+	return string(issueType)
+}
@@ -0,0 +1,155 @@
+package review
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/katichai/katich/internal/analysis"
+)
+
+// dependencyManifests are files whose changes can alter frameworks or
+// dependencies
+var dependencyManifests = map[string]bool{
+	"go.mod":           true,
+	"package.json":     true,
+	"requirements.txt": true,
+	"pyproject.toml":   true,
+	"Pipfile":          true,
+	"pom.xml":          true,
+	"build.gradle":     true,
+	"build.gradle.kts": true,
+	"Cargo.toml":       true,
+	"Gemfile":          true,
+	"composer.json":    true,
+}
+
+// Summary is the top-level synopsis of a review, shown before inline details
+type Summary struct {
+	Files        int                       `json:"files"`
+	Findings     int                       `json:"findings"`
+	BySeverity   map[analysis.Severity]int `json:"by_severity"`
+	QualityScore int                       `json:"quality_score"`
+	Duplicates   []FindingLocation         `json:"duplicates,omitempty"`
+	Dependencies []string                  `json:"dependencies,omitempty"` // changed manifests, e.g. go.mod
+	NewSymbols   int                       `json:"new_symbols"`
+}
+
+// FindingLocation is a finding together with the file it appears in
+type FindingLocation struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// BuildSummary condenses a review result into counts, the quality score,
+// new duplicates and dependency changes
+func BuildSummary(result *Result) *Summary {
+	summary := &Summary{
+		Files:        len(result.Files),
+		Findings:     result.TotalFindings(),
+		BySeverity:   result.CountBySeverity(),
+		QualityScore: result.QualityScore(),
+	}
+
+	for _, file := range result.Files {
+		if dependencyManifests[path.Base(file.Path)] {
+			summary.Dependencies = append(summary.Dependencies, file.Path)
+		}
+		for _, finding := range file.Findings {
+			if finding.Type == analysis.IssueTypeDuplication {
+				summary.Duplicates = append(summary.Duplicates, FindingLocation{
+					Path:    file.Path,
+					Line:    finding.Line,
+					Message: finding.Message,
+				})
+			}
+		}
+	}
+
+	for _, sym := range result.Symbols {
+		if sym.Change == SymbolAdded {
+			summary.NewSymbols++
+		}
+	}
+
+	return summary
+}
+
+// Markdown renders the summary as a short Markdown section suitable for a
+// pull request comment
+func (s *Summary) Markdown() string {
+	var b strings.Builder
+
+	b.WriteString("### katich review summary\n\n")
+	fmt.Fprintf(&b, "**Quality score: %d/100** · %d file(s) · %d issue(s)\n\n", s.QualityScore, s.Files, s.Findings)
+
+	if s.Findings > 0 {
+		b.WriteString("| Severity | Count |\n|---|---|\n")
+		for _, severity := range []analysis.Severity{analysis.SeverityError, analysis.SeverityWarning, analysis.SeverityInfo} {
+			fmt.Fprintf(&b, "| %s | %d |\n", severity, s.BySeverity[severity])
+		}
+		b.WriteString("\n")
+	}
+
+	if s.NewSymbols > 0 {
+		fmt.Fprintf(&b, "🧩 %d new symbol(s)\n\n", s.NewSymbols)
+	}
+
+	if len(s.Duplicates) > 0 {
+		b.WriteString("**New duplicates**\n\n")
+		for _, dup := range s.Duplicates {
+			fmt.Fprintf(&b, "- `%s:%d` %s\n", dup.Path, dup.Line, dup.Message)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(s.Dependencies) > 0 {
+		b.WriteString("**Dependency changes**\n\n")
+		for _, manifest := range s.Dependencies {
+			fmt.Fprintf(&b, "- `%s`\n", manifest)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// WriteMarkdown writes the summary followed by per-file findings
+func WriteMarkdown(w io.Writer, result *Result) error {
+	var b strings.Builder
+	b.WriteString(BuildSummary(result).Markdown())
+
+	for _, file := range result.Files {
+		if len(file.Findings) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "#### `%s`\n\n", file.Path)
+		if file.Metrics != nil {
+			fmt.Fprintf(&b, "%d lines of code, %d function(s), complexity %d\n\n",
+				file.Metrics.LinesOfCode, file.Metrics.FunctionCount, file.Metrics.CyclomaticComplexity)
+		}
+		b.WriteString("| Line | Severity | Issue |\n|---|---|---|\n")
+		for _, finding := range file.Findings {
+			message := finding.Message
+			if finding.Suggestion != "" {
+				message += " — " + finding.Suggestion
+			}
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", finding.Line, finding.Severity, escapeTableCell(message))
+		}
+		b.WriteString("\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write Markdown report: %w", err)
+	}
+	return nil
+}
+
+// escapeTableCell keeps a message on one Markdown table row
+func escapeTableCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
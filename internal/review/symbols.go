@@ -0,0 +1,110 @@
+package review
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/katichai/katich/internal/analysis"
+)
+
+// Symbol change kinds
+const (
+	SymbolAdded    = "added"
+	SymbolModified = "modified"
+	SymbolRemoved  = "removed"
+)
+
+// SymbolChange describes a function, method or type touched by a diff
+type SymbolChange struct {
+	File   string `json:"file"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"` // func, method, type
+	Change string `json:"change"`
+}
+
+// symbol is a named declaration with its normalized source
+type symbol struct {
+	name   string
+	kind   string
+	source string
+}
+
+// DiffSymbols compares the symbols declared in two versions of a file.
+// Either analysis may be nil when the file was added or deleted.
+func DiffSymbols(path string, oldAnalysis, newAnalysis *analysis.FileAnalysis, oldSource, newSource string) []SymbolChange {
+	oldSymbols := collectSymbols(oldAnalysis, oldSource)
+	newSymbols := collectSymbols(newAnalysis, newSource)
+
+	changes := make([]SymbolChange, 0)
+
+	for key, newSym := range newSymbols {
+		oldSym, existed := oldSymbols[key]
+		switch {
+		case !existed:
+			changes = append(changes, SymbolChange{File: path, Name: newSym.name, Kind: newSym.kind, Change: SymbolAdded})
+		case oldSym.source != newSym.source:
+			changes = append(changes, SymbolChange{File: path, Name: newSym.name, Kind: newSym.kind, Change: SymbolModified})
+		}
+	}
+
+	for key, oldSym := range oldSymbols {
+		if _, exists := newSymbols[key]; !exists {
+			changes = append(changes, SymbolChange{File: path, Name: oldSym.name, Kind: oldSym.kind, Change: SymbolRemoved})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Change != changes[j].Change {
+			return changeOrder(changes[i].Change) < changeOrder(changes[j].Change)
+		}
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+// collectSymbols indexes functions, methods and types by qualified name
+func collectSymbols(fileAnalysis *analysis.FileAnalysis, source string) map[string]symbol {
+	symbols := make(map[string]symbol)
+	if fileAnalysis == nil {
+		return symbols
+	}
+
+	lines := strings.Split(source, "\n")
+
+	for _, fn := range fileAnalysis.Functions {
+		name, kind := fn.Name, "func"
+		if fn.Receiver != "" {
+			name, kind = fn.Receiver+"."+fn.Name, "method"
+		}
+		symbols[kind+":"+name] = symbol{name: name, kind: kind, source: sliceSource(lines, fn.StartLine, fn.EndLine)}
+	}
+
+	for _, class := range fileAnalysis.Classes {
+		symbols["type:"+class.Name] = symbol{name: class.Name, kind: "type", source: sliceSource(lines, class.StartLine, class.EndLine)}
+	}
+
+	return symbols
+}
+
+// sliceSource returns the whitespace-normalized text of a line range
+func sliceSource(lines []string, start, end int) string {
+	if start < 1 || start > len(lines) {
+		return ""
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return strings.Join(strings.Fields(strings.Join(lines[start-1:end], "\n")), " ")
+}
+
+// changeOrder sorts added symbols first, then modified, then removed
+func changeOrder(change string) int {
+	switch change {
+	case SymbolAdded:
+		return 0
+	case SymbolModified:
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,39 @@
+package review
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Writer renders a review result in a specific output format
+type Writer func(w io.Writer, result *Result) error
+
+// writers maps output format names to their writers
+var writers = map[string]Writer{
+	"codeclimate": WriteCodeClimate,
+	"html":        WriteHTML,
+	"json":        WriteJSON,
+	"markdown":    WriteMarkdown,
+	"sarif":       WriteSARIF,
+}
+
+// GetWriter returns the writer for an output format
+func GetWriter(format string) (Writer, error) {
+	writer, ok := writers[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format: %s (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return writer, nil
+}
+
+// Formats returns the names of all registered output formats
+func Formats() []string {
+	formats := make([]string, 0, len(writers))
+	for name := range writers {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}